@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// collection storage-neutral store of the documents of an entity other than Book, i.e. patrons or loans.
+// Documents are the bson representation of the entity, queried with the same Filter and FindOptions as Books
+type collection interface {
+	// find decodes the documents matching the filter into results, a pointer to a slice of the entity
+	find(filter Filter, findOptions FindOptions, results interface{}) *BookAPIError
+	// count returns the number of documents matching the filter
+	count(filter Filter) (int64, *BookAPIError)
+	// insert saves the entity, generating an ID when it has none, and returns its ID
+	insert(entity interface{}) (string, *BookAPIError)
+	// update sets the fields of the document with the ID when it matches the condition and reports whether it did
+	update(id string, condition Filter, fields Fields) (bool, *BookAPIError)
+	// delete removes the document with the ID when it matches the condition and reports whether it did
+	delete(id string, condition Filter) (bool, *BookAPIError)
+}
+
+// newCollection opens the named collection in the storage engine selected by the storage_engine environment variable,
+// defaulting to MongoDB
+// It returns an API Error Response if failed
+func newCollection(name string) (collection, *BookAPIError) {
+	engine, _ := os.LookupEnv("storage_engine")
+	switch engine {
+	case "", "mongodb":
+		database, err := connectMongo()
+		if err != nil {
+			return nil, err
+		}
+
+		documents := database.Collection(name)
+		createUniqueIndexes(documents, uniqueKeys[name])
+		return &mongoCollection{collection: documents}, nil
+	case "memory":
+		return newMemoryCollection(nil, uniqueKeys[name]...), nil
+	case "file":
+		path, err := lookupEnv("storage_path")
+		if err != nil {
+			return nil, err
+		}
+
+		// the collection is stored next to the Books file
+		return newFileCollection(filepath.Join(filepath.Dir(path), name+".json"), uniqueKeys[name]...)
+	case "sql":
+		driver, err := lookupEnv("sql_driver")
+		if err != nil {
+			return nil, err
+		}
+
+		dsn, err := lookupEnv("sql_dsn")
+		if err != nil {
+			return nil, err
+		}
+
+		return newSQLCollection(driver, dsn, name)
+	default:
+		return nil, NewMissingEnvVariable(fmt.Sprintf("unsupported storage_engine %s", engine))
+	}
+}
+
+// uniqueKeys fields no two documents of a collection have the same values of, by collection name.
+// The SQL engine creates the same unique indexes with its migrations
+var uniqueKeys = map[string][][]string{
+	"patrons":      {{"email"}},
+	"ratings":      {{"book_id", "patron_id"}},
+	"reviews":      {{"book_id", "patron_id"}},
+	"review_votes": {{"review_id", "patron_id"}},
+}
+
+// createUniqueIndexes creates the unique indexes of the keys in the MongoDB collection.
+// Writes are not refused without them, so a failure is logged rather than stopping the API
+func createUniqueIndexes(collection *mongo.Collection, keys [][]string) {
+	for _, key := range keys {
+		fields := bson.D{}
+		for _, field := range key {
+			fields = append(fields, bson.E{Key: field, Value: 1})
+		}
+
+		index := mongo.IndexModel{Keys: fields, Options: options.Index().SetUnique(true)}
+		indexCtx, cancelIndex := context.WithTimeout(context.Background(), 5*time.Second)
+		if _, indexErr := collection.Indexes().CreateOne(indexCtx, index); indexErr != nil {
+			log.Println("Unable to create the unique index of", strings.Join(key, ", "), indexErr.Error())
+		}
+		cancelIndex()
+	}
+}
+
+// isDuplicateKey reports whether the storage engine refused the write for breaking a unique index:
+// MongoDB error E11000, a failed SQLite UNIQUE constraint or PostgreSQL unique_violation
+func isDuplicateKey(err error) bool {
+	text := err.Error()
+	return strings.Contains(text, "E11000") || strings.Contains(text, "UNIQUE constraint failed") ||
+		strings.Contains(text, "duplicate key value")
+}
+
+// lookupEnv returns the value of the environment variable
+// It returns a Missing Env Variable error if it is not set
+func lookupEnv(name string) (string, *BookAPIError) {
+	value, present := os.LookupEnv(name)
+	if !present {
+		return "", NewMissingEnvVariable(fmt.Sprintf("need to set %s environment variable", name))
+	}
+
+	return value, nil
+}
+
+// idFilter returns the Filter matching the document with the ID
+func idFilter(id string) Filter {
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	return NewQueryFilter("_id", Equals, objectID)
+}
+
+// newDocument returns the document of the entity with its ID, generated when it has none
+func newDocument(entity interface{}) (bson.M, string, error) {
+	doc, err := toBSONDocument(entity)
+	if err != nil {
+		return nil, "", err
+	}
+
+	id, ok := doc["_id"].(primitive.ObjectID)
+	if !ok || id.IsZero() {
+		id = primitive.NewObjectID()
+		doc["_id"] = id
+	}
+
+	return doc, id.Hex(), nil
+}
+
+// decodeDocuments decodes the documents into results, a pointer to a slice of the entity
+func decodeDocuments(docs []bson.M, results interface{}) error {
+	slice := reflect.ValueOf(results).Elem()
+	decoded := reflect.MakeSlice(slice.Type(), 0, len(docs))
+	for _, doc := range docs {
+		raw, err := bson.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		entity := reflect.New(slice.Type().Elem())
+		if err := bson.Unmarshal(raw, entity.Interface()); err != nil {
+			return err
+		}
+		decoded = reflect.Append(decoded, entity.Elem())
+	}
+
+	slice.Set(decoded)
+	return nil
+}
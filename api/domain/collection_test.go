@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+// testLoanCollection exercises the loans stored in the collection, every engine must pass it
+func testLoanCollection(t *testing.T, loans collection) {
+	repo := &loanRepo{collection: loans}
+	bookID, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	checkedOutAt := time.Date(2019, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	firstID, err := repo.Save(Loan{BookID: bookID, PatronID: patronID, CheckedOutAt: checkedOutAt, DueAt: checkedOutAt.Add(DefaultLoanPolicy.Period)})
+	assert.Nil(t, err)
+	secondID, err := repo.Save(Loan{BookID: bookID, PatronID: patronID, CheckedOutAt: checkedOutAt.Add(time.Hour), DueAt: checkedOutAt.Add(DefaultLoanPolicy.Period)})
+	assert.Nil(t, err)
+
+	open := AllOf(NewQueryFilter("book_id", Equals, bookID), NewQueryFilter("returned_at", Equals, nil))
+	found, err := repo.FindAll(open, newestLoansFirst)
+	assert.Nil(t, err)
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, secondID, found[0].ID.Hex())
+		assert.Equal(t, firstID, found[1].ID.Hex())
+		assert.True(t, checkedOutAt.Equal(found[1].CheckedOutAt))
+		assert.Nil(t, found[1].ReturnedAt)
+	}
+
+	returnedAt := checkedOutAt.Add(24 * time.Hour)
+	closed := NewQueryFilter("returned_at", Equals, nil)
+	assert.Nil(t, repo.Update(firstID, closed, Fields{"returned_at": returnedAt}))
+	assert.Equal(t, NotFoundError, repo.Update(firstID, closed, Fields{"returned_at": returnedAt}).errorType)
+
+	found, err = repo.FindAll(open, FindOptions{})
+	assert.Nil(t, err)
+	count, err := loans.count(open)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, secondID, found[0].ID.Hex())
+	}
+
+	found, err = repo.FindAll(NewQueryFilter("returned_at", DoesNotEqual, nil), FindOptions{})
+	assert.Nil(t, err)
+	if assert.Len(t, found, 1) && assert.NotNil(t, found[0].ReturnedAt) {
+		assert.True(t, returnedAt.Equal(*found[0].ReturnedAt))
+	}
+
+	found, err = repo.FindAll(NewQueryFilter("patron_id", Equals, patronID), FindOptions{Skip: 1, Limit: 5})
+	assert.Nil(t, err)
+	assert.Len(t, found, 1)
+}
+
+func TestMemoryCollection_Loans(t *testing.T) {
+	testLoanCollection(t, newMemoryCollection(nil))
+}
+
+func TestFileCollection_Loans(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	loans, err := newFileCollection(path)
+	assert.Nil(t, err)
+	testLoanCollection(t, loans)
+
+	reopened, err := newFileCollection(path)
+	assert.Nil(t, err)
+
+	found, findErr := (&loanRepo{collection: reopened}).FindAll(Filter{}, FindOptions{})
+	assert.Nil(t, findErr)
+	assert.Len(t, found, 2)
+}
+
+func TestSQLCollection_Loans(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testLoanCollection(t, newSQLiteCollection(t, path, "loans"))
+
+	_, err := newSQLCollection("sqlite3", path, "unknown")
+	assert.NotNil(t, err)
+}
+
+func TestPatronRepo_CRUD(t *testing.T) {
+	repo := &patronRepo{collection: newMemoryCollection(nil)}
+
+	id, err := repo.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, repo.Update(id, Fields{"name": "Augusta Ada King"}))
+	patron, err := repo.FindOne(id)
+	assert.Nil(t, err)
+	assert.Equal(t, "Augusta Ada King", patron.Name)
+	assert.Equal(t, "ada@example.com", patron.Email)
+
+	assert.Nil(t, repo.Delete(id))
+	_, err = repo.FindOne(id)
+	assert.Equal(t, NotFoundError, err.errorType)
+	assert.Equal(t, NotFoundError, repo.Update(id, Fields{"name": "Ada"}).errorType)
+	assert.Equal(t, NotFoundError, repo.Delete(id).errorType)
+}
+
+// testPatronEmails exercises the unique email of the patrons stored in the collection, every engine must pass it
+func testPatronEmails(t *testing.T, patrons collection) {
+	repo := &patronRepo{collection: patrons}
+	_, err := repo.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	assert.Nil(t, err)
+	id, err := repo.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+	assert.Nil(t, err)
+
+	_, err = repo.Save(Patron{Name: "Augusta Ada King", Email: "ada@example.com"})
+	assert.Equal(t, ExistingRecord, err.errorType)
+	assert.Equal(t, ExistingRecord, repo.Update(id, Fields{"email": "ada@example.com"}).errorType)
+
+	assert.Nil(t, repo.Update(id, Fields{"name": "Alan Mathison Turing", "email": "alan@example.com"}))
+	count, err := patrons.count(NewQueryFilter("email", Equals, "ada@example.com"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMemoryCollection_PatronEmails(t *testing.T) {
+	testPatronEmails(t, newMemoryCollection(nil, uniqueKeys["patrons"]...))
+}
+
+func TestFileCollection_PatronEmails(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	patrons, err := newFileCollection(path, uniqueKeys["patrons"]...)
+	assert.Nil(t, err)
+	testPatronEmails(t, patrons)
+}
+
+func TestSQLCollection_PatronEmails(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testPatronEmails(t, newSQLiteCollection(t, path, "patrons"))
+}
@@ -2,10 +2,13 @@ package domain
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/go-chi/chi"
 	"github.com/temesxgn/redeam/api/utils"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // A Controller - action handler for Book API
@@ -16,19 +19,88 @@ type Controller struct {
 // GetAll handles REST API Get '/' Endpoint
 func (c *Controller) GetAll(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
-	queryBuilder := utils.QueryBuilder{}
-	filters, queries := queryBuilder.GetQueryParams(r)
-	blogs, err := c.service.FindAll(filters, queries)
+	queryBuilder := QueryBuilder{}
+	request, queryErr := queryBuilder.GetQueryParams(r)
+	if queryErr != nil {
+		writeError(w, r, queryErr)
+		return
+	}
+
+	if len(request.Fuzzy) > 0 {
+		c.getSimilar(w, r, request)
+		return
+	}
+
+	blogs, err := c.service.FindAll(request.FindFilter(), request.FindOptions)
 	if err != nil {
-		responseBuilder.InternalServerError(w, err.Error())
+		writeError(w, r, err)
 		return
 	}
 
-	data, _ := json.Marshal(blogs)
+	total, countErr := c.service.Count(request.Filter)
+	if countErr != nil {
+		writeError(w, r, countErr)
+		return
+	}
+
+	page := request.Paginate(blogs)
+	if page.Next != nil {
+		w.Header().Set("X-Next-Cursor", page.Next.Encode())
+	}
+	if page.Prev != nil {
+		w.Header().Set("X-Prev-Cursor", page.Prev.Encode())
+	}
+
+	response := NewPageResponse(r, request, page, total)
+	w.Header().Set("Link", response.Links.Header())
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	data, _ := json.Marshal(response)
 	responseBuilder.OK(w, data)
 	return
 }
 
+// getSimilar responds to a GetAll request with fuzzy conditions
+func (c *Controller) getSimilar(w http.ResponseWriter, r *http.Request, request PageRequest) {
+	responseBuilder := utils.ResponseBuilder{}
+	result, err := c.service.FindSimilar(request.Filter, request.Fuzzy, request.FindOptions)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := NewFuzzyPageResponse(r, request, result)
+	w.Header().Set("Link", response.Links.Header())
+	w.Header().Set("X-Total-Count", strconv.FormatInt(result.Total, 10))
+
+	data, _ := json.Marshal(response)
+	responseBuilder.OK(w, data)
+}
+
+// Search handles REST API Get '/search' Endpoint
+func (c *Controller) Search(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	queryBuilder := QueryBuilder{}
+	request, queryErr := queryBuilder.GetSearchParams(r)
+	if queryErr != nil {
+		writeError(w, r, queryErr)
+		return
+	}
+
+	hits, total, err := c.service.Search(request.Text, request.FindOptions)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := NewSearchResponse(r, request, hits, total)
+	w.Header().Set("Link", response.Links.Header())
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	data, _ := json.Marshal(response)
+	responseBuilder.OK(w, data)
+}
+
 // GetByID handles REST API Get '/{id}' Endpoint
 func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
@@ -36,16 +108,11 @@ func (c *Controller) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	blog, err := c.service.FindOne(id)
 	if err != nil {
-		switch err.errorType {
-		case NotFoundError:
-			responseBuilder.NotFound(w)
-			return
-		default:
-			responseBuilder.InternalServerError(w, err.Error())
-			return
-		}
+		writeError(w, r, err)
+		return
 	}
 
+	w.Header().Set("ETag", entityTag(blog.Version))
 	data, _ := json.Marshal(blog)
 	responseBuilder.OK(w, data)
 }
@@ -55,22 +122,20 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
 
 	var book Book
-	_ = json.NewDecoder(r.Body).Decode(&book)
+	if err := decodeJSON(r, &book); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	if err := book.Validate(); err != nil {
-		responseBuilder.BadRequest(w, err.Error())
+		writeError(w, r, err)
 		return
 	}
 
 	id, createError := c.service.Create(book)
 	if createError != nil {
-		switch createError.errorType {
-		case ExistingRecord:
-			responseBuilder.BadRequest(w, createError.Error())
-			return
-		default:
-			responseBuilder.InternalServerError(w, createError.Error())
-			return
-		}
+		writeError(w, r, createError)
+		return
 	}
 
 	responseBuilder.OK(w, []byte(id))
@@ -80,77 +145,114 @@ func (c *Controller) Create(w http.ResponseWriter, r *http.Request) {
 func (c *Controller) Update(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
 	id := chi.URLParam(r, "id")
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
 
 	var book Book
-	_ = json.NewDecoder(r.Body).Decode(&book)
+	if err := decodeJSON(r, &book); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	if err := book.Validate(); err != nil {
-		responseBuilder.BadRequest(w, err.Error())
+		writeError(w, r, err)
 		return
 	}
 
-	if updateError := c.service.Update(id, book); updateError != nil {
-		switch updateError.errorType {
-		case NotFoundError:
-			responseBuilder.NotFound(w)
-			return
-		default:
-			responseBuilder.InternalServerError(w, updateError.Error())
-			return
-		}
+	if updateError := c.service.Update(id, versions, book); updateError != nil {
+		writeError(w, r, updateError)
+		return
 	}
 
 	responseBuilder.OK(w, []byte(""))
 }
 
+// Patch handles REST API PATCH '/{id}' Endpoint, the body being a JSON merge patch or a JSON patch
+func (c *Controller) Patch(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	w.Header().Set("Accept-Patch", mergePatchMediaType+", "+jsonPatchMediaType)
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	patch, err := readPatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	book, patchError := c.service.Patch(id, versions, patch)
+	if patchError != nil {
+		writeError(w, r, patchError)
+		return
+	}
+
+	w.Header().Set("ETag", entityTag(book.Version))
+	data, _ := json.Marshal(book)
+	responseBuilder.OK(w, data)
+}
+
 // Delete handles REST API DELETE '/{id}' Endpoint
 func (c *Controller) Delete(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
 	id := chi.URLParam(r, "id")
 
 	if len(id) == 0 {
-		responseBuilder.BadRequest(w, "Missing ID!")
+		writeError(w, r, NewInvalidFieldError("id", "required", "Missing ID!"))
 		return
 	}
 
-	if updateError := c.service.Delete(id); updateError != nil {
-		switch updateError.errorType {
-		case NotFoundError:
-			responseBuilder.NotFound(w)
-			return
-		default:
-			responseBuilder.InternalServerError(w, updateError.Error())
-			return
-		}
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	if updateError := c.service.Delete(id, versions); updateError != nil {
+		writeError(w, r, updateError)
+		return
 	}
 
 	responseBuilder.OK(w, []byte(""))
 }
 
-// CheckOut handles REST API PUT '/checkout/{id}' Endpoint
+// CheckOut handles REST API PUT '/checkout/{id}' Endpoint, responding with the Loan of the Book to the Patron
 func (c *Controller) CheckOut(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
 	id := chi.URLParam(r, "id")
 
 	if len(id) == 0 {
-		responseBuilder.BadRequest(w, "Missing ID!")
+		writeError(w, r, NewInvalidFieldError("id", "required", "Missing ID!"))
 		return
 	}
 
-	if err := c.service.CheckOut(id); err != nil {
-		switch err.errorType {
-		case NotFoundError:
-			responseBuilder.NotFound(w)
-			return
-		case AlreadyCheckedOut:
-			responseBuilder.BadRequest(w, err.Error())
-			return
-		default:
-			responseBuilder.InternalServerError(w, err.Error())
-			return
-		}
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
 	}
 
-	responseBuilder.OK(w, []byte(nil))
+	var request LoanRequest
+	if err := decodeJSON(r, &request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	loan, err := c.service.CheckOut(id, versions, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(loan)
+	responseBuilder.OK(w, data)
 }
 
 // CheckIn handles REST API PUT '/checkin/{id}' Endpoint
@@ -159,54 +261,273 @@ func (c *Controller) CheckIn(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	if len(id) == 0 {
-		responseBuilder.BadRequest(w, "Missing ID!")
+		writeError(w, r, NewInvalidFieldError("id", "required", "Missing ID!"))
 		return
 	}
 
-	if err := c.service.CheckIn(id); err != nil {
-		switch err.errorType {
-		case NotFoundError:
-			responseBuilder.NotFound(w)
-			return
-		case AlreadyCheckedIn:
-			responseBuilder.BadRequest(w, err.Error())
-			return
-		default:
-			responseBuilder.InternalServerError(w, err.Error())
-			return
-		}
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	if err := c.service.CheckIn(id, versions); err != nil {
+		writeError(w, r, err)
+		return
 	}
 
 	responseBuilder.OK(w, []byte(nil))
 }
 
-// Rate handles REST API PUT '/{id}/rate/{id}' Endpoint
+// CheckOutCopy handles REST API PUT '/{id}/copies/{copyID}/checkout' Endpoint, returning the loan of the Copy
+func (c *Controller) CheckOutCopy(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	copyID := chi.URLParam(r, "copyID")
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	var request LoanRequest
+	if err := decodeJSON(r, &request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	loan, err := c.service.CheckOutCopy(id, copyID, versions, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(loan)
+	responseBuilder.OK(w, data)
+}
+
+// CheckInCopy handles REST API PUT '/{id}/copies/{copyID}/checkin' Endpoint
+func (c *Controller) CheckInCopy(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	copyID := chi.URLParam(r, "copyID")
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	if err := c.service.CheckInCopy(id, copyID, versions); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(nil))
+}
+
+// Transition handles REST API PUT '/{id}/status/{status}' Endpoint, a branch moving the Book to the status
+func (c *Controller) Transition(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	status, statusErr := statusParam(r)
+	if statusErr != nil {
+		writeError(w, r, statusErr)
+		return
+	}
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	if err := c.service.Transition(id, versions, status); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(nil))
+}
+
+// TransitionCopy handles REST API PUT '/{id}/copies/{copyID}/status/{status}' Endpoint, a branch moving the Copy
+// to the status
+func (c *Controller) TransitionCopy(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	copyID := chi.URLParam(r, "copyID")
+
+	status, statusErr := statusParam(r)
+	if statusErr != nil {
+		writeError(w, r, statusErr)
+		return
+	}
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	if err := c.service.TransitionCopy(id, copyID, versions, status); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(nil))
+}
+
+// statusParam returns the Status named by the status URL parameter
+func statusParam(r *http.Request) (Status, *BookAPIError) {
+	name := chi.URLParam(r, "status")
+	status, ok := parseStatus(name)
+	if !ok {
+		return Unknown, NewInvalidFieldError("status", "in", fmt.Sprintf("%s is not a status", name))
+	}
+
+	return status, nil
+}
+
+// Loans handles REST API Get '/{id}/loans' Endpoint, the loan history of the Book, newest first
+func (c *Controller) Loans(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	loans, err := c.service.Loans(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(loans)
+	responseBuilder.OK(w, data)
+}
+
+// Rate handles REST API PUT '/{id}/rate/{rate}' Endpoint, the Patron rating the Book
 func (c *Controller) Rate(w http.ResponseWriter, r *http.Request) {
 	responseBuilder := utils.ResponseBuilder{}
 	id := chi.URLParam(r, "id")
 	rateParam := chi.URLParam(r, "rate")
 
-	rate, rateError := strconv.Atoi(rateParam)
+	rate, rateError := ratingScale.Parse("rate", rateParam)
 	if rateError != nil {
-		responseBuilder.BadRequest(w, "Rate must be a number!")
+		writeError(w, r, rateError)
+		return
+	}
+
+	var request RatingRequest
+	if err := decodeJSON(r, &request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	rating, err := c.service.Rate(id, rate, request)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if err := c.service.Rate(id, rate); err != nil {
-		switch err.errorType {
-		case ValidationError:
-			responseBuilder.BadRequest(w, err.Error())
-			return
-		case NotFoundError:
-			responseBuilder.NotFound(w)
-			return
-		default:
-			responseBuilder.InternalServerError(w, err.Error())
-			return
+	data, _ := json.Marshal(rating)
+	responseBuilder.OK(w, data)
+}
+
+// Ratings handles REST API GET '/{id}/ratings' Endpoint, the Ratings of the Book with their average, count and
+// distribution
+func (c *Controller) Ratings(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	summary, err := c.service.Ratings(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(summary)
+	responseBuilder.OK(w, data)
+}
+
+// NotFound handles requests to paths no endpoint handles
+func (c *Controller) NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, NewRouteNotFoundError(r.URL.Path))
+}
+
+// MethodNotAllowed handles requests with a method the endpoint does not handle
+func (c *Controller) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, NewMethodNotAllowedError(r.Method, r.URL.Path))
+}
+
+// entityTag returns the ETag header of the Book version
+func entityTag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch returns the versions of the Book or the Copy the If-Match header of the request lists, AnyVersion with *.
+// A weak entity tag never matches since writes need a strong comparison.
+// It returns a Precondition Required error without the header, a Validation Error if the header is not * or a list
+// of entity tags and a Precondition Failed error if they are all weak
+func ifMatch(r *http.Request) (Versions, *BookAPIError) {
+	kind, id := copyKind, chi.URLParam(r, "copyID")
+	if id == "" {
+		kind, id = bookKind, chi.URLParam(r, "id")
+	}
+
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch header {
+	case "":
+		return nil, NewPreconditionRequiredError(id)
+	case "*":
+		return AnyVersion, nil
+	}
+
+	versions := Versions{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			tag = strings.TrimPrefix(tag, "W/")
+			if _, err := parseEntityTag(tag); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		version, err := parseEntityTag(tag)
+		if err != nil {
+			return nil, err
 		}
+		versions = append(versions, version)
 	}
 
-	responseBuilder.OK(w, []byte(nil))
+	if len(versions) == 0 {
+		return nil, NewPreconditionFailedError(kind, id)
+	}
+
+	return versions, nil
+}
+
+// parseEntityTag returns the version of the strong entity tag
+// It returns a Validation Error if the tag is not the quoted version of a Book or a Copy
+func parseEntityTag(tag string) (int64, *BookAPIError) {
+	version, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(tag, `"`), `"`), 10, 64)
+	if err != nil || version < 0 || len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return 0, NewInvalidFieldError("If-Match", "etag", "If-Match must be * or a list of ETags")
+	}
+
+	return version, nil
+}
+
+// writeError responds with the RFC 7807 problem details of the error
+func writeError(w http.ResponseWriter, r *http.Request, err *BookAPIError) {
+	responseBuilder := utils.ResponseBuilder{}
+	problem := err.Problem(r)
+	if problem.Status >= http.StatusInternalServerError {
+		log.Println("Internal error:", err.Error())
+	}
+
+	data, _ := json.Marshal(problem)
+	responseBuilder.Problem(w, problem.Status, data)
 }
 
 // NewController Creates Controller instance
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,6 +15,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -30,6 +32,7 @@ func TestController_GetAll(t *testing.T) {
 
 	bookService := NewMockService(gomock.NewController(t))
 	bookService.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(books, nil)
+	bookService.EXPECT().Count(gomock.Any()).Return(int64(1), nil)
 	bookController := NewController(bookService)
 
 	wr := httptest.NewRecorder()
@@ -39,6 +42,49 @@ func TestController_GetAll(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, wr.Code, `Invalid response... Expected 200 but got %d`, wr.Code)
 	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte("thg090020")))
+	assert.Equal(t, "1", wr.Header().Get("X-Total-Count"))
+}
+
+func TestController_GetAll_WithEnvelope(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(Books{{Author: "Robert Martin"}, {Author: "Paulo Coelho"}, {Author: "Robert Martin"}}, nil)
+	bookService.EXPECT().Count(NewQueryFilter("rating", GreaterThanOrEqual, float64(1))).Return(int64(7), nil)
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books?rating>=1&size=2&page=2")
+	r := &http.Request{URL: testURL}
+	bookController.GetAll(wr, r)
+
+	var response PageResponse
+	assert.Nil(t, json.Unmarshal(wr.Body.Bytes(), &response))
+	assert.Len(t, response.Data, 2)
+	assert.Equal(t, int64(2), response.Page)
+	assert.Equal(t, int64(2), response.Size)
+	assert.Equal(t, int64(7), response.Total)
+	assert.Equal(t, Links{
+		Self:  "/books?rating>=1&size=2&page=2",
+		Next:  "/books?rating>=1&size=2&page=3",
+		Prev:  "/books?rating>=1&size=2&page=1",
+		First: "/books?rating>=1&size=2&page=1",
+		Last:  "/books?rating>=1&size=2&page=4",
+	}, response.Links)
+	assert.Equal(t, "7", wr.Header().Get("X-Total-Count"))
+	assert.Contains(t, wr.Header().Get("Link"), `</books?rating>=1&size=2&page=3>; rel="next"`)
+}
+
+func TestController_GetAll_WithCountError(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(Books{}, nil)
+	bookService.EXPECT().Count(gomock.Any()).Return(int64(0), NewDatabaseOperationError("count failed"))
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books")
+	r := &http.Request{URL: testURL}
+	bookController.GetAll(wr, r)
+
+	assert.Equal(t, http.StatusInternalServerError, wr.Code, `Invalid response... Expected 500 but got %d`, wr.Code)
 }
 
 func TestController_GetAll_WithError(t *testing.T) {
@@ -55,6 +101,75 @@ func TestController_GetAll_WithError(t *testing.T) {
 	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte("error")))
 }
 
+func TestController_GetAll_WithInvalidQuery(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books?rating>=abc")
+	r := &http.Request{URL: testURL}
+	bookController.GetAll(wr, r)
+
+	assert.Equal(t, http.StatusBadRequest, wr.Code, `Invalid response... Expected 400 but got %d`, wr.Code)
+}
+
+func TestController_GetAll_WithInvalidFilter(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse(`http://localhost:8080/books?filter=rating%3Dge%3D2%3B`)
+	r := &http.Request{URL: testURL}
+	bookController.GetAll(wr, r)
+
+	assert.Equal(t, http.StatusBadRequest, wr.Code, `Invalid response... Expected 400 but got %d`, wr.Code)
+	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte("position 13")))
+}
+
+func TestController_GetAll_WithUnknownSortField(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books?sort=-name")
+	r := &http.Request{URL: testURL}
+	bookController.GetAll(wr, r)
+
+	assert.Equal(t, http.StatusBadRequest, wr.Code, `Invalid response... Expected 400 but got %d`, wr.Code)
+}
+
+func TestController_Search(t *testing.T) {
+	hits := SearchHits{{Book: Book{Title: "Clean Code"}, Score: 1.5}}
+
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Search("clean", FindOptions{Limit: 10}).Return(hits, int64(11), nil)
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books/search?q=clean")
+	r := &http.Request{URL: testURL}
+	bookController.Search(wr, r)
+
+	assert.Equal(t, http.StatusOK, wr.Code, `Invalid response... Expected 200 but got %d`, wr.Code)
+	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte(`"title":"Clean Code"`)))
+	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte(`"score":1.5`)))
+	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte(`"next":"/books/search?q=clean\u0026size=10\u0026page=2"`)))
+	assert.Equal(t, "11", wr.Header().Get("X-Total-Count"))
+}
+
+func TestController_Search_WithoutText(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Search("", gomock.Any()).Return(nil, int64(0), NewValidationError("search text must contain at least one word"))
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books/search")
+	r := &http.Request{URL: testURL}
+	bookController.Search(wr, r)
+
+	assert.Equal(t, http.StatusBadRequest, wr.Code, `Invalid response... Expected 400 but got %d`, wr.Code)
+}
+
 func TestController_GetByID(t *testing.T) {
 	testBook := Book{
 		ID:     primitive.NewObjectID(),
@@ -79,6 +194,28 @@ func TestController_GetByID(t *testing.T) {
 	assert.True(t, bytes.Contains(body, []byte("thg090020")))
 }
 
+func TestController_GetByID_WithETag(t *testing.T) {
+	testBook := Book{
+		ID:      primitive.NewObjectID(),
+		Author:  "thg090020",
+		Version: 3,
+	}
+
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Get("/books/{id}", bookController.GetByID)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()))
+	defer closeBody(res.Body)
+
+	assert.Equal(t, `"3"`, res.Header.Get("ETag"))
+}
+
 func TestController_GetByIDWithNotFound(t *testing.T) {
 	bookService := NewMockService(gomock.NewController(t))
 	bookService.EXPECT().FindOne(gomock.Any()).Return(Book{}, NewNotFoundError("Not found"))
@@ -95,6 +232,73 @@ func TestController_GetByIDWithNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, res.StatusCode, `Invalid response... Expected 404 but got %d`, res.StatusCode)
 }
 
+func TestController_GetByIDWithNotFound_AsProblemDetails(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().FindOne("1234").Return(Book{}, NewNotFoundError("1234"))
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Get("/books/{id}", bookController.GetByID)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/books/%s", server.URL, "1234"))
+	defer closeBody(res.Body)
+
+	var problem Problem
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Nil(t, json.Unmarshal(body, &problem))
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, NotFoundError, problem.Code)
+	assert.Equal(t, "Book 1234 does not exist", problem.Detail)
+	assert.Equal(t, "/books/1234", problem.Instance)
+	assert.NotEmpty(t, problem.RequestID)
+}
+
+func TestController_NotFoundAndMethodNotAllowed(t *testing.T) {
+	bookController := NewController(NewMockService(gomock.NewController(t)))
+	router := chi.NewRouter()
+	router.NotFound(bookController.NotFound)
+	router.MethodNotAllowed(bookController.MethodNotAllowed)
+	router.Get("/books", bookController.GetAll)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/unknown", server.URL))
+	defer closeBody(res.Body)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+
+	res, _ = http.Post(fmt.Sprintf("%s/books", server.URL), ContentType, nil)
+	defer closeBody(res.Body)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	assert.True(t, bytes.Contains(body, []byte(`"code":"MethodNotAllowed"`)))
+}
+
+func TestController_Rate_WithInvalidRateNumber_AsFieldError(t *testing.T) {
+	bookController := NewController(NewMockService(gomock.NewController(t)))
+	router := chi.NewRouter()
+	router.Post("/books/{id}/rate/{rate}", bookController.Rate)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Post(fmt.Sprintf("%s/books/%s/rate/%s", server.URL, primitive.NewObjectID().Hex(), "high"), ContentType, nil)
+	defer closeBody(res.Body)
+
+	var problem Problem
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Nil(t, json.Unmarshal(body, &problem))
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, ValidationError, problem.Code)
+	assert.Equal(t, []FieldError{{Field: "rate", Rule: "in", Message: "rate must be a whole rating from 0 to 3"}}, problem.Errors)
+}
+
 func TestController_GetByIDWithInternalError(t *testing.T) {
 	testBook := Book{
 		ID:     primitive.NewObjectID(),
@@ -164,7 +368,33 @@ func TestController_Create_WithMalformedBody(t *testing.T) {
 	res, _ := http.Post(fmt.Sprintf("%s/books", server.URL), ContentType, requestReader)
 	defer closeBody(res.Body)
 
+	var problem Problem
+	body, _ := ioutil.ReadAll(res.Body)
+	_ = json.Unmarshal(body, &problem)
+
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
+	assert.Equal(t, MalformedBody, problem.Code)
+	assert.Equal(t, []FieldError{{Field: "Name", Rule: "unknown", Message: "Name is not a Book field"}}, problem.Errors)
+}
+
+func TestController_Create_WithInvalidFields(t *testing.T) {
+	bookController := NewController(NewMockService(gomock.NewController(t)))
+	router := chi.NewRouter()
+	router.Post("/books", bookController.Create)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Post(fmt.Sprintf("%s/books", server.URL), ContentType, bytes.NewReader([]byte(`{"title": "Clean Code"}`)))
+	defer closeBody(res.Body)
+
+	var problem Problem
+	body, _ := ioutil.ReadAll(res.Body)
+	_ = json.Unmarshal(body, &problem)
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
+	assert.Equal(t, ValidationError, problem.Code)
+	assert.Contains(t, problem.Errors, FieldError{Field: "author", Rule: "required", Message: "cannot be blank"})
 }
 
 func TestController_Create_WithExistingRecord(t *testing.T) {
@@ -242,7 +472,7 @@ func TestController_Update(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Update(testBook.ID.Hex(), testBook).Return(nil)
+	bookService.EXPECT().Update(testBook.ID.Hex(), AnyVersion, testBook).Return(nil)
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.Update)
@@ -250,12 +480,85 @@ func TestController_Update(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusOK, res.StatusCode, `Invalid response... Expected 200 but got %d`, res.StatusCode)
 }
 
+func TestController_Patch(t *testing.T) {
+	testBook := newPatchBook()
+	patch := `{"publisher": "Pearson"}`
+
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Patch(testBook.ID.Hex(), AnyVersion, MergePatch(patch)).Return(testBook, nil)
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Patch("/books/{id}", bookController.Patch)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), bytes.NewReader([]byte(patch)))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", "*")
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var book Book
+	json.NewDecoder(res.Body).Decode(&book)
+	assert.Equal(t, http.StatusOK, res.StatusCode, `Invalid response... Expected 200 but got %d`, res.StatusCode)
+	assert.Equal(t, testBook, book)
+}
+
+func TestController_Patch_WithUnsupportedMediaType(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Patch("/books/{id}", bookController.Patch)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/books/%s", server.URL, "5ca7c76f9287bd3832d96f15"), bytes.NewReader([]byte(`{"rating": 3}`)))
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("If-Match", "*")
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode, `Invalid response... Expected 415 but got %d`, res.StatusCode)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", res.Header.Get("Accept-Patch"))
+}
+
+func TestController_Patch_WithFailedTest(t *testing.T) {
+	testBook := newPatchBook()
+
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Patch(testBook.ID.Hex(), AnyVersion, gomock.Any()).DoAndReturn(func(id string, versions Versions, patch BookPatch) (Book, *BookAPIError) {
+		assert.IsType(t, JSONPatch{}, patch)
+		return patch.Apply(testBook)
+	})
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Patch("/books/{id}", bookController.Patch)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	body := `[{"op": "test", "path": "/rating", "value": 5}]`
+	req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	req.Header.Set("If-Match", "*")
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var problem Problem
+	json.NewDecoder(res.Body).Decode(&problem)
+	assert.Equal(t, http.StatusConflict, res.StatusCode, `Invalid response... Expected 409 but got %d`, res.StatusCode)
+	assert.Equal(t, PatchConflict, problem.Code)
+	assert.Equal(t, "Operation 0: test failed, /rating is 2", problem.Detail)
+}
+
 func TestController_Update_WithMalformedBody(t *testing.T) {
 	testBook := struct {
 		Name string
@@ -272,7 +575,7 @@ func TestController_Update_WithMalformedBody(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
@@ -293,7 +596,7 @@ func TestController_Update_WithNotFound(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(NewNotFoundError("not found"))
+	bookService.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(NewNotFoundError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.Update)
@@ -301,7 +604,7 @@ func TestController_Update_WithNotFound(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusNotFound, res.StatusCode, `Invalid response... Expected 404 but got %d`, res.StatusCode)
@@ -322,7 +625,7 @@ func TestController_Update_WithInternalError(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Update(gomock.Any(), gomock.Any()).Return(NewDatabaseOperationError("internal error"))
+	bookService.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(NewDatabaseOperationError("internal error"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.Update)
@@ -330,7 +633,7 @@ func TestController_Update_WithInternalError(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusInternalServerError, res.StatusCode, `Invalid response... Expected 500 but got %d`, res.StatusCode)
@@ -351,7 +654,7 @@ func TestController_Delete(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Delete(testBook.ID.Hex()).Return(nil)
+	bookService.EXPECT().Delete(testBook.ID.Hex(), AnyVersion).Return(nil)
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.Delete)
@@ -359,7 +662,7 @@ func TestController_Delete(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusOK, res.StatusCode, `Invalid response... Expected 200 but got %d`, res.StatusCode)
@@ -380,7 +683,7 @@ func TestController_Delete_WithNotFound(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Delete(gomock.Any()).Return(NewNotFoundError("not found"))
+	bookService.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(NewNotFoundError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.Delete)
@@ -388,7 +691,7 @@ func TestController_Delete_WithNotFound(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusNotFound, res.StatusCode, `Invalid response... Expected 404 but got %d`, res.StatusCode)
@@ -409,7 +712,7 @@ func TestController_Delete_WithInternalError(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Delete(gomock.Any()).Return(NewDatabaseOperationError("internal error"))
+	bookService.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(NewDatabaseOperationError("internal error"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.Delete)
@@ -417,28 +720,18 @@ func TestController_Delete_WithInternalError(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusInternalServerError, res.StatusCode, `Invalid response... Expected 500 but got %d`, res.StatusCode)
 }
 
 func TestController_CheckOut(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      2,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
-	}
-
-	requestBytes, _ := json.Marshal(testBook)
-	requestReader := bytes.NewReader(requestBytes)
+	bookID, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	loan := Loan{ID: primitive.NewObjectID(), BookID: bookID, PatronID: patronID}
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckOut(testBook.ID.Hex()).Return(nil)
+	bookService.EXPECT().CheckOut(bookID, AnyVersion, LoanRequest{PatronID: patronID}).Return(loan, nil)
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckOut)
@@ -446,28 +739,107 @@ func TestController_CheckOut(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, bookID), strings.NewReader(`{"patron_id": "`+patronID+`"}`)))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusOK, res.StatusCode, `Invalid response... Expected 200 but got %d`, res.StatusCode)
+
+	var body Loan
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	assert.Equal(t, loan.ID, body.ID)
+	assert.Equal(t, patronID, body.PatronID)
 }
 
-func TestController_CheckOut_WithNotFound(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      2,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
+func TestController_CheckOut_WithMalformedBody(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Post("/books/{id}", bookController.CheckOut)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, primitive.NewObjectID().Hex()), strings.NewReader(`{"author": "thg090020"}`)))
+	defer closeBody(res.Body)
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
+}
+
+func TestController_CheckOut_WithIfMatch(t *testing.T) {
+	id, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	cases := []struct {
+		ifMatch string
+		status  int
+	}{
+		{`"3"`, http.StatusOK},
+		{`*`, http.StatusOK},
+		{``, http.StatusPreconditionRequired},
+		{`W/"3"`, http.StatusPreconditionFailed},
+		{`W/"3", W/"4"`, http.StatusPreconditionFailed},
+		{`"3", W/"4", "5"`, http.StatusOK},
+		{`"3", *`, http.StatusBadRequest},
+		{`3`, http.StatusBadRequest},
 	}
 
-	requestBytes, _ := json.Marshal(testBook)
-	requestReader := bytes.NewReader(requestBytes)
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().CheckOut(id, Versions{3}, LoanRequest{PatronID: patronID}).Return(Loan{}, nil)
+	bookService.EXPECT().CheckOut(id, Versions{3, 5}, LoanRequest{PatronID: patronID}).Return(Loan{}, nil)
+	bookService.EXPECT().CheckOut(id, AnyVersion, LoanRequest{PatronID: patronID}).Return(Loan{}, nil)
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Put("/books/checkout/{id}", bookController.CheckOut)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
 
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/books/checkout/%s", server.URL, id), strings.NewReader(`{"patron_id": "`+patronID+`"}`))
+		req.Header.Set("Content-Type", ContentType)
+		req.Header.Set("If-Match", c.ifMatch)
+		res, _ := http.DefaultClient.Do(req)
+		closeBody(res.Body)
+
+		assert.Equal(t, c.status, res.StatusCode, c.ifMatch)
+	}
+}
+
+func TestController_Writes_WithoutIfMatch(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	bookController := NewController(NewMockService(gomock.NewController(t)))
+	router := chi.NewRouter()
+	router.Put("/books/{id}", bookController.Update)
+	router.Patch("/books/{id}", bookController.Patch)
+	router.Delete("/books/{id}", bookController.Delete)
+	router.Put("/books/checkout/{id}", bookController.CheckOut)
+	router.Put("/books/checkin/{id}", bookController.CheckIn)
+	router.Put("/books/{id}/status/{status}", bookController.Transition)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, write := range []struct{ method, path string }{
+		{http.MethodPut, "/books/" + id},
+		{http.MethodPatch, "/books/" + id},
+		{http.MethodDelete, "/books/" + id},
+		{http.MethodPut, "/books/checkout/" + id},
+		{http.MethodPut, "/books/checkin/" + id},
+		{http.MethodPut, "/books/" + id + "/status/Lost"},
+	} {
+		req, _ := http.NewRequest(write.method, server.URL+write.path, strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", ContentType)
+		res, _ := http.DefaultClient.Do(req)
+
+		var problem Problem
+		_ = json.NewDecoder(res.Body).Decode(&problem)
+		closeBody(res.Body)
+		assert.Equal(t, http.StatusPreconditionRequired, res.StatusCode, write.method+" "+write.path)
+		assert.Equal(t, PreconditionRequired, problem.Code, write.method+" "+write.path)
+	}
+}
+
+func TestController_CheckOut_WithNotFound(t *testing.T) {
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckOut(gomock.Any()).Return(NewNotFoundError("not found"))
+	bookService.EXPECT().CheckOut(gomock.Any(), gomock.Any(), gomock.Any()).Return(Loan{}, NewNotFoundError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckOut)
@@ -475,28 +847,15 @@ func TestController_CheckOut_WithNotFound(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), strings.NewReader(`{"patron_id": "1"}`)))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusNotFound, res.StatusCode, `Invalid response... Expected 404 but got %d`, res.StatusCode)
 }
 
 func TestController_CheckOut_WithAlreadyCheckedOut(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      2,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
-	}
-
-	requestBytes, _ := json.Marshal(testBook)
-	requestReader := bytes.NewReader(requestBytes)
-
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckOut(gomock.Any()).Return(NewAlreadyCheckedOutError("internal error"))
+	bookService.EXPECT().CheckOut(gomock.Any(), gomock.Any(), gomock.Any()).Return(Loan{}, NewAlreadyCheckedOutError("internal error"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckOut)
@@ -504,28 +863,15 @@ func TestController_CheckOut_WithAlreadyCheckedOut(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), strings.NewReader(`{"patron_id": "1"}`)))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
 }
 
 func TestController_CheckOut_WithInternalError(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      2,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
-	}
-
-	requestBytes, _ := json.Marshal(testBook)
-	requestReader := bytes.NewReader(requestBytes)
-
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckOut(gomock.Any()).Return(NewDatabaseOperationError("internal error"))
+	bookService.EXPECT().CheckOut(gomock.Any(), gomock.Any(), gomock.Any()).Return(Loan{}, NewDatabaseOperationError("internal error"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckOut)
@@ -533,12 +879,39 @@ func TestController_CheckOut_WithInternalError(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), strings.NewReader(`{"patron_id": "1"}`)))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusInternalServerError, res.StatusCode, `Invalid response... Expected 500 but got %d`, res.StatusCode)
 }
 
+func TestController_Loans(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	loans := Loans{{ID: primitive.NewObjectID(), BookID: id, PatronID: primitive.NewObjectID().Hex()}}
+
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Loans(id).Return(loans, nil)
+	bookService.EXPECT().Loans(gomock.Not(id)).Return(nil, NewNotFoundError("not found"))
+	bookController := NewController(bookService)
+	router := chi.NewRouter()
+	router.Get("/books/{id}/loans", bookController.Loans)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/books/%s/loans", server.URL, id))
+	defer closeBody(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var body Loans
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	assert.Equal(t, loans, body)
+
+	missing, _ := http.Get(fmt.Sprintf("%s/books/%s/loans", server.URL, primitive.NewObjectID().Hex()))
+	defer closeBody(missing.Body)
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+}
+
 func TestController_CheckIn(t *testing.T) {
 	testBook := Book{
 		ID:          primitive.NewObjectID(),
@@ -554,7 +927,7 @@ func TestController_CheckIn(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckIn(testBook.ID.Hex()).Return(nil)
+	bookService.EXPECT().CheckIn(testBook.ID.Hex(), AnyVersion).Return(nil)
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckIn)
@@ -562,7 +935,7 @@ func TestController_CheckIn(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, testBook.ID.Hex()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusOK, res.StatusCode, `Invalid response... Expected 200 but got %d`, res.StatusCode)
@@ -583,7 +956,7 @@ func TestController_CheckIn_WithNotFound(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckIn(gomock.Any()).Return(NewNotFoundError("not found"))
+	bookService.EXPECT().CheckIn(gomock.Any(), gomock.Any()).Return(NewNotFoundError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckIn)
@@ -591,7 +964,7 @@ func TestController_CheckIn_WithNotFound(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusNotFound, res.StatusCode, `Invalid response... Expected 404 but got %d`, res.StatusCode)
@@ -612,7 +985,7 @@ func TestController_CheckIn_WithAlreadyCheckedOut(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckIn(gomock.Any()).Return(NewAlreadyCheckedInError("internal error"))
+	bookService.EXPECT().CheckIn(gomock.Any(), gomock.Any()).Return(NewAlreadyCheckedInError("internal error"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckIn)
@@ -620,7 +993,7 @@ func TestController_CheckIn_WithAlreadyCheckedOut(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
@@ -641,7 +1014,7 @@ func TestController_CheckIn_WithInternalError(t *testing.T) {
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().CheckIn(gomock.Any()).Return(NewDatabaseOperationError("internal error"))
+	bookService.EXPECT().CheckIn(gomock.Any(), gomock.Any()).Return(NewDatabaseOperationError("internal error"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}", bookController.CheckIn)
@@ -649,12 +1022,20 @@ func TestController_CheckIn_WithInternalError(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	res, _ := http.Post(fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), ContentType, requestReader)
+	res, _ := http.DefaultClient.Do(newWriteRequest(http.MethodPost, fmt.Sprintf("%s/books/%s", server.URL, gomock.Any()), requestReader))
 	defer closeBody(res.Body)
 
 	assert.Equal(t, http.StatusInternalServerError, res.StatusCode, `Invalid response... Expected 500 but got %d`, res.StatusCode)
 }
 
+// newWriteRequest returns a request writing the body with If-Match: *, the write of a client that did not read the Book
+func newWriteRequest(method string, url string, body io.Reader) *http.Request {
+	req, _ := http.NewRequest(method, url, body)
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("If-Match", "*")
+	return req
+}
+
 func closeBody(body io.ReadCloser) {
 	err := body.Close()
 	if err != nil {
@@ -673,11 +1054,11 @@ func TestController_Rate(t *testing.T) {
 		PublishDate: "2019",
 	}
 
-	requestBytes, _ := json.Marshal(testBook)
+	requestBytes, _ := json.Marshal(RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"})
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any()).Return(nil)
+	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any(), RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"}).Return(Rating{}, nil)
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}/rate/{rate}", bookController.Rate)
@@ -702,11 +1083,11 @@ func TestController_Rate_WithNotFound(t *testing.T) {
 		PublishDate: "2019",
 	}
 
-	requestBytes, _ := json.Marshal(testBook)
+	requestBytes, _ := json.Marshal(RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"})
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any()).Return(NewNotFoundError("not found"))
+	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any(), RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"}).Return(Rating{}, NewNotFoundError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}/rate/{rate}", bookController.Rate)
@@ -731,11 +1112,11 @@ func TestController_Rate_WithInternalError(t *testing.T) {
 		PublishDate: "2019",
 	}
 
-	requestBytes, _ := json.Marshal(testBook)
+	requestBytes, _ := json.Marshal(RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"})
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any()).Return(NewDatabaseOperationError("not found"))
+	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any(), RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"}).Return(Rating{}, NewDatabaseOperationError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}/rate/{rate}", bookController.Rate)
@@ -760,11 +1141,11 @@ func TestController_Rate_WithInvalidRate(t *testing.T) {
 		PublishDate: "2019",
 	}
 
-	requestBytes, _ := json.Marshal(testBook)
+	requestBytes, _ := json.Marshal(RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"})
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
-	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any()).Return(NewValidationError("not found"))
+	bookService.EXPECT().Rate(testBook.ID.Hex(), gomock.Any(), RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"}).Return(Rating{}, NewValidationError("not found"))
 	bookController := NewController(bookService)
 	router := chi.NewRouter()
 	router.Post("/books/{id}/rate/{rate}", bookController.Rate)
@@ -789,7 +1170,7 @@ func TestController_Rate_WithInvalidRateNumber(t *testing.T) {
 		PublishDate: "2019",
 	}
 
-	requestBytes, _ := json.Marshal(testBook)
+	requestBytes, _ := json.Marshal(RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"})
 	requestReader := bytes.NewReader(requestBytes)
 
 	bookService := NewMockService(gomock.NewController(t))
@@ -805,3 +1186,30 @@ func TestController_Rate_WithInvalidRateNumber(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, res.StatusCode, `Invalid response... Expected 400 but got %d`, res.StatusCode)
 }
+
+func TestController_Transition(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Transition(id, Versions{3}, InRepair).Return(NewIllegalTransitionError(bookKind, id, Lost, InRepair))
+
+	router := chi.NewRouter()
+	router.Put("/books/{id}/status/{status}", NewController(bookService).Transition)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/books/%s/status/InRepair", server.URL, id), nil)
+	req.Header.Set("If-Match", `"3"`)
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var problem Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+	assert.Equal(t, IllegalTransition, problem.Code)
+	assert.Equal(t, fmt.Sprintf("Book %s cannot go from Lost to InRepair", id), problem.Detail)
+
+	req, _ = http.NewRequest(http.MethodPut, fmt.Sprintf("%s/books/%s/status/Stolen", server.URL, id), nil)
+	unknown, _ := http.DefaultClient.Do(req)
+	defer closeBody(unknown.Body)
+	assert.Equal(t, http.StatusBadRequest, unknown.StatusCode)
+}
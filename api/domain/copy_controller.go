@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/temesxgn/redeam/api/utils"
+	"net/http"
+)
+
+// A CopyController - action handler for the Copy API of a Book
+type CopyController struct {
+	service CopyService
+}
+
+// GetAll handles REST API Get '/{id}/copies' Endpoint, the copies of the Book by barcode
+func (c *CopyController) GetAll(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	copies, err := c.service.FindAll(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(copies)
+	responseBuilder.OK(w, data)
+}
+
+// GetByID handles REST API Get '/{id}/copies/{copyID}' Endpoint
+func (c *CopyController) GetByID(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	copyID := chi.URLParam(r, "copyID")
+
+	bookCopy, err := c.service.FindOne(id, copyID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", entityTag(bookCopy.Version))
+	data, _ := json.Marshal(bookCopy)
+	responseBuilder.OK(w, data)
+}
+
+// Create handles REST API POST '/{id}/copies' Endpoint
+func (c *CopyController) Create(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	var bookCopy Copy
+	if err := decodeJSON(r, &bookCopy); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	copyID, createError := c.service.Create(id, bookCopy)
+	if createError != nil {
+		writeError(w, r, createError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(copyID))
+}
+
+// Update handles REST API PUT '/{id}/copies/{copyID}' Endpoint, its status only changes by checking it out and in
+func (c *CopyController) Update(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	copyID := chi.URLParam(r, "copyID")
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	var bookCopy Copy
+	if err := decodeJSON(r, &bookCopy); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if updateError := c.service.Update(id, copyID, versions, bookCopy); updateError != nil {
+		writeError(w, r, updateError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(""))
+}
+
+// Delete handles REST API DELETE '/{id}/copies/{copyID}' Endpoint
+func (c *CopyController) Delete(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	copyID := chi.URLParam(r, "copyID")
+
+	versions, versionErr := ifMatch(r)
+	if versionErr != nil {
+		writeError(w, r, versionErr)
+		return
+	}
+
+	if deleteError := c.service.Delete(id, copyID, versions); deleteError != nil {
+		writeError(w, r, deleteError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(""))
+}
+
+// Availability handles REST API Get '/{id}/availability' Endpoint, the copies of the Book counted by status
+func (c *CopyController) Availability(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	availability, err := c.service.Availability(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(availability)
+	responseBuilder.OK(w, data)
+}
+
+// NewCopyController Creates CopyController instance
+func NewCopyController(service CopyService) *CopyController {
+	return &CopyController{service: service}
+}
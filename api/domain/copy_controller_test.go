@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCopyController_CreateAndAvailability(t *testing.T) {
+	id, copyID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	copyService := NewMockCopyService(gomock.NewController(t))
+	copyService.EXPECT().Create(id, Copy{Barcode: "B-0001", Branch: "Central", Condition: "new"}).Return(copyID, nil)
+	copyService.EXPECT().Availability(id).Return(Availability{Copies: 3, Available: 1, CheckedOut: 2}, nil)
+
+	copyController := NewCopyController(copyService)
+	router := chi.NewRouter()
+	router.Post("/books/{id}/copies", copyController.Create)
+	router.Get("/books/{id}/availability", copyController.Availability)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Post(fmt.Sprintf("%s/books/%s/copies", server.URL, id), ContentType,
+		strings.NewReader(`{"barcode": "B-0001", "branch": "Central", "condition": "new"}`))
+	defer closeBody(res.Body)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, copyID, string(body))
+
+	available, _ := http.Get(fmt.Sprintf("%s/books/%s/availability", server.URL, id))
+	defer closeBody(available.Body)
+	var availability map[string]int
+	_ = json.NewDecoder(available.Body).Decode(&availability)
+	assert.Equal(t, map[string]int{"copies": 3, "available": 1, "checked_out": 2, "on_hold_shelf": 0, "unavailable": 0, "holds": 0}, availability)
+}
+
+func TestController_CheckOutCopy(t *testing.T) {
+	id, copyID, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().CheckOutCopy(id, copyID, Versions{2}, LoanRequest{PatronID: patronID}).Return(Loan{BookID: id, CopyID: copyID, PatronID: patronID}, nil)
+
+	router := chi.NewRouter()
+	router.Put("/books/{id}/copies/{copyID}/checkout", NewController(bookService).CheckOutCopy)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/books/%s/copies/%s/checkout", server.URL, id, copyID),
+		strings.NewReader(`{"patron_id": "`+patronID+`"}`))
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("If-Match", `"2"`)
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var loan Loan
+	_ = json.NewDecoder(res.Body).Decode(&loan)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, copyID, loan.CopyID)
+}
+
+func TestCopyController_Versions(t *testing.T) {
+	id, copyID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	copyService := NewMockCopyService(gomock.NewController(t))
+	copyService.EXPECT().FindOne(id, copyID).Return(Copy{BookID: id, Barcode: "B-0001", Version: 3}, nil)
+	copyService.EXPECT().Delete(id, copyID, Versions{2, 3}).Return(nil)
+
+	copyController := NewCopyController(copyService)
+	router := chi.NewRouter()
+	router.Get("/books/{id}/copies/{copyID}", copyController.GetByID)
+	router.Put("/books/{id}/copies/{copyID}", copyController.Update)
+	router.Delete("/books/{id}/copies/{copyID}", copyController.Delete)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := fmt.Sprintf("%s/books/%s/copies/%s", server.URL, id, copyID)
+	res, _ := http.Get(url)
+	closeBody(res.Body)
+	assert.Equal(t, `"3"`, res.Header.Get("ETag"))
+
+	req, _ := http.NewRequest(http.MethodPut, url, strings.NewReader(`{"barcode": "B-0002", "branch": "East"}`))
+	req.Header.Set("Content-Type", ContentType)
+	res, _ = http.DefaultClient.Do(req)
+	closeBody(res.Body)
+	assert.Equal(t, http.StatusPreconditionRequired, res.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodDelete, url, nil)
+	req.Header.Set("If-Match", `"2", "3"`)
+	res, _ = http.DefaultClient.Do(req)
+	closeBody(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
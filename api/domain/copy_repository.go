@@ -0,0 +1,97 @@
+package domain
+
+type copyRepo struct {
+	collection collection
+}
+
+// FindAll Queries the copies collection with optional filters and find options
+// It returns a list of Copies or an API Error Response
+func (r *copyRepo) FindAll(filter Filter, findOptions FindOptions) (Copies, *BookAPIError) {
+	copies := Copies{}
+	if err := r.collection.find(filter, findOptions, &copies); err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+// FindOne Queries the copies collection for a specific Copy
+// It returns one Copy or an API Error Response
+func (r *copyRepo) FindOne(id string) (Copy, *BookAPIError) {
+	copies, err := r.FindAll(idFilter(id), FindOptions{Limit: 1})
+	if err != nil {
+		return Copy{}, err
+	}
+
+	if len(copies) == 0 {
+		return Copy{}, NewCopyNotFoundError(id)
+	}
+
+	return copies[0], nil
+}
+
+// Update sets the fields of the Copy with the specified ID when it matches the condition, incrementing its version.
+// The version is compared and set in the same update, the Copy is read again when another write incremented it first
+// It returns an API Error Response if failed
+func (r *copyRepo) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	for {
+		bookCopy, err := r.FindOne(id)
+		if err != nil {
+			return err
+		}
+
+		versioned := Fields{"version": bookCopy.Version + 1}
+		for field, value := range fields {
+			if field != "version" {
+				versioned[field] = value
+			}
+		}
+
+		updated, err := r.collection.update(id, AllOf(condition, versionCondition(Versions{bookCopy.Version})), versioned)
+		if err != nil || updated {
+			return err
+		}
+
+		current, err := r.FindOne(id)
+		if err != nil {
+			return err
+		}
+
+		if current.Version == bookCopy.Version {
+			// still at the version read, the Copy does not match the condition
+			return NewCopyNotFoundError(id)
+		}
+	}
+}
+
+// Delete removes the Copy with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *copyRepo) Delete(id string, condition Filter) *BookAPIError {
+	deleted, err := r.collection.delete(id, condition)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return NewCopyNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Save Saves the Copy Payload, generating an ID when it has none
+// It returns the persisted Copy ID or an API Error Response if failed
+func (r *copyRepo) Save(bookCopy Copy) (string, *BookAPIError) {
+	return r.collection.insert(bookCopy)
+}
+
+// NewCopyRepository Initializes the copies repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewCopyRepository() (CopyRepository, *BookAPIError) {
+	collection, err := newCollection("copies")
+	if err != nil {
+		return nil, err
+	}
+
+	return &copyRepo{collection: collection}, nil
+}
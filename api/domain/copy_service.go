@@ -0,0 +1,204 @@
+package domain
+
+import "fmt"
+
+type copyService struct {
+	repository Repository
+	copies     CopyRepository
+	holds      HoldRepository
+	loans      LoanRepository
+}
+
+func (s *copyService) FindAll(bookID string) (Copies, *BookAPIError) {
+	if _, err := s.repository.FindOne(bookID); err != nil {
+		return nil, err
+	}
+
+	return s.copies.FindAll(NewQueryFilter("book_id", Equals, bookID), copiesByBarcode)
+}
+
+func (s *copyService) FindOne(bookID string, id string) (Copy, *BookAPIError) {
+	bookCopy, err := s.copies.FindOne(id)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	if bookCopy.BookID != bookID {
+		return Copy{}, NewCopyNotFoundError(id)
+	}
+
+	return bookCopy, nil
+}
+
+func (s *copyService) Create(bookID string, bookCopy Copy) (string, *BookAPIError) {
+	if len(bookCopy.Condition) == 0 {
+		bookCopy.Condition = "good"
+	}
+
+	if validationError := bookCopy.Validate(); validationError != nil {
+		return "", validationError
+	}
+
+	book, err := s.repository.FindOne(bookID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.checkShelved(book); err != nil {
+		return "", err
+	}
+
+	if err := s.checkBarcode(bookCopy.Barcode, ""); err != nil {
+		return "", err
+	}
+
+	// a new Copy is on the shelf, its status only changes by checking it out
+	bookCopy.BookID, bookCopy.Status, bookCopy.Version = bookID, CheckedIn, 1
+	return s.copies.Save(bookCopy)
+}
+
+func (s *copyService) Update(bookID string, id string, versions Versions, bookCopy Copy) *BookAPIError {
+	if validationError := bookCopy.Validate(); validationError != nil {
+		return validationError
+	}
+
+	current, err := s.FindOne(bookID, id)
+	if err != nil {
+		return err
+	}
+
+	if !versions.Match(current.Version) {
+		return NewPreconditionFailedError(copyKind, id)
+	}
+
+	if err := s.checkBarcode(bookCopy.Barcode, id); err != nil {
+		return err
+	}
+
+	fields := Fields{"barcode": bookCopy.Barcode, "branch": bookCopy.Branch, "condition": bookCopy.Condition}
+	if err := s.copies.Update(id, versionCondition(versions), fields); err != nil {
+		if err.errorType == NotFoundError {
+			// written since it was read
+			return NewPreconditionFailedError(copyKind, id)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *copyService) Delete(bookID string, id string, versions Versions) *BookAPIError {
+	bookCopy, err := s.FindOne(bookID, id)
+	if err != nil {
+		return err
+	}
+
+	if !versions.Match(bookCopy.Version) {
+		return NewPreconditionFailedError(copyKind, id)
+	}
+
+	// a Copy lent, kept for a Patron or away from the shelf stays until it is back, or was withdrawn or lost
+	deleteError := s.copies.Delete(id, AllOf(NewQueryFilter("status", In, deletableStatuses), versionCondition(versions)))
+	if deleteError == nil || deleteError.errorType != NotFoundError {
+		return deleteError
+	}
+
+	// the Copy now tells whether its version or its status did not match
+	if bookCopy, err = s.FindOne(bookID, id); err != nil {
+		return err
+	}
+
+	if !versions.Match(bookCopy.Version) {
+		return NewPreconditionFailedError(copyKind, id)
+	}
+
+	return NewValidationError(fmt.Sprintf("Copy %s is %s, only a Copy checked in, withdrawn or lost can be deleted", id, bookCopy.Status))
+}
+
+func (s *copyService) Availability(bookID string) (Availability, *BookAPIError) {
+	book, err := s.repository.FindOne(bookID)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	copies, err := s.copies.FindAll(NewQueryFilter("book_id", Equals, bookID), FindOptions{})
+	if err != nil {
+		return Availability{}, err
+	}
+
+	waiting := AllOf(NewQueryFilter("book_id", Equals, bookID), NewQueryFilter("ready_at", Equals, nil))
+	holds, err := s.holds.FindAll(waiting, FindOptions{})
+	if err != nil {
+		return Availability{}, err
+	}
+
+	availability := Availability{Holds: len(holds)}
+	for _, item := range shelfItems(book, copies) {
+		if item.status == Withdrawn {
+			continue
+		}
+
+		availability.Copies++
+		switch item.status {
+		case CheckedIn:
+			availability.Available++
+		case CheckedOut:
+			availability.CheckedOut++
+		case OnHoldShelf:
+			availability.OnHoldShelf++
+		default:
+			availability.Unavailable++
+		}
+	}
+
+	return availability, nil
+}
+
+// checkShelved returns a Validation Error if the Book has no copies yet and is not on the shelf. Once it has copies
+// the Book is lent through them only, so its own loan or hold shelf could not be ended by checking it in
+func (s *copyService) checkShelved(book Book) *BookAPIError {
+	id := book.ID.Hex()
+	copies, err := s.copies.FindAll(NewQueryFilter("book_id", Equals, id), FindOptions{Limit: 1})
+	if err != nil || len(copies) > 0 {
+		return err
+	}
+
+	open := AllOf(NewQueryFilter("book_id", Equals, id), NewQueryFilter("copy_id", Equals, nil), NewQueryFilter("returned_at", Equals, nil))
+	loans, err := s.loans.FindAll(open, FindOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	if book.Status != CheckedIn || len(loans) > 0 {
+		return NewValidationError(fmt.Sprintf("Book %s is %s, check it in before adding copies", id, book.Status))
+	}
+
+	return nil
+}
+
+// checkBarcode returns an Already Exists error if a Copy other than the one with the ID has the barcode
+func (s *copyService) checkBarcode(barcode string, id string) *BookAPIError {
+	copies, err := s.copies.FindAll(NewQueryFilter("barcode", Equals, barcode), FindOptions{Limit: 2})
+	if err != nil {
+		return err
+	}
+
+	for _, bookCopy := range copies {
+		if bookCopy.ID.Hex() != id {
+			return NewCopyAlreadyExistsError(barcode)
+		}
+	}
+
+	return nil
+}
+
+// deletableStatuses statuses of the copies that can be deleted, none of which is lent or kept for a Patron
+var deletableStatuses = []interface{}{CheckedIn, Withdrawn, Lost}
+
+// copiesByBarcode find options listing the copies of a Book
+var copiesByBarcode = FindOptions{Sort: []Sort{{Field: "barcode", Order: ASC}}}
+
+// NewCopyService creates instance of the Copy service
+func NewCopyService(repository Repository, copies CopyRepository, holds HoldRepository, loans LoanRepository) CopyService {
+	return &copyService{repository: repository, copies: copies, holds: holds, loans: loans}
+}
@@ -0,0 +1,233 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+	"time"
+)
+
+func TestCopyService_CRUD(t *testing.T) {
+	repo := NewMemoryRepository()
+	bookID, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	copies := &copyRepo{collection: newMemoryCollection(nil)}
+	copyService := NewCopyService(repo, copies, &holdRepo{collection: newMemoryCollection(nil)}, &loanRepo{collection: newMemoryCollection(nil)})
+
+	id, err := copyService.Create(bookID, Copy{Barcode: "B-0001", Branch: "Central", Status: CheckedOut})
+	assert.Nil(t, err)
+
+	created, err := copyService.FindOne(bookID, id)
+	assert.Nil(t, err)
+	assert.Equal(t, Copy{ID: created.ID, BookID: bookID, Barcode: "B-0001", Branch: "Central", Condition: "good", Status: CheckedIn, Version: 1}, created)
+
+	_, err = copyService.Create(bookID, Copy{Barcode: "B-0001", Branch: "East"})
+	assert.Equal(t, ExistingRecord, err.errorType)
+	_, err = copyService.Create(bookID, Copy{Barcode: "B-0002", Branch: "East", Condition: "mint"})
+	assert.Equal(t, []FieldError{{Field: "condition", Rule: "in", Message: "must be a valid value"}}, err.fields)
+	_, err = copyService.Create("5ca7c76f9287bd3832d96f15", Copy{Barcode: "B-0002", Branch: "East"})
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	assert.Nil(t, copyService.Update(bookID, id, Versions{1}, Copy{Barcode: "B-0001", Branch: "East", Condition: "fair"}))
+	updated, _ := copyService.FindOne(bookID, id)
+	assert.Equal(t, "East", updated.Branch)
+	assert.Equal(t, "fair", updated.Condition)
+	assert.Equal(t, CheckedIn, updated.Status)
+	assert.Equal(t, int64(2), updated.Version)
+
+	// a write of the version read before the update does not overwrite it
+	err = copyService.Update(bookID, id, Versions{1}, Copy{Barcode: "B-0001", Branch: "West", Condition: "fair"})
+	assert.Equal(t, PreconditionFailed, err.errorType)
+	assert.Equal(t, PreconditionFailed, copyService.Delete(bookID, id, Versions{1}).errorType)
+
+	_, err = copyService.FindOne("5ca7c76f9287bd3832d96f15", id)
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	// a Copy out on loan, kept for a Patron or away from the shelf cannot be deleted
+	for _, status := range []Status{CheckedOut, OnHoldShelf, Damaged, InRepair, InTransit} {
+		assert.Nil(t, copies.Update(id, Filter{}, Fields{"status": status}))
+		assert.Equal(t, ValidationError, copyService.Delete(bookID, id, AnyVersion).errorType, status.String())
+	}
+	assert.Nil(t, copies.Update(id, Filter{}, Fields{"status": CheckedIn}))
+	assert.Nil(t, copyService.Delete(bookID, id, AnyVersion))
+
+	// nor can a withdrawn or lost one be lent again, both are deleted
+	for _, status := range []Status{Withdrawn, Lost} {
+		other, _ := copyService.Create(bookID, Copy{Barcode: "B-" + status.String(), Branch: "Central"})
+		assert.Nil(t, copies.Update(other, Filter{}, Fields{"status": status}))
+		assert.Nil(t, copyService.Delete(bookID, other, AnyVersion), status.String())
+	}
+
+	found, err := copyService.FindAll(bookID)
+	assert.Nil(t, err)
+	assert.Empty(t, found)
+}
+
+func TestCopyService_Create_Shelved(t *testing.T) {
+	repo := NewMemoryRepository()
+	bookID, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedOut, PublishDate: "2008"})
+	loans := &loanRepo{collection: newMemoryCollection(nil)}
+	copyService := NewCopyService(repo, &copyRepo{collection: newMemoryCollection(nil)}, &holdRepo{collection: newMemoryCollection(nil)}, loans)
+
+	// the loan of the Book itself could not be ended once it has copies
+	_, err := copyService.Create(bookID, Copy{Barcode: "B-0001", Branch: "Central"})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	checkedOutAt := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	loanID, _ := loans.Save(Loan{BookID: bookID, PatronID: "5ca7c76f9287bd3832d96f22", CheckedOutAt: checkedOutAt, DueAt: checkedOutAt})
+	assert.Nil(t, repo.Update(bookID, Filter{}, Fields{"status": CheckedIn}))
+	_, err = copyService.Create(bookID, Copy{Barcode: "B-0001", Branch: "Central"})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	assert.Nil(t, loans.Update(loanID, Filter{}, Fields{"returned_at": checkedOutAt}))
+	_, err = copyService.Create(bookID, Copy{Barcode: "B-0001", Branch: "Central"})
+	assert.Nil(t, err)
+
+	// the status of the Book itself no longer matters once it has copies
+	assert.Nil(t, repo.Update(bookID, Filter{}, Fields{"status": OnHoldShelf}))
+	_, err = copyService.Create(bookID, Copy{Barcode: "B-0002", Branch: "East"})
+	assert.Nil(t, err)
+}
+
+// testCopyCheckOut exercises the checkout and checkin of the copies stored in the collection, one copy reserved for
+// the Patron in line
+func testCopyCheckOut(t *testing.T, copies collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+	grace, _ := patrons.Save(Patron{Name: "Grace Hopper", Email: "grace@example.com"})
+
+	copyRepository, holds := &copyRepo{collection: copies}, &holdRepo{collection: newMemoryCollection(nil)}
+	loans := &loanRepo{collection: newMemoryCollection(nil)}
+	copyService := NewCopyService(repo, copyRepository, holds, loans)
+	first, _ := copyService.Create(id, Copy{Barcode: "B-0001", Branch: "Central"})
+	second, _ := copyService.Create(id, Copy{Barcode: "B-0002", Branch: "East"})
+
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	bookService := &service{repository: repo, patrons: patrons, loans: loans, holds: holds, copies: copyRepository,
+		policy: DefaultLoanPolicy, now: func() time.Time { return now }}
+
+	_, err := bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: ada})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	loan, err := bookService.CheckOutCopy(id, first, AnyVersion, LoanRequest{PatronID: ada})
+	assert.Nil(t, err)
+	assert.Equal(t, first, loan.CopyID)
+
+	_, err = bookService.CheckOutCopy(id, first, AnyVersion, LoanRequest{PatronID: alan})
+	assert.Equal(t, AlreadyCheckedOut, err.errorType)
+	_, err = bookService.CheckOutCopy("5ca7c76f9287bd3832d96f15", second, AnyVersion, LoanRequest{PatronID: alan})
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	// a copy is still on the shelf, nobody needs to get in line
+	_, err = bookService.PlaceHold(id, HoldRequest{PatronID: grace})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	_, err = bookService.CheckOutCopy(id, second, AnyVersion, LoanRequest{PatronID: alan})
+	assert.Nil(t, err)
+	_, err = bookService.PlaceHold(id, HoldRequest{PatronID: grace})
+	assert.Nil(t, err)
+
+	availability, err := copyService.Availability(id)
+	assert.Nil(t, err)
+	assert.Equal(t, Availability{Copies: 2, CheckedOut: 2, Holds: 1}, availability)
+
+	// the first copy back goes to the hold shelf for the Patron in line, the other loan stays open
+	assert.Nil(t, bookService.CheckInCopy(id, second, AnyVersion))
+	assert.Equal(t, AlreadyCheckedIn, bookService.CheckInCopy(id, second, AnyVersion).errorType)
+
+	availability, _ = copyService.Availability(id)
+	assert.Equal(t, Availability{Copies: 2, CheckedOut: 1, OnHoldShelf: 1}, availability)
+
+	line, _ := bookService.Holds(id)
+	if assert.Len(t, line, 1) {
+		assert.Equal(t, second, line[0].CopyID)
+		assert.NotNil(t, line[0].ReadyAt)
+	}
+
+	open, _ := loans.FindAll(NewQueryFilter("returned_at", Equals, nil), FindOptions{})
+	if assert.Len(t, open, 1) {
+		assert.Equal(t, first, open[0].CopyID)
+	}
+
+	_, err = bookService.CheckOutCopy(id, second, AnyVersion, LoanRequest{PatronID: ada})
+	assert.Equal(t, OnHold, err.errorType)
+
+	_, err = bookService.CheckOutCopy(id, second, AnyVersion, LoanRequest{PatronID: grace})
+	assert.Nil(t, err)
+
+	availability, _ = copyService.Availability(id)
+	assert.Equal(t, Availability{Copies: 2, CheckedOut: 2}, availability)
+}
+
+func TestService_CheckOutCopy(t *testing.T) {
+	testCopyCheckOut(t, newMemoryCollection(nil))
+}
+
+func TestService_CheckOutCopy_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testCopyCheckOut(t, newSQLiteCollection(t, path, "copies"))
+}
+
+// testCopyVersions exercises the checkout, checkin and transition of the copies stored in the collection at the
+// versions If-Match lists
+func testCopyVersions(t *testing.T, copies collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+
+	copyRepository, holds := &copyRepo{collection: copies}, &holdRepo{collection: newMemoryCollection(nil)}
+	copyService := NewCopyService(repo, copyRepository, holds, &loanRepo{collection: newMemoryCollection(nil)})
+	first, _ := copyService.Create(id, Copy{Barcode: "B-0001", Branch: "Central"})
+	bookService := &service{repository: repo, patrons: patrons, loans: &loanRepo{collection: newMemoryCollection(nil)},
+		holds: holds, copies: copyRepository, policy: DefaultLoanPolicy, now: loanTime}
+
+	_, err := bookService.CheckOutCopy(id, first, Versions{2}, LoanRequest{PatronID: ada})
+	assert.Equal(t, PreconditionFailed, err.errorType)
+	_, err = bookService.CheckOutCopy(id, first, Versions{1}, LoanRequest{PatronID: ada})
+	assert.Nil(t, err)
+
+	// the checkout is a write of the Copy, the version read before it no longer matches
+	assert.Equal(t, PreconditionFailed, bookService.CheckInCopy(id, first, Versions{1}).errorType)
+	assert.Nil(t, bookService.CheckInCopy(id, first, Versions{1, 2}))
+	assert.Equal(t, NewPreconditionFailedError(copyKind, first), bookService.TransitionCopy(id, first, Versions{2}, Damaged))
+	assert.Nil(t, bookService.TransitionCopy(id, first, Versions{3}, Damaged))
+	assert.Equal(t, NewIllegalTransitionError(copyKind, first, Damaged, Lost), bookService.TransitionCopy(id, first, AnyVersion, Lost))
+
+	bookCopy, _ := copyService.FindOne(id, first)
+	assert.Equal(t, int64(4), bookCopy.Version)
+}
+
+func TestService_CopyVersions(t *testing.T) {
+	testCopyVersions(t, newMemoryCollection(nil))
+}
+
+// copies saved in a document store before versioning have no version and are at version 0
+func TestService_CopyVersions_Unversioned(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	copies := newMemoryCollection(nil)
+	copyRepository, holds := &copyRepo{collection: copies}, &holdRepo{collection: newMemoryCollection(nil)}
+	copyService := NewCopyService(repo, copyRepository, holds, &loanRepo{collection: newMemoryCollection(nil)})
+	bookService := &service{repository: repo, patrons: &patronRepo{collection: newMemoryCollection(nil)},
+		loans: &loanRepo{collection: newMemoryCollection(nil)}, holds: holds, copies: copyRepository, policy: DefaultLoanPolicy, now: loanTime}
+
+	unversioned, _ := copies.insert(bson.M{"book_id": id, "barcode": "B-0001", "branch": "East", "condition": "good", "status": CheckedIn})
+	assert.Equal(t, PreconditionFailed, bookService.TransitionCopy(id, unversioned, Versions{1}, Lost).errorType)
+	assert.Nil(t, bookService.TransitionCopy(id, unversioned, Versions{0}, Lost))
+
+	bookCopy, _ := copyService.FindOne(id, unversioned)
+	assert.Equal(t, Lost, bookCopy.Status)
+	assert.Equal(t, int64(1), bookCopy.Version)
+}
+
+func TestService_CopyVersions_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testCopyVersions(t, newSQLiteCollection(t, path, "copies"))
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Cursor opaque position in a sorted listing: the sort it was created for
+// and the values of the sort fields of the Book it points at
+type Cursor struct {
+	Sort   []Sort   `json:"sort"`
+	Values []string `json:"values"`
+}
+
+// cursorSecret signs cursors so clients cannot forge positions.
+// Set cursor_secret so cursors stay valid across restarts and instances.
+var cursorSecret = loadCursorSecret()
+
+func loadCursorSecret() []byte {
+	if secret, present := os.LookupEnv("cursor_secret"); present && secret != "" {
+		return []byte(secret)
+	}
+
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+// NewCursor returns the Cursor pointing at the Book for the sort
+func NewCursor(book Book, sortFields []Sort) Cursor {
+	doc, _ := toDocument(book)
+	values := make([]string, 0, len(sortFields))
+	for _, field := range sortFields {
+		values = append(values, formatFieldValue(doc[field.Field]))
+	}
+
+	return Cursor{Sort: sortFields, Values: values}
+}
+
+// Encode returns the signed, URL safe, token of the Cursor
+func (c Cursor) Encode() string {
+	payload, _ := json.Marshal(c)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded))
+}
+
+// DecodeCursor verifies the token signature and returns its Cursor
+// It returns a Validation Error if the token is malformed or was tampered with
+func DecodeCursor(token string) (Cursor, *BookAPIError) {
+	var cursor Cursor
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return cursor, NewValidationError("invalid cursor")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, signCursor(parts[0])) {
+		return cursor, NewValidationError("invalid cursor")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(payload, &cursor) != nil || len(cursor.Sort) != len(cursor.Values) {
+		return cursor, NewValidationError("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// KeysetFilter returns the Filter matching the Books after the Cursor position in its sort,
+// or before it when backward is set
+func (c Cursor) KeysetFilter(backward bool) (Filter, *BookAPIError) {
+	values := make([]interface{}, len(c.Values))
+	for i, text := range c.Values {
+		value, err := ParseFieldValue(c.Sort[i].Field, text)
+		if err != nil {
+			return Filter{}, NewValidationError("invalid cursor")
+		}
+		values[i] = value
+	}
+
+	// (s1 > v1) OR (s1 = v1 AND s2 > v2) OR ...
+	alternatives := make([]Filter, 0, len(c.Sort))
+	for i, field := range c.Sort {
+		conditions := make([]Filter, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, NewQueryFilter(c.Sort[j].Field, Equals, values[j]))
+		}
+
+		operator := GreaterThan
+		if (field.Order == DESC) != backward {
+			operator = LessThan
+		}
+		conditions = append(conditions, NewQueryFilter(field.Field, operator, values[i]))
+		alternatives = append(alternatives, AllOf(conditions...))
+	}
+
+	return AnyOf(alternatives...), nil
+}
+
+// matchesSort reports whether the Cursor was created for the sort
+func (c Cursor) matchesSort(sortFields []Sort) bool {
+	return reflect.DeepEqual(c.Sort, sortFields)
+}
+
+func signCursor(payload string) []byte {
+	mac := hmac.New(sha256.New, cursorSecret)
+	_, _ = mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// formatFieldValue returns the text ParseFieldValue parses back into the value
+func formatFieldValue(value interface{}) string {
+	if id, ok := value.(primitive.ObjectID); ok {
+		return id.Hex()
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.String:
+		return v.String()
+	}
+
+	return ""
+}
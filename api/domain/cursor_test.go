@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCursor_EncodeAndDecode(t *testing.T) {
+	cursor := Cursor{Sort: []Sort{{Field: "rating", Order: DESC}, {Field: "_id", Order: ASC}}, Values: []string{"2", "5ca7c76f9287bd3832d96f15"}}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	assert.Nil(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestCursor_DecodeTampered(t *testing.T) {
+	token := Cursor{Sort: []Sort{{Field: "_id", Order: ASC}}, Values: []string{"5ca7c76f9287bd3832d96f15"}}.Encode()
+	forged := Cursor{Sort: []Sort{{Field: "_id", Order: ASC}}, Values: []string{"5ca7c76f9287bd3832d96f16"}}.Encode()
+
+	for _, invalid := range []string{"", "abc", token + "x", strings.Split(forged, ".")[0] + "." + strings.Split(token, ".")[1]} {
+		_, err := DecodeCursor(invalid)
+		assert.NotNil(t, err, invalid)
+		assert.Equal(t, ValidationError, err.errorType, invalid)
+	}
+}
+
+func TestCursor_KeysetFilter(t *testing.T) {
+	cursor := Cursor{Sort: []Sort{{Field: "rating", Order: DESC}, {Field: "_id", Order: ASC}}, Values: []string{"2", "5ca7c76f9287bd3832d96f15"}}
+	id, _ := ParseFieldValue("_id", "5ca7c76f9287bd3832d96f15")
+
+	filter, err := cursor.KeysetFilter(false)
+	assert.Nil(t, err)
+	assert.Equal(t, AnyOf(
+		NewQueryFilter("rating", LessThan, float64(2)),
+		AllOf(NewQueryFilter("rating", Equals, float64(2)), NewQueryFilter("_id", GreaterThan, id)),
+	), filter)
+
+	filter, err = cursor.KeysetFilter(true)
+	assert.Nil(t, err)
+	assert.Equal(t, AnyOf(
+		NewQueryFilter("rating", GreaterThan, float64(2)),
+		AllOf(NewQueryFilter("rating", Equals, float64(2)), NewQueryFilter("_id", LessThan, id)),
+	), filter)
+}
+
+func TestQueryBuilder_GetQueryParams_WithCursor(t *testing.T) {
+	repo := seedMemoryRepository(t)
+	builder := QueryBuilder{}
+	fetch := func(rawQuery string) ResultPage {
+		request, err := builder.GetQueryParams(newQueryRequest(rawQuery))
+		assert.Nil(t, err, rawQuery)
+
+		books, findErr := repo.FindAll(request.FindFilter(), request.FindOptions)
+		assert.Nil(t, findErr, rawQuery)
+		return request.Paginate(books)
+	}
+
+	page := fetch("sort=author&size=2")
+	assert.Len(t, page.Books, 2)
+	assert.Equal(t, "The Alchemist", page.Books[0].Title)
+	assert.Nil(t, page.Prev)
+	assert.NotNil(t, page.Next)
+
+	page = fetch("size=2&after=" + url.QueryEscape(page.Next.Encode()))
+	assert.Len(t, page.Books, 1)
+	assert.Equal(t, "Robert Martin", page.Books[0].Author)
+	assert.Nil(t, page.Next)
+	assert.NotNil(t, page.Prev)
+
+	page = fetch("size=2&before=" + url.QueryEscape(page.Prev.Encode()))
+	assert.Len(t, page.Books, 2)
+	assert.Equal(t, "The Alchemist", page.Books[0].Title)
+	assert.Nil(t, page.Prev)
+	assert.NotNil(t, page.Next)
+
+	page = fetch("size=2&page=2&sort=author")
+	assert.Len(t, page.Books, 1)
+	assert.Nil(t, page.Next)
+	assert.NotNil(t, page.Prev)
+}
+
+func TestQueryBuilder_GetQueryParams_WithInvalidCursor(t *testing.T) {
+	builder := QueryBuilder{}
+	token := url.QueryEscape(Cursor{Sort: []Sort{{Field: "_id", Order: ASC}}, Values: []string{"5ca7c76f9287bd3832d96f15"}}.Encode())
+
+	for _, rawQuery := range []string{"after=abc", "after=" + token + "&before=" + token, "sort=-rating&after=" + token} {
+		_, err := builder.GetQueryParams(newQueryRequest(rawQuery))
+		assert.NotNil(t, err, rawQuery)
+		assert.Equal(t, ValidationError, err.errorType, rawQuery)
+	}
+}
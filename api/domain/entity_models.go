@@ -1,37 +1,246 @@
 package domain
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/go-ozzo/ozzo-validation"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
 )
 
 // Book slice of books
 type Books []Book
 
-// Book model for Book schema
+// Book model for Book schema.
+// Version is incremented by every write and sent back as the ETag of the Book
 type Book struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
 	Author      string             `bson:"author" json:"author"`
 	Title       string             `bson:"title" json:"title"`
 	Publisher   string             `bson:"publisher" json:"publisher"`
 	Status      Status             `bson:"status" json:"status"`
-	Rating      int                `bson:"rating" json:"rating"`
+	Rating      float64            `bson:"rating" json:"rating"`
 	PublishDate string             `bson:"publish_date" json:"publish_date"`
+	Version     int64              `bson:"version" json:"version"`
+	// RatingAverage, RatingCount and RatingDistribution aggregate the Ratings of the Patrons, kept up to date by
+	// rating the Book. Rating is then their average rounded to the scale
+	RatingAverage      float64            `bson:"rating_average" json:"rating_average"`
+	RatingCount        int                `bson:"rating_count" json:"rating_count"`
+	RatingDistribution RatingDistribution `bson:"rating_distribution" json:"rating_distribution,omitempty"`
+	// RatingScale names the scale Rating was last rescaled to, empty while it is on the scale the Ratings are stored on
+	RatingScale string `bson:"rating_scale" json:"-"`
 }
 
 // Validate validates the Book fields.
+// It returns a Validation Error listing the rule each invalid field breaks
 func (b Book) Validate() *BookAPIError {
 	errors := validation.ValidateStruct(&b,
-		validation.Field(&b.Author, validation.Required, validation.Length(1, 30)),
-		validation.Field(&b.Title, validation.Required, validation.Length(1, 50)),
-		validation.Field(&b.Publisher, validation.Required, validation.Length(1, 20)),
-		validation.Field(&b.Status, validation.Required, validation.In(CheckedIn, CheckedOut)),
-		validation.Field(&b.Rating, validation.In(0, 1, 2, 3)),
-		validation.Field(&b.PublishDate, validation.Required, validation.Date("2006")),
+		validation.Field(&b.Author, named("required", validation.Required), named("length", validation.Length(1, 30))),
+		validation.Field(&b.Title, named("required", validation.Required), named("length", validation.Length(1, 50))),
+		validation.Field(&b.Publisher, named("required", validation.Required), named("length", validation.Length(1, 20))),
+		validation.Field(&b.Status, named("required", validation.Required), named("in", validation.In(statuses...))),
+		validation.Field(&b.Rating, named("in", ratingScale.unratedOr())),
+		validation.Field(&b.PublishDate, named("required", validation.Required), named("date", validation.Date("2006"))),
 	)
 
+	if fieldErrors, ok := errors.(validation.Errors); ok {
+		return NewFieldValidationError(fieldErrors)
+	}
+
+	if errors != nil {
+		return NewValidationError(errors.Error())
+	}
+
+	return nil
+}
+
+// ratingAggregates the Book fields computed from the Ratings, which PUT and PATCH do not change
+var ratingAggregates = []string{"rating", "rating_average", "rating_count", "rating_distribution"}
+
+// RatingDistribution number of Ratings of a Book given each value of the scale, keyed by the value
+type RatingDistribution map[string]int
+
+// Ratings slice of ratings
+type Ratings []Rating
+
+// Rating model for Rating schema, the rating a Patron gives a Book. A Patron has a single Rating per Book,
+// rating it again changes its value
+type Rating struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	BookID   string             `bson:"book_id" json:"book_id"`
+	PatronID string             `bson:"patron_id" json:"patron_id"`
+	Value    float64            `bson:"rating" json:"rating"`
+	RatedAt  time.Time          `bson:"rated_at" json:"rated_at"`
+	// Scale names the scale Value was last rescaled to, empty while it is on the scale the Ratings are stored on
+	Scale string `bson:"rating_scale" json:"-"`
+}
+
+// Validate validates the Rating value is on the rating scale.
+// It returns a Validation Error naming the rule the value breaks
+func (r Rating) Validate() *BookAPIError {
+	errors := validation.ValidateStruct(&r,
+		validation.Field(&r.Value, named("in", ratingScale)),
+	)
+
+	if fieldErrors, ok := errors.(validation.Errors); ok {
+		return NewFieldValidationError(fieldErrors)
+	}
+
+	if errors != nil {
+		return NewValidationError(errors.Error())
+	}
+
+	return nil
+}
+
+// RatingRequest body of a rating, the Patron rating the Book
+type RatingRequest struct {
+	PatronID string `json:"patron_id"`
+}
+
+// RatingSummary the Ratings of a Book, newest first, with their average, count and distribution
+type RatingSummary struct {
+	Average      float64            `json:"average"`
+	Count        int                `json:"count"`
+	Distribution RatingDistribution `json:"distribution"`
+	Ratings      Ratings            `json:"ratings"`
+}
+
+// ReviewStatus where a Review stands in moderation, only the approved Reviews are listed with the Book
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// Reviews slice of reviews
+type Reviews []Review
+
+// Review model for Review schema, the text a Patron writes about a Book. A Patron has a single Review per Book,
+// pending moderation until staff approve it and again whenever its author edits it
+type Review struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	BookID       string             `bson:"book_id" json:"book_id"`
+	PatronID     string             `bson:"patron_id" json:"patron_id"`
+	Title        string             `bson:"title" json:"title"`
+	Text         string             `bson:"text" json:"text"`
+	Status       ReviewStatus       `bson:"status" json:"status"`
+	HelpfulVotes int                `bson:"helpful_votes" json:"helpful_votes"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	ModeratedAt  *time.Time         `bson:"moderated_at,omitempty" json:"moderated_at,omitempty"`
+}
+
+// Validate validates the Review fields written by its author.
+// It returns a Validation Error listing the rule each invalid field breaks
+func (r Review) Validate() *BookAPIError {
+	errors := validation.ValidateStruct(&r,
+		validation.Field(&r.PatronID, named("required", validation.Required)),
+		validation.Field(&r.Title, named("required", validation.Required), named("length", validation.Length(1, 100))),
+		validation.Field(&r.Text, named("required", validation.Required), named("length", validation.Length(1, 5000))),
+	)
+
+	if fieldErrors, ok := errors.(validation.Errors); ok {
+		return NewFieldValidationError(fieldErrors)
+	}
+
+	if errors != nil {
+		return NewValidationError(errors.Error())
+	}
+
+	return nil
+}
+
+// ReviewVote a Patron finding a Review helpful, once per Review
+type ReviewVote struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	ReviewID string             `bson:"review_id" json:"review_id"`
+	PatronID string             `bson:"patron_id" json:"patron_id"`
+	VotedAt  time.Time          `bson:"voted_at" json:"voted_at"`
+}
+
+// ReviewVotes slice of review votes
+type ReviewVotes []ReviewVote
+
+// VoteRequest body of a helpful vote, the Patron who found the Review helpful
+type VoteRequest struct {
+	PatronID string `json:"patron_id"`
+}
+
+// Patrons slice of patrons
+type Patrons []Patron
+
+// Patron model for Patron schema, a member of the library who borrows Books
+type Patron struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	Name  string             `bson:"name" json:"name"`
+	Email string             `bson:"email" json:"email"`
+}
+
+// emailPattern loose shape of an email address, its mailbox is not checked
+var emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// Validate validates the Patron fields.
+// It returns a Validation Error listing the rule each invalid field breaks
+func (p Patron) Validate() *BookAPIError {
+	errors := validation.ValidateStruct(&p,
+		validation.Field(&p.Name, named("required", validation.Required), named("length", validation.Length(1, 50))),
+		validation.Field(&p.Email, named("required", validation.Required), named("length", validation.Length(3, 100)),
+			named("email", validation.Match(emailPattern))),
+	)
+
+	if fieldErrors, ok := errors.(validation.Errors); ok {
+		return NewFieldValidationError(fieldErrors)
+	}
+
+	if errors != nil {
+		return NewValidationError(errors.Error())
+	}
+
+	return nil
+}
+
+// Loans slice of loans
+type Loans []Loan
+
+// Copies slice of copies
+type Copies []Copy
+
+// Copy model for Copy schema, a physical item of a Book. A Book catalogued without copies is its own single item
+type Copy struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	BookID    string             `bson:"book_id" json:"book_id"`
+	Barcode   string             `bson:"barcode" json:"barcode"`
+	Branch    string             `bson:"branch" json:"branch"`
+	Condition string             `bson:"condition" json:"condition"`
+	Status    Status             `bson:"status" json:"status"`
+	Version   int64              `bson:"version" json:"version"`
+}
+
+// copyConditions the conditions a Copy may be in, best first
+var copyConditions = []interface{}{"new", "good", "fair", "poor"}
+
+// Validate validates the Copy fields.
+// It returns a Validation Error listing the rule each invalid field breaks
+func (c Copy) Validate() *BookAPIError {
+	errors := validation.ValidateStruct(&c,
+		validation.Field(&c.Barcode, named("required", validation.Required), named("length", validation.Length(1, 30))),
+		validation.Field(&c.Branch, named("required", validation.Required), named("length", validation.Length(1, 50))),
+		validation.Field(&c.Condition, named("required", validation.Required), named("in", validation.In(copyConditions...))),
+	)
+
+	if fieldErrors, ok := errors.(validation.Errors); ok {
+		return NewFieldValidationError(fieldErrors)
+	}
+
 	if errors != nil {
 		return NewValidationError(errors.Error())
 	}
@@ -39,6 +248,93 @@ func (b Book) Validate() *BookAPIError {
 	return nil
 }
 
+// Availability counts of the copies of a Book by status, and of the Patrons waiting in line for one.
+// Unavailable counts the copies lost, damaged, in repair or in transit, the withdrawn ones are not counted
+type Availability struct {
+	Copies      int `json:"copies"`
+	Available   int `json:"available"`
+	CheckedOut  int `json:"checked_out"`
+	OnHoldShelf int `json:"on_hold_shelf"`
+	Unavailable int `json:"unavailable"`
+	Holds       int `json:"holds"`
+}
+
+// Loan model for Loan schema, a Book checked out by a Patron.
+// The loan is open until the Book is checked in, ReturnedAt is then set.
+// OverdueAt is set by the sweep finding the loan still open after its due date
+type Loan struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	BookID       string             `bson:"book_id" json:"book_id"`
+	CopyID       string             `bson:"copy_id,omitempty" json:"copy_id,omitempty"`
+	PatronID     string             `bson:"patron_id" json:"patron_id"`
+	CheckedOutAt time.Time          `bson:"checked_out_at" json:"checked_out_at"`
+	DueAt        time.Time          `bson:"due_at" json:"due_at"`
+	ReturnedAt   *time.Time         `bson:"returned_at,omitempty" json:"returned_at,omitempty"`
+	OverdueAt    *time.Time         `bson:"overdue_at,omitempty" json:"overdue_at,omitempty"`
+}
+
+// LoanRequest terms of a checkout: the Patron borrowing the Book and for how many days, the loan period when 0
+type LoanRequest struct {
+	PatronID string `json:"patron_id"`
+	Days     int    `json:"loan_days"`
+}
+
+// Holds slice of holds
+type Holds []Hold
+
+// Hold model for Hold schema, a Patron waiting in line for a Book.
+// The first Hold of a Book checked in becomes ready: the Book, or the Copy checked in, is kept on the hold shelf for
+// the Patron until ExpiresAt
+type Hold struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id,omitempty"`
+	BookID    string             `bson:"book_id" json:"book_id"`
+	CopyID    string             `bson:"copy_id,omitempty" json:"copy_id,omitempty"`
+	PatronID  string             `bson:"patron_id" json:"patron_id"`
+	PlacedAt  time.Time          `bson:"placed_at" json:"placed_at"`
+	ReadyAt   *time.Time         `bson:"ready_at,omitempty" json:"ready_at,omitempty"`
+	ExpiresAt *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	// Position place of the Hold in the line of the Book, 1 for the next Patron
+	Position int `bson:"-" json:"position"`
+}
+
+// HoldRequest body of a hold, the Patron getting in line
+type HoldRequest struct {
+	PatronID string `json:"patron_id"`
+}
+
+// OverdueLoan an open Loan past its due date
+type OverdueLoan struct {
+	Loan
+	DaysOverdue int `json:"days_overdue"`
+}
+
+// OverdueLoans open Loans past their due date, most overdue first
+type OverdueLoans []OverdueLoan
+
+// namedRule a validation rule whose errors tell its name
+type namedRule struct {
+	name string
+	rule validation.Rule
+}
+
+// ruleError the error of a named validation rule
+type ruleError struct {
+	rule string
+	error
+}
+
+func named(name string, rule validation.Rule) validation.Rule {
+	return namedRule{name: name, rule: rule}
+}
+
+func (r namedRule) Validate(value interface{}) error {
+	if err := r.rule.Validate(value); err != nil {
+		return ruleError{rule: r.name, error: err}
+	}
+
+	return nil
+}
+
 // Status
 type Status int
 
@@ -47,28 +343,157 @@ const (
 	Unknown Status = iota
 	CheckedIn
 	CheckedOut
+	OnHoldShelf
+	Lost
+	Damaged
+	InRepair
+	InTransit
+	Withdrawn
 )
 
+// statuses the statuses a Book or a Copy may be in
+var statuses = []interface{}{CheckedIn, CheckedOut, OnHoldShelf, Lost, Damaged, InRepair, InTransit, Withdrawn}
+
+// statusTransitions the statuses an item may go to from each status. Checking out, checking in and the holds move it
+// between CheckedIn, CheckedOut and OnHoldShelf, the others are set by the branches
+var statusTransitions = map[Status][]Status{
+	CheckedIn:   {CheckedOut, OnHoldShelf, InTransit, Lost, Damaged, Withdrawn},
+	CheckedOut:  {CheckedIn, OnHoldShelf, Lost, Damaged},
+	OnHoldShelf: {CheckedOut, CheckedIn, Lost, Damaged},
+	Lost:        {CheckedIn, OnHoldShelf, Withdrawn},
+	Damaged:     {InRepair, Withdrawn},
+	InRepair:    {CheckedIn, OnHoldShelf, Damaged, Withdrawn},
+	InTransit:   {CheckedIn, OnHoldShelf, Lost, Damaged},
+	Withdrawn:   {},
+}
+
+// CanBecome reports whether an item in the status may go to the other one. A status never set, i.e. of a Book saved
+// before the statuses were validated, may become any
+func (status Status) CanBecome(to Status) bool {
+	if status == Unknown {
+		return true
+	}
+
+	for _, allowed := range statusTransitions[status] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (status Status) String() string {
 	names := [...]string{
 		"CheckedIn",
 		"CheckedOut",
+		"OnHoldShelf",
+		"Lost",
+		"Damaged",
+		"InRepair",
+		"InTransit",
+		"Withdrawn",
 	}
 
 	// prevent panicking in case of
 	// `status` is out of range
-	if status < CheckedIn || status > CheckedOut {
+	if status < CheckedIn || status > Withdrawn {
 		return "Unknown"
 	}
 
-	return names[status]
+	return names[status-1]
+}
+
+// parseStatus returns the Status with the name, i.e. InRepair, false when there is none
+func parseStatus(name string) (Status, bool) {
+	for _, status := range statuses {
+		if status.(Status).String() == name {
+			return status.(Status), true
+		}
+	}
+
+	return Unknown, false
+}
+
+// MarshalText returns the name of the Status, the form of the status in JSON
+func (status Status) MarshalText() ([]byte, error) {
+	return []byte(status.String()), nil
+}
+
+// UnmarshalText reads the name of a Status or, as sent and stored before statuses were named, its number
+func (status *Status) UnmarshalText(text []byte) error {
+	if parsed, ok := parseStatus(string(text)); ok {
+		*status = parsed
+		return nil
+	}
+
+	if string(text) == Unknown.String() {
+		*status = Unknown
+		return nil
+	}
+
+	number, err := strconv.Atoi(string(text))
+	if err != nil {
+		return fmt.Errorf("%q is not a status", text)
+	}
+
+	*status = Status(number)
+	return nil
+}
+
+// UnmarshalJSON reads the Status from a JSON string holding its name or a legacy JSON number
+func (status *Status) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	text := string(data)
+	if err := json.Unmarshal(data, &text); err != nil {
+		// not a string, the number is read as it is
+		text = string(data)
+	}
+
+	if err := status.UnmarshalText([]byte(text)); err != nil {
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(*status)}
+	}
+
+	return nil
+}
+
+// UnmarshalBSONValue reads the Status from a document. Statuses are stored as numbers, which keeps them sorted
+// in their declaration order, names are read as well
+func (status *Status) UnmarshalBSONValue(valueType bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: valueType, Value: data}
+	if name, ok := value.StringValueOK(); ok {
+		return status.UnmarshalText([]byte(name))
+	}
+
+	if number, ok := value.Int32OK(); ok {
+		*status = Status(number)
+		return nil
+	}
+
+	if number, ok := value.Int64OK(); ok {
+		*status = Status(number)
+		return nil
+	}
+
+	// mongoimport stores the numbers of JSON files as doubles
+	if number, ok := value.DoubleOK(); ok && number == math.Trunc(number) {
+		*status = Status(number)
+		return nil
+	}
+
+	return fmt.Errorf("cannot decode %s into a Status", valueType)
 }
 
+// SortOrder direction of a Sort
 type SortOrder int
 
+// Sort orders
 const (
 	ASC  SortOrder = 1
-	DESC           = -1
+	DESC SortOrder = -1
 )
 
 // QueryOperator possible query operations
@@ -76,37 +501,224 @@ type QueryOperator string
 
 const (
 	Equals             QueryOperator = "="
-	LessThan                         = "<"
-	GreaterThan                      = ">"
+	LessThan           QueryOperator = "<"
+	GreaterThan        QueryOperator = ">"
 	GreaterThanOrEqual               = GreaterThan + Equals
 	LessThanOrEqual                  = LessThan + Equals
-	DoesNotEqual                     = "!="
+	DoesNotEqual       QueryOperator = "!="
+	In                 QueryOperator = "=in="
+	StartsWith         QueryOperator = "^="
+	Contains           QueryOperator = "*="
 )
 
+// Query a condition on a single Book field, identified by its bson name.
+// The Value of an In query is a []interface{}
 type Query struct {
 	Field    string
 	Operator QueryOperator
-	Value    string
+	Value    interface{}
 }
 
+// LogicalOperator combines the children of a Filter
+type LogicalOperator string
+
+// Logical operators
+const (
+	And LogicalOperator = "AND"
+	Or  LogicalOperator = "OR"
+	Not LogicalOperator = "NOT"
+)
+
+// Filter node of a filter expression tree.
+// A leaf holds a Query, any other node combines its Children with the Operator.
+// The zero value matches every Book
+type Filter struct {
+	Operator LogicalOperator
+	Children []Filter
+	Query    *Query
+}
+
+// Sort orders results on a single Book field, identified by its bson name
+type Sort struct {
+	Field string
+	Order SortOrder
+}
+
+// FindOptions storage-neutral pagination and ordering of query results
+type FindOptions struct {
+	Limit int64
+	Skip  int64
+	Sort  []Sort
+}
+
+// Versions versions of a Book or a Copy a write applies to, the entity tags If-Match lists
+type Versions []int64
+
+// AnyVersion versions of a write that applies whatever the current version of the Book or the Copy, i.e. with If-Match: *
+var AnyVersion Versions
+
+// Match reports whether the version is one of the versions, any version matching AnyVersion
+func (v Versions) Match(version int64) bool {
+	if len(v) == 0 {
+		return true
+	}
+
+	for _, candidate := range v {
+		if candidate == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fields new values of Book fields keyed by their bson name
+type Fields map[string]interface{}
+
+// SearchHit a Book matching a full-text or fuzzy search with its relevance, higher is more relevant.
+// Listings that are not searches leave the score out
+type SearchHit struct {
+	Book  `bson:",inline"`
+	Score float64 `json:"score,omitempty" bson:"score"`
+}
+
+// SearchHits Books matching a full-text search, most relevant first
+type SearchHits []SearchHit
+
 /** ======== Repository Interface ========*/
 type Repository interface {
-	FindAll(filters bson.M, findOptions *options.FindOptions) (Books, *BookAPIError)
+	FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError)
+	Count(filter Filter) (int64, *BookAPIError)
+	Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError)
 	FindOne(id string) (Book, *BookAPIError)
-	Update(id string, fields bson.D) *BookAPIError
-	Delete(id string) *BookAPIError
+	Update(id string, condition Filter, fields Fields) *BookAPIError
+	Delete(id string, condition Filter) *BookAPIError
 	IsExistingEntry(book Book) bool
 	Save(book Book) (string, *BookAPIError)
 }
 
 /** ======== Service Interface ========*/
 type Service interface {
-	FindAll(filters bson.M, findOptions *options.FindOptions) (Books, *BookAPIError)
+	FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError)
+	Count(filter Filter) (int64, *BookAPIError)
+	Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError)
+	FindSimilar(filter Filter, conditions []Query, findOptions FindOptions) (FuzzyResult, *BookAPIError)
 	FindOne(id string) (Book, *BookAPIError)
-	Update(id string, book Book) *BookAPIError
-	Delete(id string) *BookAPIError
-	CheckOut(id string) *BookAPIError
-	CheckIn(id string) *BookAPIError
+	Update(id string, versions Versions, book Book) *BookAPIError
+	Patch(id string, versions Versions, patch BookPatch) (Book, *BookAPIError)
+	Delete(id string, versions Versions) *BookAPIError
+	CheckOut(id string, versions Versions, request LoanRequest) (Loan, *BookAPIError)
+	CheckIn(id string, versions Versions) *BookAPIError
+	CheckOutCopy(id string, copyID string, versions Versions, request LoanRequest) (Loan, *BookAPIError)
+	CheckInCopy(id string, copyID string, versions Versions) *BookAPIError
+	Transition(id string, versions Versions, status Status) *BookAPIError
+	TransitionCopy(id string, copyID string, versions Versions, status Status) *BookAPIError
+	Loans(id string) (Loans, *BookAPIError)
+	PlaceHold(id string, request HoldRequest) (Hold, *BookAPIError)
+	CancelHold(id string, holdID string) *BookAPIError
+	Hold(id string, holdID string) (Hold, *BookAPIError)
+	Holds(id string) (Holds, *BookAPIError)
+	ExpireHolds() (int, *BookAPIError)
 	Create(book Book) (string, *BookAPIError)
-	Rate(id string, rate int) *BookAPIError
+	Rate(id string, rate float64, request RatingRequest) (Rating, *BookAPIError)
+	Ratings(id string) (RatingSummary, *BookAPIError)
+	RescaleRatings() (int, *BookAPIError)
+}
+
+/** ======== Patron Repository Interface ========*/
+type PatronRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (Patrons, *BookAPIError)
+	FindOne(id string) (Patron, *BookAPIError)
+	Update(id string, fields Fields) *BookAPIError
+	Delete(id string) *BookAPIError
+	Save(patron Patron) (string, *BookAPIError)
+}
+
+/** ======== Loan Repository Interface ========*/
+type LoanRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (Loans, *BookAPIError)
+	Update(id string, condition Filter, fields Fields) *BookAPIError
+	Save(loan Loan) (string, *BookAPIError)
+}
+
+/** ======== Loan Service Interface ========*/
+type LoanService interface {
+	Overdue() (OverdueLoans, *BookAPIError)
+	MarkOverdue() (int, *BookAPIError)
+}
+
+/** ======== Hold Repository Interface ========*/
+type HoldRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (Holds, *BookAPIError)
+	Update(id string, condition Filter, fields Fields) *BookAPIError
+	Delete(id string, condition Filter) *BookAPIError
+	Save(hold Hold) (string, *BookAPIError)
+}
+
+/** ======== Rating Repository Interface ========*/
+type RatingRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (Ratings, *BookAPIError)
+	Update(id string, condition Filter, fields Fields) *BookAPIError
+	Save(rating Rating) (string, *BookAPIError)
+	Scale() (RatingScale, *BookAPIError)
+	SaveScale(scale RatingScale) *BookAPIError
+}
+
+/** ======== Review Repository Interface ========*/
+type ReviewRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (Reviews, *BookAPIError)
+	Count(filter Filter) (int64, *BookAPIError)
+	FindOne(id string) (Review, *BookAPIError)
+	Update(id string, condition Filter, fields Fields) *BookAPIError
+	Delete(id string, condition Filter) *BookAPIError
+	Save(review Review) (string, *BookAPIError)
+}
+
+/** ======== Review Vote Repository Interface ========*/
+type ReviewVoteRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (ReviewVotes, *BookAPIError)
+	Count(filter Filter) (int64, *BookAPIError)
+	Delete(id string, condition Filter) *BookAPIError
+	Save(vote ReviewVote) (string, *BookAPIError)
+}
+
+/** ======== Copy Repository Interface ========*/
+type CopyRepository interface {
+	FindAll(filter Filter, findOptions FindOptions) (Copies, *BookAPIError)
+	FindOne(id string) (Copy, *BookAPIError)
+	Update(id string, condition Filter, fields Fields) *BookAPIError
+	Delete(id string, condition Filter) *BookAPIError
+	Save(bookCopy Copy) (string, *BookAPIError)
+}
+
+/** ======== Copy Service Interface ========*/
+type CopyService interface {
+	FindAll(bookID string) (Copies, *BookAPIError)
+	FindOne(bookID string, id string) (Copy, *BookAPIError)
+	Update(bookID string, id string, versions Versions, bookCopy Copy) *BookAPIError
+	Delete(bookID string, id string, versions Versions) *BookAPIError
+	Create(bookID string, bookCopy Copy) (string, *BookAPIError)
+	Availability(bookID string) (Availability, *BookAPIError)
+}
+
+/** ======== Review Service Interface ========*/
+type ReviewService interface {
+	FindAll(bookID string, request ReviewRequest) (Reviews, int64, *BookAPIError)
+	FindOne(bookID string, id string) (Review, *BookAPIError)
+	Create(bookID string, review Review) (string, *BookAPIError)
+	Update(bookID string, id string, review Review) *BookAPIError
+	Delete(bookID string, id string, patronID string) *BookAPIError
+	Vote(bookID string, id string, request VoteRequest) (Review, *BookAPIError)
+	Queue(request ReviewRequest) (Reviews, int64, *BookAPIError)
+	Moderate(id string, status ReviewStatus) (Review, *BookAPIError)
+}
+
+/** ======== Patron Service Interface ========*/
+type PatronService interface {
+	FindAll(findOptions FindOptions) (Patrons, *BookAPIError)
+	FindOne(id string) (Patron, *BookAPIError)
+	Update(id string, patron Patron) *BookAPIError
+	Delete(id string) *BookAPIError
+	Create(patron Patron) (string, *BookAPIError)
+	Loans(id string) (Loans, *BookAPIError)
 }
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestBook_Validate(t *testing.T) {
+	book := Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, Rating: 3, PublishDate: "2008"}
+	assert.Nil(t, book.Validate())
+}
+
+func TestBook_Validate_WithFieldErrors(t *testing.T) {
+	book := Book{Author: "Robert Martin", Publisher: "A publisher name far too long", Status: CheckedIn, Rating: 7, PublishDate: "May 2008"}
+
+	err := book.Validate()
+	assert.NotNil(t, err)
+	assert.Equal(t, ValidationError, err.errorType)
+	assert.Equal(t, []FieldError{
+		{Field: "publish_date", Rule: "date", Message: "must be a valid date"},
+		{Field: "publisher", Rule: "length", Message: "the length must be between 1 and 20"},
+		{Field: "rating", Rule: "in", Message: "must be a whole rating from 0 to 3"},
+		{Field: "title", Rule: "required", Message: "cannot be blank"},
+	}, err.fields)
+	assert.Contains(t, err.Error(), "title: cannot be blank")
+}
+
+func TestStatus_CanBecome(t *testing.T) {
+	for _, transition := range []struct {
+		from, to Status
+		allowed  bool
+	}{
+		{CheckedIn, InTransit, true},
+		{InTransit, CheckedIn, true},
+		{CheckedOut, Lost, true},
+		{Lost, CheckedIn, true},
+		{Damaged, InRepair, true},
+		{InRepair, CheckedIn, true},
+		{Unknown, CheckedOut, true},
+		{Damaged, CheckedOut, false},
+		{Lost, InRepair, false},
+		{InTransit, Withdrawn, false},
+		{Withdrawn, CheckedIn, false},
+	} {
+		assert.Equal(t, transition.allowed, transition.from.CanBecome(transition.to), "%s to %s", transition.from, transition.to)
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	status, ok := parseStatus("InRepair")
+	assert.True(t, ok)
+	assert.Equal(t, InRepair, status)
+	assert.Equal(t, "InRepair", status.String())
+
+	_, ok = parseStatus("Stolen")
+	assert.False(t, ok)
+	assert.Equal(t, "Unknown", Status(42).String())
+}
+
+func TestStatus_JSON(t *testing.T) {
+	data, err := json.Marshal(Copy{Barcode: "B-0001", Status: InRepair})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"status":"InRepair"`)
+
+	for _, body := range []string{`{"status": "CheckedOut"}`, `{"status": 2}`} {
+		var book Book
+		assert.Nil(t, json.Unmarshal([]byte(body), &book), body)
+		assert.Equal(t, CheckedOut, book.Status, body)
+	}
+
+	for _, body := range []string{`{"status": "Stolen"}`, `{"status": 1.5}`, `{"status": true}`} {
+		var book Book
+		_, isTypeError := json.Unmarshal([]byte(body), &book).(*json.UnmarshalTypeError)
+		assert.True(t, isTypeError, body)
+	}
+}
+
+func TestStatus_BSON(t *testing.T) {
+	for _, doc := range []bson.M{{"status": int32(4)}, {"status": int64(4)}, {"status": 4.0}, {"status": "Lost"}} {
+		raw, _ := bson.Marshal(doc)
+		var book Book
+		assert.Nil(t, bson.Unmarshal(raw, &book), "%v", doc)
+		assert.Equal(t, Lost, book.Status, "%v", doc)
+	}
+
+	// the stored form stays the number, which sorts the statuses in order
+	stored, _ := toBSONDocument(Book{Status: Lost})
+	assert.Equal(t, int32(4), stored["status"])
+
+	raw, _ := bson.Marshal(bson.M{"status": "Stolen"})
+	assert.NotNil(t, bson.Unmarshal(raw, &Book{}))
+}
@@ -1,6 +1,12 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-ozzo/ozzo-validation"
+	"net/http"
+	"sort"
+)
 
 type OperationError int8
 
@@ -14,6 +20,18 @@ const (
 	DbConnectionError
 	NotFoundError
 	PersistError
+	MethodNotAllowed
+	MalformedBody
+	PayloadTooLarge
+	UnsupportedMediaType
+	PatchConflict
+	PreconditionFailed
+	OpenLoans
+	OnHold
+	IllegalTransition
+	NotAuthor
+	PreconditionRequired
+	ModerationConflict
 )
 
 func (oe OperationError) Name() string {
@@ -27,66 +45,325 @@ func (oe OperationError) Name() string {
 		"DbConnectionError",
 		"NotFoundError",
 		"PersistError",
+		"MethodNotAllowed",
+		"MalformedBody",
+		"PayloadTooLarge",
+		"UnsupportedMediaType",
+		"PatchConflict",
+		"PreconditionFailed",
+		"OpenLoans",
+		"OnHold",
+		"IllegalTransition",
+		"NotAuthor",
+		"PreconditionRequired",
+		"ModerationConflict",
 	}
 
 	// prevent panicking in case of
 	// `status` is out of range
-	if oe < AlreadyCheckedIn || oe > PersistError {
+	if oe < AlreadyCheckedOut || oe > ModerationConflict {
 		return "Unknown"
 	}
 
 	return names[oe]
 }
 
+// MarshalText returns the name of the error type, the form of the type in JSON
+func (oe OperationError) MarshalText() ([]byte, error) {
+	return []byte(oe.Name()), nil
+}
+
+// UnmarshalText reads the error type with the name
+func (oe *OperationError) UnmarshalText(text []byte) error {
+	for candidate := AlreadyCheckedOut; candidate <= ModerationConflict; candidate++ {
+		if candidate.Name() == string(text) {
+			*oe = candidate
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q is not an operation error", text)
+}
+
+// HTTPStatus returns the HTTP status of the responses to errors of the type
+func (oe OperationError) HTTPStatus() int {
+	switch oe {
+	case AlreadyCheckedOut, AlreadyCheckedIn, OnHold, ValidationError, ExistingRecord, MalformedBody:
+		return http.StatusBadRequest
+	case NotAuthor:
+		return http.StatusForbidden
+	case NotFoundError:
+		return http.StatusNotFound
+	case MethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case PayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case PatchConflict, OpenLoans, IllegalTransition, ModerationConflict:
+		return http.StatusConflict
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case PreconditionRequired:
+		return http.StatusPreconditionRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // BookAPIError is a extension of error containing specific errors within API.
 type BookAPIError struct {
 	errorType OperationError
 	msg       string
+	fields    []FieldError
+}
+
+// FieldError describes why the value of a field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// Problem RFC 7807 problem details of an error response.
+// Code is the OperationError, serialized as its name which is stable for clients to branch on
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      OperationError `json:"code"`
+	RequestID string         `json:"request_id,omitempty"`
+	Errors    []FieldError   `json:"errors,omitempty"`
 }
 
 // NewAlreadyCheckedOutError returns a domain already checked out error describing the error.
 func NewAlreadyCheckedOutError(id string) *BookAPIError {
-	return &BookAPIError{AlreadyCheckedOut, fmt.Sprintf("Book %s is already checked out", id)}
+	return &BookAPIError{AlreadyCheckedOut, fmt.Sprintf("Book %s is already checked out", id), nil}
 }
 
 // NewAlreadyCheckedInError returns a domain already checked in error describing the error.
 func NewAlreadyCheckedInError(id string) *BookAPIError {
-	return &BookAPIError{AlreadyCheckedIn, fmt.Sprintf("Book %s is already checked in", id)}
+	return &BookAPIError{AlreadyCheckedIn, fmt.Sprintf("Book %s is already checked in", id), nil}
+}
+
+// NewOnHoldError returns an error for a Book kept on the hold shelf for another Patron
+func NewOnHoldError(id string) *BookAPIError {
+	return &BookAPIError{OnHold, fmt.Sprintf("Book %s is on the hold shelf for another patron", id), nil}
+}
+
+// Kinds of the items named by the errors common to Books and copies
+const (
+	bookKind = "Book"
+	copyKind = "Copy"
+)
+
+// NewIllegalTransitionError returns an error for a Book or a Copy, the kind of item, that cannot go from its status
+// to the other one
+func NewIllegalTransitionError(kind string, id string, from Status, to Status) *BookAPIError {
+	return &BookAPIError{IllegalTransition, fmt.Sprintf("%s %s cannot go from %s to %s", kind, id, from, to), nil}
 }
 
 // NewDatabaseOperationError returns a database connection error describing the error.
 func NewDatabaseOperationError(text string) *BookAPIError {
-	return &BookAPIError{DbConnectionError, text}
+	return &BookAPIError{DbConnectionError, text, nil}
 }
 
 // NewNotFoundError  returns a not found error describing the error.
 func NewNotFoundError(text string) *BookAPIError {
-	return &BookAPIError{NotFoundError, fmt.Sprintf("Book %s does not exist", text)}
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Book %s does not exist", text), nil}
+}
+
+// NewPatronNotFoundError returns a not found error for a Patron that does not exist
+func NewPatronNotFoundError(id string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Patron %s does not exist", id), nil}
+}
+
+// NewLoanNotFoundError returns a not found error for a Loan that does not exist or is already closed
+func NewLoanNotFoundError(id string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Open loan %s does not exist", id), nil}
+}
+
+// NewCopyNotFoundError returns a not found error for a Copy that does not exist or is a copy of another Book
+func NewCopyNotFoundError(id string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Copy %s does not exist", id), nil}
+}
+
+// NewHoldNotFoundError returns a not found error for a Hold that does not exist or is no longer in line
+func NewHoldNotFoundError(id string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Hold %s does not exist", id), nil}
+}
+
+// NewRatingNotFoundError returns a not found error for a Rating that does not exist
+func NewRatingNotFoundError(id string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Rating %s does not exist", id), nil}
+}
+
+// NewReviewNotFoundError returns a not found error for a Review that does not exist or is a review of another Book
+func NewReviewNotFoundError(id string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("Review %s does not exist", id), nil}
 }
 
 // NewMissingEnvVariable returns an missing env variable error describing the error.
 func NewMissingEnvVariable(text string) *BookAPIError {
-	return &BookAPIError{MissingEnvVariable, text}
+	return &BookAPIError{MissingEnvVariable, text, nil}
 }
 
 // NewValidationError return a domain validation error describing the error
 func NewValidationError(text string) *BookAPIError {
-	return &BookAPIError{ValidationError, text}
+	return &BookAPIError{ValidationError, text, nil}
+}
+
+// NewInvalidFieldError returns a domain validation error describing which rule the field value breaks
+func NewInvalidFieldError(field string, rule string, text string) *BookAPIError {
+	return &BookAPIError{ValidationError, text, []FieldError{{Field: field, Rule: rule, Message: text}}}
+}
+
+// NewFieldValidationError returns a domain validation error keeping the error of each invalid field
+func NewFieldValidationError(errors validation.Errors) *BookAPIError {
+	fields := make([]FieldError, 0, len(errors))
+	for field, err := range errors {
+		fieldError := FieldError{Field: field, Message: err.Error()}
+		if named, ok := err.(ruleError); ok {
+			fieldError.Rule = named.rule
+		}
+		fields = append(fields, fieldError)
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+
+	return &BookAPIError{ValidationError, errors.Error(), fields}
+}
+
+// NewFilterSyntaxError returns a domain validation error describing where the filter expression is malformed
+func NewFilterSyntaxError(position int, text string) *BookAPIError {
+	return &BookAPIError{ValidationError, fmt.Sprintf("Invalid filter at position %d: %s", position, text), nil}
 }
 
 // NewUpdateError returns a domain update error describing the error
 func NewUpdateError(text string) *BookAPIError {
-	return &BookAPIError{UpdateError, text}
+	return &BookAPIError{UpdateError, text, nil}
 }
 
 // NewAlreadyExistsError return a domain already exists error
 func NewAlreadyExistsError() *BookAPIError {
-	return &BookAPIError{ExistingRecord, "Book already exists"}
+	return &BookAPIError{ExistingRecord, "Book already exists", nil}
+}
+
+// NewDuplicateKeyError returns a domain already exists error for a write refused by a unique index
+func NewDuplicateKeyError(text string) *BookAPIError {
+	return &BookAPIError{ExistingRecord, text, nil}
+}
+
+// NewPatronAlreadyExistsError return a domain already exists error for a Patron with the same email
+func NewPatronAlreadyExistsError(email string) *BookAPIError {
+	return &BookAPIError{ExistingRecord, fmt.Sprintf("Patron with email %s already exists", email), nil}
+}
+
+// NewHoldExistsError returns an error for a Patron already in line for the Book
+func NewHoldExistsError(patronID string, bookID string) *BookAPIError {
+	return &BookAPIError{ExistingRecord, fmt.Sprintf("Patron %s already has a hold on Book %s", patronID, bookID), nil}
+}
+
+// NewReviewExistsError returns an error for a Patron who already reviewed the Book
+func NewReviewExistsError(patronID string, bookID string) *BookAPIError {
+	return &BookAPIError{ExistingRecord, fmt.Sprintf("Patron %s already reviewed Book %s", patronID, bookID), nil}
+}
+
+// NewVoteExistsError returns an error for a Patron who already found the Review helpful
+func NewVoteExistsError(patronID string, reviewID string) *BookAPIError {
+	return &BookAPIError{ExistingRecord, fmt.Sprintf("Patron %s already voted for Review %s", patronID, reviewID), nil}
+}
+
+// NewModerationConflictError returns an error for a Review moderated or edited since staff read it, which they
+// would otherwise moderate without reading its text
+func NewModerationConflictError(id string) *BookAPIError {
+	return &BookAPIError{ModerationConflict, fmt.Sprintf("Review %s was edited or moderated since it was read", id), nil}
+}
+
+// NewCopyAlreadyExistsError returns an error for a Copy with the barcode of another one
+func NewCopyAlreadyExistsError(barcode string) *BookAPIError {
+	return &BookAPIError{ExistingRecord, fmt.Sprintf("Copy with barcode %s already exists", barcode), nil}
+}
+
+// NewOpenLoansError returns an error for a Patron that cannot be deleted while borrowing Books
+func NewOpenLoansError(id string) *BookAPIError {
+	return &BookAPIError{OpenLoans, fmt.Sprintf("Patron %s has Books checked out", id), nil}
+}
+
+// NewNotAuthorError returns an error for a Patron changing a Review someone else wrote
+func NewNotAuthorError(patronID string, reviewID string) *BookAPIError {
+	return &BookAPIError{NotAuthor, fmt.Sprintf("Patron %s is not the author of Review %s", patronID, reviewID), nil}
 }
 
 // NewPersistError returns a domain persistence error
 func NewPersistError(text string) *BookAPIError {
-	return &BookAPIError{PersistError, fmt.Sprintf("Error saving domain %s", text)}
+	return &BookAPIError{PersistError, fmt.Sprintf("Error saving domain %s", text), nil}
+}
+
+// NewRouteNotFoundError returns a not found error for a path no endpoint handles
+func NewRouteNotFoundError(path string) *BookAPIError {
+	return &BookAPIError{NotFoundError, fmt.Sprintf("No endpoint at %s", path), nil}
+}
+
+// NewMethodNotAllowedError returns an error for a method the endpoint does not handle
+func NewMethodNotAllowedError(method string, path string) *BookAPIError {
+	return &BookAPIError{MethodNotAllowed, fmt.Sprintf("%s is not allowed on %s", method, path), nil}
+}
+
+// NewMalformedBodyError returns an error for a request body that is not the expected JSON document
+func NewMalformedBodyError(text string) *BookAPIError {
+	return &BookAPIError{MalformedBody, text, nil}
+}
+
+// NewMalformedFieldError returns an error for a request body field that is unknown or of the wrong type
+func NewMalformedFieldError(field string, rule string, text string) *BookAPIError {
+	return &BookAPIError{MalformedBody, text, []FieldError{{Field: field, Rule: rule, Message: text}}}
+}
+
+// NewPayloadTooLargeError returns an error for a request body larger than the limit
+func NewPayloadTooLargeError(limit int64) *BookAPIError {
+	return &BookAPIError{PayloadTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", limit), nil}
+}
+
+// NewUnsupportedMediaTypeError returns an error for a request body of a media type the endpoint does not accept
+func NewUnsupportedMediaTypeError(contentType string, supported string) *BookAPIError {
+	return &BookAPIError{UnsupportedMediaType, fmt.Sprintf("Content-Type %q is not supported, use %s", contentType, supported), nil}
+}
+
+// NewPatchConflictError returns an error for a patch that cannot be applied to the current Book
+func NewPatchConflictError(text string) *BookAPIError {
+	return &BookAPIError{PatchConflict, text, nil}
+}
+
+// NewPreconditionFailedError returns an error for a Book or a Copy, the kind of item, that no longer matches the
+// condition of a write, i.e. modified by another request since the client read it
+func NewPreconditionFailedError(kind string, id string) *BookAPIError {
+	return &BookAPIError{PreconditionFailed, fmt.Sprintf("%s %s has been modified since it was read", kind, id), nil}
+}
+
+// NewPreconditionRequiredError returns an error for a write of a Book or a Copy without If-Match, which could
+// overwrite the changes made since the client read it
+func NewPreconditionRequiredError(id string) *BookAPIError {
+	return &BookAPIError{PreconditionRequired, fmt.Sprintf("writing %s requires If-Match with its ETag, or *", id), nil}
+}
+
+// Problem returns the problem details of the error in response to the request
+func (err *BookAPIError) Problem(r *http.Request) Problem {
+	status := err.errorType.HTTPStatus()
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.msg,
+		Instance:  r.URL.Path,
+		Code:      err.errorType,
+		RequestID: middleware.GetReqID(r.Context()),
+		Errors:    err.fields,
+	}
 }
 
 // Implicit implement of Error interface
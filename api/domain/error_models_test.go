@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestOperationError_Name(t *testing.T) {
+	assert.Equal(t, "AlreadyCheckedOut", AlreadyCheckedOut.Name())
+	assert.Equal(t, "ExistingRecord", ExistingRecord.Name())
+	assert.Equal(t, "MethodNotAllowed", MethodNotAllowed.Name())
+	assert.Equal(t, "Unknown", OperationError(-1).Name())
+	assert.Equal(t, "Unknown", OperationError(100).Name())
+}
+
+func TestOperationError_JSON(t *testing.T) {
+	data, err := json.Marshal(Problem{Status: http.StatusConflict, Code: IllegalTransition})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"code":"IllegalTransition"`)
+
+	var problem Problem
+	assert.Nil(t, json.Unmarshal(data, &problem))
+	assert.Equal(t, IllegalTransition, problem.Code)
+	assert.NotNil(t, json.Unmarshal([]byte(`{"code": "Teapot"}`), &problem))
+}
+
+func TestOperationError_HTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, AlreadyCheckedOut.HTTPStatus())
+	assert.Equal(t, http.StatusBadRequest, ValidationError.HTTPStatus())
+	assert.Equal(t, http.StatusBadRequest, ExistingRecord.HTTPStatus())
+	assert.Equal(t, http.StatusNotFound, NotFoundError.HTTPStatus())
+	assert.Equal(t, http.StatusConflict, ModerationConflict.HTTPStatus())
+	assert.Equal(t, http.StatusMethodNotAllowed, MethodNotAllowed.HTTPStatus())
+	assert.Equal(t, http.StatusInternalServerError, PersistError.HTTPStatus())
+	assert.Equal(t, http.StatusInternalServerError, DbConnectionError.HTTPStatus())
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// fileCollection keeps the documents in memory and writes them all to a MongoDB extended JSON file after every change
+type fileCollection struct {
+	mu    sync.Mutex
+	path  string
+	store *memoryCollection
+}
+
+// fileDocuments root of the collection file, extended JSON documents must be objects
+type fileDocuments struct {
+	Documents []bson.M `bson:"documents"`
+}
+
+func (c *fileCollection) find(filter Filter, findOptions FindOptions, results interface{}) *BookAPIError {
+	return c.store.find(filter, findOptions, results)
+}
+
+func (c *fileCollection) count(filter Filter) (int64, *BookAPIError) {
+	return c.store.count(filter)
+}
+
+func (c *fileCollection) insert(entity interface{}) (string, *BookAPIError) {
+	var id string
+	err := c.write(func() (bool, *BookAPIError) {
+		var insertErr *BookAPIError
+		id, insertErr = c.store.insert(entity)
+		return insertErr == nil, insertErr
+	})
+
+	return id, err
+}
+
+func (c *fileCollection) update(id string, condition Filter, fields Fields) (bool, *BookAPIError) {
+	var updated bool
+	err := c.write(func() (bool, *BookAPIError) {
+		var updateErr *BookAPIError
+		updated, updateErr = c.store.update(id, condition, fields)
+		return updated, updateErr
+	})
+
+	return updated, err
+}
+
+func (c *fileCollection) delete(id string, condition Filter) (bool, *BookAPIError) {
+	var deleted bool
+	err := c.write(func() (bool, *BookAPIError) {
+		var deleteErr *BookAPIError
+		deleted, deleteErr = c.store.delete(id, condition)
+		return deleted, deleteErr
+	})
+
+	return deleted, err
+}
+
+// write applies the change to the in-memory store and flushes it to disk when it changed anything.
+// The change is rolled back when the file cannot be written.
+func (c *fileCollection) write(change func() (bool, *BookAPIError)) *BookAPIError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous := c.store.snapshot()
+	changed, err := change()
+	if err != nil || !changed {
+		return err
+	}
+
+	data, marshalErr := bson.MarshalExtJSON(fileDocuments{Documents: c.store.snapshot()}, true, false)
+	if marshalErr == nil {
+		marshalErr = writeFileAtomically(c.path, data)
+	}
+
+	if marshalErr != nil {
+		c.store.restore(previous)
+		return NewPersistError(marshalErr.Error())
+	}
+
+	return nil
+}
+
+// newFileCollection Initializes a collection persisted to the extended JSON file at path,
+// loading any previously saved documents, no two of which may have the same values of a unique key
+// It returns an API Error Response if failed
+func newFileCollection(path string, unique ...[]string) (collection, *BookAPIError) {
+	stored := fileDocuments{}
+
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, NewDatabaseOperationError(err.Error())
+	case len(data) > 0:
+		if err := bson.UnmarshalExtJSON(data, true, &stored); err != nil {
+			return nil, NewDatabaseOperationError(err.Error())
+		}
+	}
+
+	return &fileCollection{path: path, store: newMemoryCollection(stored.Documents, unique...)}, nil
+}
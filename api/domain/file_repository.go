@@ -0,0 +1,188 @@
+package domain
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileRepo keeps the Books in memory and writes the whole collection to a JSON file after every change
+type fileRepo struct {
+	mu    sync.Mutex
+	path  string
+	store *memoryRepo
+}
+
+// FindAll Queries the stored Books with optional filters on custom attributes and find options
+// It returns a list of paginated Books or an API Error Response
+func (r *fileRepo) FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError) {
+	return r.store.FindAll(filter, findOptions)
+}
+
+// Count Counts the stored Books matching the filter
+// It returns the number of Books or an API Error Response
+func (r *fileRepo) Count(filter Filter) (int64, *BookAPIError) {
+	return r.store.Count(filter)
+}
+
+// Search Queries the search index of the stored Books for the Books matching any term of the text
+// It returns the page of Books selected by the find options, most relevant first, with the number of matching Books
+func (r *fileRepo) Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError) {
+	return r.store.Search(text, findOptions)
+}
+
+// FindOne Queries the stored Books for a specific Book
+// It returns one Book or an API Error Response
+func (r *fileRepo) FindOne(id string) (Book, *BookAPIError) {
+	return r.store.FindOne(id)
+}
+
+// Delete Hard deletes the Book with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *fileRepo) Delete(id string, condition Filter) *BookAPIError {
+	return r.write(func() *BookAPIError {
+		return r.store.Delete(id, condition)
+	})
+}
+
+// Update sets the fields of the Book with the specified ID when it matches the condition, incrementing its version
+// It returns an API Error Response if failed
+func (r *fileRepo) Update(id string, condition Filter, updatedFields Fields) *BookAPIError {
+	return r.write(func() *BookAPIError {
+		return r.store.Update(id, condition, updatedFields)
+	})
+}
+
+// IsExistingEntry Checks the store if it has a Book with the same unique composite fields
+// Author, Title, Publish_Date
+// It returns a boolean
+func (r *fileRepo) IsExistingEntry(book Book) bool {
+	return r.store.IsExistingEntry(book)
+}
+
+// Save Saves the Book Payload, generating an ID when it has none
+// It returns the persisted Book ID or an API Error Response if failed
+func (r *fileRepo) Save(book Book) (string, *BookAPIError) {
+	var id string
+	err := r.write(func() *BookAPIError {
+		var saveErr *BookAPIError
+		id, saveErr = r.store.Save(book)
+		return saveErr
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// write applies the change to the in-memory store and flushes it to disk.
+// The change is rolled back when the file cannot be written.
+func (r *fileRepo) write(change func() *BookAPIError) *BookAPIError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.store.snapshot()
+	if err := change(); err != nil {
+		return err
+	}
+
+	if err := r.flush(); err != nil {
+		r.store.restore(previous)
+		return NewPersistError(err.Error())
+	}
+
+	return nil
+}
+
+// fileBook Book as written to the data file, keeping the scale of its rating the API leaves out
+type fileBook struct {
+	Book
+	RatingScale string `json:"rating_scale,omitempty"`
+}
+
+// flush durably replaces the data file with the stored Books
+func (r *fileRepo) flush() error {
+	books := r.store.snapshot()
+	stored := make([]fileBook, len(books))
+	for i, book := range books {
+		stored[i] = fileBook{Book: book, RatingScale: book.RatingScale}
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(r.path, data)
+}
+
+// writeFileAtomically durably replaces the file at path: the data is written to a temporary file
+// which is synced and renamed over the previous version
+func writeFileAtomically(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir flushes the directory entry so the rename survives a crash
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
+
+// NewFileRepository Initializes a repository persisted to the JSON file at path,
+// loading any previously saved Books
+// It returns an API Error Response if failed
+func NewFileRepository(path string) (Repository, *BookAPIError) {
+	books := Books{}
+
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, NewDatabaseOperationError(err.Error())
+	case len(data) > 0:
+		stored := []fileBook{}
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return nil, NewDatabaseOperationError(err.Error())
+		}
+
+		for _, book := range stored {
+			book.Book.RatingScale = book.RatingScale
+			books = append(books, book.Book)
+		}
+	}
+
+	return &fileRepo{path: path, store: newMemoryRepo(books)}, nil
+}
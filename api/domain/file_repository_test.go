@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func tempStoragePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "redeam")
+	if err != nil {
+		t.Fatalf(`Unable to create temp dir: %s`, err.Error())
+	}
+
+	return filepath.Join(dir, "books.json"), func() { _ = os.RemoveAll(dir) }
+}
+
+func TestFileRepo_PersistsAcrossRestarts(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	repo, err := NewFileRepository(path)
+	assert.Nil(t, err)
+
+	id, saveErr := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Status: CheckedIn, PublishDate: "2008"})
+	assert.Nil(t, saveErr)
+	assert.Nil(t, repo.Update(id, Filter{}, Fields{"status": CheckedOut, "rating": 4, "rating_scale": "1-5/1"}))
+
+	reopened, err := NewFileRepository(path)
+	assert.Nil(t, err)
+
+	book, findErr := reopened.FindOne(id)
+	assert.Nil(t, findErr)
+	assert.Equal(t, "Clean Code", book.Title)
+	assert.Equal(t, CheckedOut, book.Status)
+	assert.Equal(t, "1-5/1", book.RatingScale, "the scale of the rating is kept though the API leaves it out")
+	assert.True(t, reopened.IsExistingEntry(Book{Author: "Robert Martin", Title: "Clean Code", PublishDate: "2008"}))
+
+	assert.Nil(t, reopened.Delete(id, Filter{}))
+	reopened, _ = NewFileRepository(path)
+	books, _ := reopened.FindAll(Filter{}, FindOptions{})
+	assert.Len(t, books, 0)
+}
+
+func TestFileRepo_MigratesNumericStatuses(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	legacy := `[{"_id": "5ca7c76f9287bd3832d96f15", "author": "Robert Martin", "title": "Clean Code", "status": 2, "publish_date": "2008"}]`
+	_ = ioutil.WriteFile(path, []byte(legacy), 0600)
+
+	repo, err := NewFileRepository(path)
+	assert.Nil(t, err)
+
+	book, _ := repo.FindOne("5ca7c76f9287bd3832d96f15")
+	assert.Equal(t, CheckedOut, book.Status)
+
+	// the next write stores the statuses by name
+	assert.Nil(t, repo.Update("5ca7c76f9287bd3832d96f15", Filter{}, Fields{"rating": 3}))
+	data, _ := ioutil.ReadFile(path)
+	assert.Contains(t, string(data), `"status": "CheckedOut"`)
+}
+
+func TestFileRepo_RollsBackOnWriteFailure(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	repo, _ := NewFileRepository(filepath.Join(path, "missing", "books.json"))
+	_, err := repo.Save(Book{Author: "thg090020"})
+	assert.NotNil(t, err)
+
+	books, _ := repo.FindAll(Filter{}, FindOptions{})
+	assert.Len(t, books, 0)
+}
+
+func TestFileRepo_NewFileRepositoryWithCorruptFile(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	_ = ioutil.WriteFile(path, []byte("{not json"), 0600)
+	_, err := NewFileRepository(path)
+	assert.NotNil(t, err)
+}
+
+func TestRepo_NewRepositoryWithFileEngineMissingPath(t *testing.T) {
+	_ = os.Setenv("storage_engine", "file")
+	defer os.Unsetenv("storage_engine")
+
+	_, err := NewRepository()
+	assert.NotNil(t, err)
+	assert.Equal(t, MissingEnvVariable, err.errorType)
+}
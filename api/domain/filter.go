@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NewQueryFilter returns a leaf Filter matching the single condition
+func NewQueryFilter(field string, operator QueryOperator, value interface{}) Filter {
+	return Filter{Query: &Query{Field: field, Operator: operator, Value: value}}
+}
+
+// AllOf returns a Filter matching Books that match every filter
+func AllOf(filters ...Filter) Filter {
+	return combine(And, filters)
+}
+
+// AnyOf returns a Filter matching Books that match at least one filter
+func AnyOf(filters ...Filter) Filter {
+	return combine(Or, filters)
+}
+
+// Negate returns a Filter matching Books the filter does not match
+func Negate(filter Filter) Filter {
+	return Filter{Operator: Not, Children: []Filter{filter}}
+}
+
+// IsEmpty reports whether the Filter has no condition and so matches every Book
+func (f Filter) IsEmpty() bool {
+	return f.Query == nil && len(f.Children) == 0
+}
+
+// combine drops empty filters, flattens nested filters of the same operator
+// and avoids wrapping a single remaining filter
+func combine(operator LogicalOperator, filters []Filter) Filter {
+	children := make([]Filter, 0, len(filters))
+	for _, filter := range filters {
+		switch {
+		case filter.IsEmpty():
+		case filter.Query == nil && filter.Operator == operator:
+			children = append(children, filter.Children...)
+		default:
+			children = append(children, filter)
+		}
+	}
+
+	switch len(children) {
+	case 0:
+		return Filter{}
+	case 1:
+		return children[0]
+	}
+
+	return Filter{Operator: operator, Children: children}
+}
+
+var objectIDType = reflect.TypeOf(primitive.ObjectID{})
+
+var statusType = reflect.TypeOf(Unknown)
+
+// bookFieldTypes Go types of the Book fields keyed by their bson name. A map, i.e. rating_distribution, has no value
+// a filter could compare it with and a field the responses leave out, i.e. rating_scale, is no filter either
+var bookFieldTypes = func() map[string]reflect.Type {
+	types := map[string]reflect.Type{}
+	bookType := reflect.TypeOf(Book{})
+	for i := 0; i < bookType.NumField(); i++ {
+		field := bookType.Field(i)
+		name := strings.Split(field.Tag.Get("bson"), ",")[0]
+		hidden := field.Tag.Get("json") == "-"
+		if name != "" && name != "-" && !hidden && field.Type.Kind() != reflect.Map {
+			types[name] = field.Type
+		}
+	}
+
+	return types
+}()
+
+// IsBookField reports whether name is the bson name of a Book field
+func IsBookField(name string) bool {
+	_, ok := bookFieldTypes[name]
+	return ok
+}
+
+// IsTextField reports whether name is the bson name of a string Book field
+func IsTextField(name string) bool {
+	fieldType, ok := bookFieldTypes[name]
+	return ok && fieldType.Kind() == reflect.String
+}
+
+// ParseFieldValue converts the text into the Go type of the Book field, a status is named or numbered
+// It returns a Validation Error if the field is unknown or the text is not a valid value
+func ParseFieldValue(field string, text string) (interface{}, *BookAPIError) {
+	fieldType, ok := bookFieldTypes[field]
+	if !ok {
+		return nil, NewValidationError(fmt.Sprintf("unknown field %s", field))
+	}
+
+	switch {
+	case fieldType == objectIDType:
+		id, err := primitive.ObjectIDFromHex(text)
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("%s must be a valid id", field))
+		}
+		return id, nil
+
+	case fieldType == statusType:
+		var status Status
+		if err := status.UnmarshalText([]byte(text)); err != nil {
+			return nil, NewValidationError(fmt.Sprintf("%s must be a status, i.e. CheckedOut", field))
+		}
+		return status, nil
+
+	case fieldType.Kind() >= reflect.Int && fieldType.Kind() <= reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("%s must be a number", field))
+		}
+		return value, nil
+
+	case fieldType.Kind() == reflect.Float64:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, NewValidationError(fmt.Sprintf("%s must be a number", field))
+		}
+		return value, nil
+
+	case fieldType.Kind() == reflect.String:
+		return text, nil
+	}
+
+	return nil, NewValidationError(fmt.Sprintf("%s cannot be filtered", field))
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// fuzzyThreshold minimum similarity of a Book field to a fuzzy condition for the Book to match
+	fuzzyThreshold = 0.7
+	// suggestionThreshold minimum similarity of a field value to be suggested when nothing matches
+	suggestionThreshold = 0.4
+)
+
+// fuzzyFields Book fields, by bson name, that may be matched approximately
+var fuzzyFields = map[string]bool{
+	"author": true,
+	"title":  true,
+}
+
+// FuzzyResult the page of Books similar to fuzzy conditions
+type FuzzyResult struct {
+	Hits  SearchHits
+	Total int64
+	// DidYouMean closest value of each fuzzy field, set when no Book matches
+	DidYouMean map[string]string
+}
+
+// Similarity returns how alike the texts are, from 0 to 1, ignoring case and diacritics.
+// Texts are compared as a whole and word by word, so that a single word matches a longer field,
+// each comparison taking the best of the edit distance and the trigram similarity
+func Similarity(text string, value string) float64 {
+	textTerms, valueTerms := Tokenize(text), Tokenize(value)
+	if len(textTerms) == 0 || len(valueTerms) == 0 {
+		return 0
+	}
+
+	whole := termSimilarity(strings.Join(textTerms, " "), strings.Join(valueTerms, " "))
+
+	wordByWord := 0.0
+	for _, textTerm := range textTerms {
+		best := 0.0
+		for _, valueTerm := range valueTerms {
+			if similarity := termSimilarity(textTerm, valueTerm); similarity > best {
+				best = similarity
+			}
+		}
+		wordByWord += best
+	}
+	wordByWord /= float64(len(textTerms))
+
+	if wordByWord > whole {
+		return wordByWord
+	}
+
+	return whole
+}
+
+// fuzzyMatch scores the Books against every condition, keeping the Books similar enough to all of them
+// ordered by decreasing score. Books of equal score keep their order.
+// When no Book matches it suggests the closest value of each field
+func fuzzyMatch(books Books, conditions []Query) (SearchHits, map[string]string) {
+	hits := SearchHits{}
+	closest := map[string]string{}
+	closestScore := map[string]float64{}
+	for _, book := range books {
+		fields := searchFields(book)
+		score, matched := 0.0, true
+		for _, condition := range conditions {
+			text, _ := condition.Value.(string)
+			value := fields[condition.Field]
+			similarity := Similarity(text, value)
+			if similarity > closestScore[condition.Field] {
+				closest[condition.Field], closestScore[condition.Field] = value, similarity
+			}
+
+			score += similarity
+			matched = matched && similarity >= fuzzyThreshold
+		}
+
+		if matched {
+			hits = append(hits, SearchHit{Book: book, Score: score / float64(len(conditions))})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	if len(hits) > 0 {
+		return hits, nil
+	}
+
+	suggestions := map[string]string{}
+	for field, value := range closest {
+		if closestScore[field] >= suggestionThreshold {
+			suggestions[field] = value
+		}
+	}
+
+	return hits, suggestions
+}
+
+// termSimilarity returns the best of the edit distance and trigram similarities of the folded texts
+func termSimilarity(a string, b string) float64 {
+	edit := editSimilarity(a, b)
+	if trigram := trigramSimilarity(a, b); trigram > edit {
+		return trigram
+	}
+
+	return edit
+}
+
+// editSimilarity returns 1 minus the Levenshtein distance of the texts relative to the longest one
+func editSimilarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the minimum number of single rune insertions, deletions or substitutions turning a into b
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// trigramSimilarity returns the Jaccard index of the sets of three rune sequences of the padded texts
+func trigramSimilarity(a string, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for trigram := range ta {
+		if tb[trigram] {
+			shared++
+		}
+	}
+
+	union := len(ta) + len(tb) - shared
+	if union == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(union)
+}
+
+func trigrams(text string) map[string]bool {
+	runes := []rune("  " + text + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+
+	return set
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+
+	return min
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("robert MARTIN", "Robert Martin"))
+	assert.Equal(t, 1.0, Similarity("Garcia", "Gabriel García Márquez"))
+	assert.True(t, Similarity("Robert Martn", "Robert Martin") > fuzzyThreshold)
+	assert.True(t, Similarity("Alchemyst", "The Alchemist") > fuzzyThreshold)
+	assert.True(t, Similarity("Paulo Coelho", "Robert Martin") < suggestionThreshold)
+	assert.Equal(t, 0.0, Similarity("", "Robert Martin"))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein([]rune("martin"), []rune("martin")))
+	assert.Equal(t, 1, levenshtein([]rune("martn"), []rune("martin")))
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+	assert.Equal(t, 6, levenshtein([]rune(""), []rune("coelho")))
+}
+
+func TestService_FindSimilar(t *testing.T) {
+	bookService := NewService(seedMemoryRepository(t), nil, nil, nil, nil, nil, DefaultLoanPolicy)
+
+	result, err := bookService.FindSimilar(Filter{}, []Query{{Field: "author", Operator: Equals, Value: "Robert Martn"}}, FindOptions{Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Len(t, result.Hits, 1)
+	assert.Equal(t, "Robert Martin", result.Hits[0].Author)
+	assert.True(t, result.Hits[0].Score > fuzzyThreshold)
+	assert.Nil(t, result.DidYouMean)
+
+	result, err = bookService.FindSimilar(NewQueryFilter("rating", Equals, float64(3)), []Query{{Field: "title", Operator: Equals, Value: "clean cod"}}, FindOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, result.Hits, 1)
+	assert.Equal(t, "Clean Code", result.Hits[0].Title)
+
+	result, err = bookService.FindSimilar(Filter{}, []Query{{Field: "author", Operator: Equals, Value: "Rbrt Mrtn"}}, FindOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, result.Hits)
+	assert.Equal(t, map[string]string{"author": "Robert Martin"}, result.DidYouMean)
+}
+
+func TestQueryBuilder_GetQueryParams_WithFuzzy(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest("author=Robert+Martn&rating>=1&fuzzy=true&size=5"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Query{{Field: "author", Operator: Equals, Value: "Robert Martn"}}, request.Fuzzy)
+	assert.Equal(t, NewQueryFilter("rating", GreaterThanOrEqual, float64(1)), request.Filter)
+	assert.Equal(t, int64(5), request.FindOptions.Limit)
+
+	for _, rawQuery := range []string{"fuzzy=yes&author=Robert", "fuzzy=true&rating=1", "fuzzy=true&author=Robert&after=abc"} {
+		_, err := builder.GetQueryParams(newQueryRequest(rawQuery))
+		assert.NotNil(t, err, rawQuery)
+		assert.Equal(t, ValidationError, err.errorType, rawQuery)
+	}
+}
+
+func TestController_GetAll_WithFuzzy(t *testing.T) {
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().FindSimilar(Filter{}, []Query{{Field: "author", Operator: Equals, Value: "Rbrt Mrtn"}}, gomock.Any()).
+		Return(FuzzyResult{Hits: SearchHits{}, DidYouMean: map[string]string{"author": "Robert Martin"}}, nil)
+	bookController := NewController(bookService)
+
+	wr := httptest.NewRecorder()
+	testURL, _ := url.Parse("http://localhost:8080/books?author=Rbrt+Mrtn&fuzzy=true")
+	r := &http.Request{URL: testURL}
+	bookController.GetAll(wr, r)
+
+	assert.Equal(t, http.StatusOK, wr.Code, `Invalid response... Expected 200 but got %d`, wr.Code)
+	assert.Contains(t, wr.Body.String(), `"did_you_mean":{"author":"Robert Martin"}`)
+	assert.Equal(t, "0", wr.Header().Get("X-Total-Count"))
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/temesxgn/redeam/api/utils"
+	"net/http"
+)
+
+// PlaceHold handles REST API POST '/{id}/holds' Endpoint, the Patron getting in line for the Book
+func (c *Controller) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	var request HoldRequest
+	if err := decodeJSON(r, &request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	hold, err := c.service.PlaceHold(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(hold)
+	responseBuilder.OK(w, data)
+}
+
+// Holds handles REST API GET '/{id}/holds' Endpoint, the line of the Book, next Patron first
+func (c *Controller) Holds(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	holds, err := c.service.Holds(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(holds)
+	responseBuilder.OK(w, data)
+}
+
+// Hold handles REST API GET '/{id}/holds/{holdID}' Endpoint, the Hold with its position in line
+func (c *Controller) Hold(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	holdID := chi.URLParam(r, "holdID")
+
+	hold, err := c.service.Hold(id, holdID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(hold)
+	responseBuilder.OK(w, data)
+}
+
+// CancelHold handles REST API DELETE '/{id}/holds/{holdID}' Endpoint, the Patron leaving the line
+func (c *Controller) CancelHold(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	holdID := chi.URLParam(r, "holdID")
+
+	if err := c.service.CancelHold(id, holdID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(nil))
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newHoldServer(service Service) *httptest.Server {
+	ctrl := NewController(service)
+	router := chi.NewRouter()
+	router.Get("/books/{id}/holds", ctrl.Holds)
+	router.Post("/books/{id}/holds", ctrl.PlaceHold)
+	router.Get("/books/{id}/holds/{holdID}", ctrl.Hold)
+	router.Delete("/books/{id}/holds/{holdID}", ctrl.CancelHold)
+
+	return httptest.NewServer(router)
+}
+
+func TestController_PlaceHold(t *testing.T) {
+	id, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	hold := Hold{ID: primitive.NewObjectID(), BookID: id, PatronID: patronID, Position: 2}
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().PlaceHold(id, HoldRequest{PatronID: patronID}).Return(hold, nil)
+
+	server := newHoldServer(bookService)
+	defer server.Close()
+
+	res, _ := http.Post(fmt.Sprintf("%s/books/%s/holds", server.URL, id), ContentType, strings.NewReader(`{"patron_id": "`+patronID+`"}`))
+	defer closeBody(res.Body)
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, float64(2), body["position"])
+	assert.Equal(t, hold.ID.Hex(), body["_id"])
+}
+
+func TestController_HoldAndCancelHold(t *testing.T) {
+	id, holdID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().Hold(id, holdID).Return(Hold{}, NewHoldNotFoundError(holdID))
+	bookService.EXPECT().CancelHold(id, holdID).Return(nil)
+
+	server := newHoldServer(bookService)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/books/%s/holds/%s", server.URL, id, holdID))
+	defer closeBody(res.Body)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/books/%s/holds/%s", server.URL, id, holdID), nil)
+	cancelled, _ := http.DefaultClient.Do(req)
+	defer closeBody(cancelled.Body)
+	assert.Equal(t, http.StatusOK, cancelled.StatusCode)
+}
+
+func TestController_CheckOut_WithOnHold(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	bookService := NewMockService(gomock.NewController(t))
+	bookService.EXPECT().CheckOut(id, AnyVersion, gomock.Any()).Return(Loan{}, NewOnHoldError(id))
+
+	router := chi.NewRouter()
+	router.Put("/books/checkout/{id}", NewController(bookService).CheckOut)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/books/checkout/%s", server.URL, id), strings.NewReader(`{"patron_id": "1"}`))
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("If-Match", "*")
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var problem Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Equal(t, OnHold, problem.Code)
+}
@@ -0,0 +1,63 @@
+package domain
+
+type holdRepo struct {
+	collection collection
+}
+
+// FindAll Queries the holds collection with optional filters and find options
+// It returns a list of Holds or an API Error Response
+func (r *holdRepo) FindAll(filter Filter, findOptions FindOptions) (Holds, *BookAPIError) {
+	holds := Holds{}
+	if err := r.collection.find(filter, findOptions, &holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// Update sets the fields of the Hold with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *holdRepo) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	updated, err := r.collection.update(id, condition, fields)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return NewHoldNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Delete removes the Hold with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *holdRepo) Delete(id string, condition Filter) *BookAPIError {
+	deleted, err := r.collection.delete(id, condition)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return NewHoldNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Save Saves the Hold Payload, generating an ID when it has none
+// It returns the persisted Hold ID or an API Error Response if failed
+func (r *holdRepo) Save(hold Hold) (string, *BookAPIError) {
+	return r.collection.insert(hold)
+}
+
+// NewHoldRepository Initializes the holds repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewHoldRepository() (HoldRepository, *BookAPIError) {
+	collection, err := newCollection("holds")
+	if err != nil {
+		return nil, err
+	}
+
+	return &holdRepo{collection: collection}, nil
+}
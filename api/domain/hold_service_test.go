@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// testHoldQueue exercises the line of a Book checked out over the holds stored in the collection
+func testHoldQueue(t *testing.T, holds collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	borrower, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	first, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+	second, _ := patrons.Save(Patron{Name: "Grace Hopper", Email: "grace@example.com"})
+
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	bookService := &service{repository: repo, patrons: patrons, loans: &loanRepo{collection: newMemoryCollection(nil)},
+		holds: &holdRepo{collection: holds}, copies: &copyRepo{collection: newMemoryCollection(nil)}, policy: DefaultLoanPolicy, now: func() time.Time { return now }}
+
+	_, err := bookService.PlaceHold(id, HoldRequest{PatronID: first})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	_, err = bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: borrower})
+	assert.Nil(t, err)
+
+	_, err = bookService.PlaceHold(id, HoldRequest{PatronID: borrower})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	firstHold, err := bookService.PlaceHold(id, HoldRequest{PatronID: first})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, firstHold.Position)
+	assert.Nil(t, firstHold.ReadyAt)
+
+	now = now.Add(time.Minute)
+	secondHold, err := bookService.PlaceHold(id, HoldRequest{PatronID: second})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, secondHold.Position)
+
+	_, err = bookService.PlaceHold(id, HoldRequest{PatronID: second})
+	assert.Equal(t, ExistingRecord, err.errorType)
+
+	// the Book checked in is kept on the hold shelf for the first Patron in line
+	now = now.Add(day)
+	assert.Nil(t, bookService.CheckIn(id, AnyVersion))
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, OnHoldShelf, book.Status)
+
+	firstHold, err = bookService.Hold(id, firstHold.ID.Hex())
+	assert.Nil(t, err)
+	if assert.NotNil(t, firstHold.ExpiresAt) {
+		assert.True(t, now.Add(DefaultLoanPolicy.PickupWindow).Equal(*firstHold.ExpiresAt))
+	}
+
+	_, err = bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: second})
+	assert.Equal(t, OnHold, err.errorType)
+
+	// the first Patron leaving the line, the Book goes to the second one
+	assert.Nil(t, bookService.CancelHold(id, firstHold.ID.Hex()))
+	assert.Equal(t, NotFoundError, bookService.CancelHold(id, firstHold.ID.Hex()).errorType)
+
+	line, err := bookService.Holds(id)
+	assert.Nil(t, err)
+	if assert.Len(t, line, 1) {
+		assert.Equal(t, secondHold.ID, line[0].ID)
+		assert.Equal(t, 1, line[0].Position)
+		assert.NotNil(t, line[0].ReadyAt)
+	}
+
+	// nobody picking the Book up in time, it goes back on the shelf
+	expired, err := bookService.ExpireHolds()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, expired)
+
+	now = now.Add(DefaultLoanPolicy.PickupWindow + time.Minute)
+	expired, err = bookService.ExpireHolds()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, expired)
+
+	book, _ = repo.FindOne(id)
+	assert.Equal(t, CheckedIn, book.Status)
+	line, _ = bookService.Holds(id)
+	assert.Empty(t, line)
+
+	// the Patron the Book is reserved for checks it out, leaving the line
+	_, err = bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: borrower})
+	assert.Nil(t, err)
+	_, err = bookService.PlaceHold(id, HoldRequest{PatronID: first})
+	assert.Nil(t, err)
+	assert.Nil(t, bookService.CheckIn(id, AnyVersion))
+
+	_, err = bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: first})
+	assert.Nil(t, err)
+	book, _ = repo.FindOne(id)
+	assert.Equal(t, CheckedOut, book.Status)
+	line, _ = bookService.Holds(id)
+	assert.Empty(t, line)
+}
+
+func TestService_Holds(t *testing.T) {
+	testHoldQueue(t, newMemoryCollection(nil))
+}
+
+func TestService_Holds_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testHoldQueue(t, newSQLiteCollection(t, path, "holds"))
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/temesxgn/redeam/api/utils"
+	"net/http"
+	"strconv"
+)
+
+// A LoanController - action handler for Loan API
+type LoanController struct {
+	service LoanService
+}
+
+// Overdue handles REST API Get '/loans/overdue' Endpoint, the open loans past their due date, most overdue first
+func (c *LoanController) Overdue(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+
+	overdue, err := c.service.Overdue()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(overdue)))
+	data, _ := json.Marshal(overdue)
+	responseBuilder.OK(w, data)
+}
+
+// NewLoanController Creates LoanController instance
+func NewLoanController(service LoanService) *LoanController {
+	return &LoanController{service: service}
+}
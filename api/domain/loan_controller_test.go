@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoanController_Overdue(t *testing.T) {
+	overdue := OverdueLoans{{Loan: Loan{ID: primitive.NewObjectID(), BookID: "1", PatronID: "2"}, DaysOverdue: 3}}
+
+	loanService := NewMockLoanService(gomock.NewController(t))
+	loanService.EXPECT().Overdue().Return(overdue, nil)
+	loanController := NewLoanController(loanService)
+	router := chi.NewRouter()
+	router.Get("/loans/overdue", loanController.Overdue)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(server.URL + "/loans/overdue")
+	defer closeBody(res.Body)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "1", res.Header.Get("X-Total-Count"))
+
+	var body []map[string]interface{}
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	if assert.Len(t, body, 1) {
+		assert.Equal(t, float64(3), body[0]["days_overdue"])
+		assert.Equal(t, "1", body[0]["book_id"])
+	}
+}
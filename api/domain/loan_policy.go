@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// LoanPolicy terms of the loans: how long a Patron keeps a Book, how long a Book on hold waits for its Patron
+// and how often overdue loans and expired holds are looked for
+type LoanPolicy struct {
+	// Period loan period of a checkout asking for none
+	Period time.Duration
+	// MaxPeriod longest loan period a checkout may ask for
+	MaxPeriod time.Duration
+	// PickupWindow time a Book checked in stays on the hold shelf for the next Patron in line
+	PickupWindow time.Duration
+	// SweepInterval time between two sweeps marking the overdue loans and expiring the holds, no sweep when 0
+	SweepInterval time.Duration
+}
+
+// DefaultLoanPolicy three week loans of at most three months, holds picked up within a week, swept hourly
+var DefaultLoanPolicy = LoanPolicy{Period: 21 * day, MaxPeriod: 90 * day, PickupWindow: 7 * day, SweepInterval: time.Hour}
+
+// NewLoanPolicy returns the loan policy configured by the loan_period_days, max_loan_period_days,
+// hold_pickup_days and overdue_sweep_interval environment variables, defaulting to DefaultLoanPolicy
+// It returns an API Error Response if a variable is invalid
+func NewLoanPolicy() (LoanPolicy, *BookAPIError) {
+	policy := DefaultLoanPolicy
+
+	for _, setting := range []struct {
+		name   string
+		period *time.Duration
+	}{
+		{"loan_period_days", &policy.Period},
+		{"max_loan_period_days", &policy.MaxPeriod},
+		{"hold_pickup_days", &policy.PickupWindow},
+	} {
+		if value, present := os.LookupEnv(setting.name); present {
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 1 {
+				return LoanPolicy{}, NewMissingEnvVariable(fmt.Sprintf("%s must be a positive number of days", setting.name))
+			}
+			*setting.period = time.Duration(days) * day
+		}
+	}
+
+	if value, present := os.LookupEnv("overdue_sweep_interval"); present {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			return LoanPolicy{}, NewMissingEnvVariable("overdue_sweep_interval must be a duration, i.e. 30m")
+		}
+		policy.SweepInterval = interval
+	}
+
+	if policy.Period > policy.MaxPeriod {
+		return LoanPolicy{}, NewMissingEnvVariable("loan_period_days cannot exceed max_loan_period_days")
+	}
+
+	return policy, nil
+}
+
+// dueAt returns the due date of a Book checked out at the time for the days asked, the policy period for 0.
+// It returns a Validation Error if the days exceed the policy maximum
+func (p LoanPolicy) dueAt(checkedOutAt time.Time, days int) (time.Time, *BookAPIError) {
+	if days == 0 {
+		return checkedOutAt.Add(p.Period), nil
+	}
+
+	maxDays := int(p.MaxPeriod / day)
+	if days < 1 || days > maxDays {
+		return time.Time{}, NewInvalidFieldError("loan_days", "range", fmt.Sprintf("loan_days must be between 1 and %d", maxDays))
+	}
+
+	return checkedOutAt.Add(time.Duration(days) * day), nil
+}
+
+// daysOverdue returns the number of days, started ones included, the loan due at the time is overdue at now
+func daysOverdue(dueAt time.Time, now time.Time) int {
+	if !now.After(dueAt) {
+		return 0
+	}
+
+	return int((now.Sub(dueAt) + day - 1) / day)
+}
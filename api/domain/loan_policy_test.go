@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewLoanPolicy(t *testing.T) {
+	defer os.Unsetenv("loan_period_days")
+	defer os.Unsetenv("max_loan_period_days")
+	defer os.Unsetenv("hold_pickup_days")
+	defer os.Unsetenv("overdue_sweep_interval")
+
+	policy, err := NewLoanPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultLoanPolicy, policy)
+
+	os.Setenv("loan_period_days", "14")
+	os.Setenv("max_loan_period_days", "28")
+	os.Setenv("hold_pickup_days", "3")
+	os.Setenv("overdue_sweep_interval", "15m")
+	policy, err = NewLoanPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, LoanPolicy{Period: 14 * day, MaxPeriod: 28 * day, PickupWindow: 3 * day, SweepInterval: 15 * time.Minute}, policy)
+
+	for name, value := range map[string]string{"loan_period_days": "two", "max_loan_period_days": "7", "hold_pickup_days": "0", "overdue_sweep_interval": "hourly"} {
+		os.Setenv(name, value)
+		_, err = NewLoanPolicy()
+		assert.NotNil(t, err, name)
+		assert.Equal(t, MissingEnvVariable, err.errorType)
+	}
+}
+
+func TestLoanPolicy_DueAt(t *testing.T) {
+	checkedOutAt := time.Date(2019, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	dueAt, err := DefaultLoanPolicy.dueAt(checkedOutAt, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, checkedOutAt.AddDate(0, 0, 21), dueAt)
+
+	dueAt, err = DefaultLoanPolicy.dueAt(checkedOutAt, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, checkedOutAt.AddDate(0, 0, 7), dueAt)
+
+	for _, days := range []int{-1, 91} {
+		_, err = DefaultLoanPolicy.dueAt(checkedOutAt, days)
+		assert.NotNil(t, err)
+		assert.Equal(t, []FieldError{{Field: "loan_days", Rule: "range", Message: "loan_days must be between 1 and 90"}}, err.fields)
+	}
+}
+
+func TestDaysOverdue(t *testing.T) {
+	dueAt := time.Date(2019, 4, 22, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0, daysOverdue(dueAt, dueAt))
+	assert.Equal(t, 0, daysOverdue(dueAt, dueAt.Add(-time.Hour)))
+	assert.Equal(t, 1, daysOverdue(dueAt, dueAt.Add(time.Minute)))
+	assert.Equal(t, 1, daysOverdue(dueAt, dueAt.Add(day)))
+	assert.Equal(t, 3, daysOverdue(dueAt, dueAt.Add(2*day+time.Hour)))
+}
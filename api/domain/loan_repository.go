@@ -0,0 +1,48 @@
+package domain
+
+type loanRepo struct {
+	collection collection
+}
+
+// FindAll Queries the loans collection with optional filters and find options
+// It returns a list of Loans or an API Error Response
+func (r *loanRepo) FindAll(filter Filter, findOptions FindOptions) (Loans, *BookAPIError) {
+	loans := Loans{}
+	if err := r.collection.find(filter, findOptions, &loans); err != nil {
+		return nil, err
+	}
+
+	return loans, nil
+}
+
+// Update sets the fields of the Loan with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *loanRepo) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	updated, err := r.collection.update(id, condition, fields)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return NewLoanNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Save Saves the Loan Payload, generating an ID when it has none
+// It returns the persisted Loan ID or an API Error Response if failed
+func (r *loanRepo) Save(loan Loan) (string, *BookAPIError) {
+	return r.collection.insert(loan)
+}
+
+// NewLoanRepository Initializes the loans repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewLoanRepository() (LoanRepository, *BookAPIError) {
+	collection, err := newCollection("loans")
+	if err != nil {
+		return nil, err
+	}
+
+	return &loanRepo{collection: collection}, nil
+}
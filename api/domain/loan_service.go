@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"log"
+	"time"
+)
+
+type loanService struct {
+	loans LoanRepository
+	// now current time of the loans, in UTC to the millisecond the stores keep
+	now func() time.Time
+}
+
+func (s *loanService) Overdue() (OverdueLoans, *BookAPIError) {
+	now := s.now()
+	loans, err := s.loans.FindAll(overdueFilter(now), FindOptions{Sort: []Sort{{Field: "due_at", Order: ASC}}})
+	if err != nil {
+		return nil, err
+	}
+
+	overdue := make(OverdueLoans, 0, len(loans))
+	for _, loan := range loans {
+		overdue = append(overdue, OverdueLoan{Loan: loan, DaysOverdue: daysOverdue(loan.DueAt, now)})
+	}
+
+	return overdue, nil
+}
+
+func (s *loanService) MarkOverdue() (int, *BookAPIError) {
+	now := s.now()
+	unmarked := AllOf(overdueFilter(now), NewQueryFilter("overdue_at", Equals, nil))
+	loans, err := s.loans.FindAll(unmarked, FindOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	marked := 0
+	for _, loan := range loans {
+		// a loan returned or marked since it was found is left as is
+		condition := AllOf(NewQueryFilter("returned_at", Equals, nil), NewQueryFilter("overdue_at", Equals, nil))
+		markError := s.loans.Update(loan.ID.Hex(), condition, Fields{"overdue_at": now})
+		if markError == nil {
+			marked++
+			continue
+		}
+
+		if markError.errorType != NotFoundError {
+			return marked, markError
+		}
+	}
+
+	return marked, nil
+}
+
+// overdueFilter returns the Filter of the loans open past their due date at now
+func overdueFilter(now time.Time) Filter {
+	return AllOf(NewQueryFilter("returned_at", Equals, nil), NewQueryFilter("due_at", LessThan, now))
+}
+
+// StartOverdueSweep marks the overdue loans every interval until stop is called, never when the interval is 0
+func StartOverdueSweep(service LoanService, interval time.Duration) (stop func()) {
+	return startSweep(interval, "mark the overdue loans", "Marked %d loans overdue", service.MarkOverdue)
+}
+
+// StartHoldSweep expires the holds not picked up in time every interval until stop is called, never when the
+// interval is 0
+func StartHoldSweep(service Service, interval time.Duration) (stop func()) {
+	return startSweep(interval, "expire the holds", "Expired %d holds", service.ExpireHolds)
+}
+
+// startSweep runs the sweep every interval until stop is called, logging what it did
+func startSweep(interval time.Duration, task string, done string, sweep func() (int, *BookAPIError)) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	stopped := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				count, err := sweep()
+				if err != nil {
+					log.Println("Unable to "+task+":", err.Error())
+				}
+				if count > 0 {
+					log.Printf(done+"\n", count)
+				}
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(stopped)
+	}
+}
+
+// NewLoanService creates instance of the Loan service
+func NewLoanService(loans LoanRepository) LoanService {
+	return &loanService{loans: loans, now: loanTime}
+}
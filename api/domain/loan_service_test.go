@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+// testOverdueLoans exercises the overdue report and sweep over the loans stored in the collection
+func testOverdueLoans(t *testing.T, loans collection) {
+	repo := &loanRepo{collection: loans}
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	loanService := &loanService{loans: repo, now: func() time.Time { return now }}
+
+	save := func(dueAt time.Time, returned bool) string {
+		loan := Loan{BookID: primitive.NewObjectID().Hex(), PatronID: "1", CheckedOutAt: dueAt.Add(-DefaultLoanPolicy.Period), DueAt: dueAt}
+		if returned {
+			loan.ReturnedAt = &now
+		}
+		id, err := repo.Save(loan)
+		assert.Nil(t, err)
+		return id
+	}
+
+	dueYesterday := save(now.Add(-20*time.Hour), false)
+	dueLastWeek := save(now.Add(-7*day), false)
+	save(now.Add(-3*day), true)
+	save(now.Add(day), false)
+
+	overdue, err := loanService.Overdue()
+	assert.Nil(t, err)
+	if assert.Len(t, overdue, 2) {
+		assert.Equal(t, dueLastWeek, overdue[0].ID.Hex())
+		assert.Equal(t, 7, overdue[0].DaysOverdue)
+		assert.Equal(t, dueYesterday, overdue[1].ID.Hex())
+		assert.Equal(t, 1, overdue[1].DaysOverdue)
+		assert.Nil(t, overdue[0].OverdueAt)
+	}
+
+	marked, err := loanService.MarkOverdue()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, marked)
+
+	marked, err = loanService.MarkOverdue()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, marked)
+
+	overdue, _ = loanService.Overdue()
+	for _, loan := range overdue {
+		if assert.NotNil(t, loan.OverdueAt) {
+			assert.True(t, now.Equal(*loan.OverdueAt))
+		}
+	}
+}
+
+func TestLoanService_Overdue(t *testing.T) {
+	testOverdueLoans(t, newMemoryCollection(nil))
+}
+
+func TestLoanService_Overdue_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testOverdueLoans(t, newSQLiteCollection(t, path, "loans"))
+}
+
+func TestStartOverdueSweep(t *testing.T) {
+	repo := &loanRepo{collection: newMemoryCollection(nil)}
+	id, _ := repo.Save(Loan{BookID: "1", PatronID: "1", CheckedOutAt: time.Now().Add(-2 * day), DueAt: time.Now().Add(-day)})
+
+	stop := StartOverdueSweep(NewLoanService(repo), time.Millisecond)
+	defer stop()
+
+	var marked Loans
+	for deadline := time.Now().Add(time.Second); len(marked) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		marked, _ = repo.FindAll(NewQueryFilter("overdue_at", DoesNotEqual, nil), FindOptions{})
+	}
+
+	if assert.Len(t, marked, 1) {
+		assert.Equal(t, id, marked[0].ID.Hex())
+	}
+}
@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memoryCollection thread-safe in-memory collection of documents
+type memoryCollection struct {
+	mu   sync.RWMutex
+	docs []bson.M
+	// unique keys no two documents have the same values of, as the unique indexes of the other engines
+	unique [][]string
+}
+
+func newMemoryCollection(docs []bson.M, unique ...[]string) *memoryCollection {
+	return &memoryCollection{docs: docs, unique: unique}
+}
+
+func (c *memoryCollection) find(filter Filter, findOptions FindOptions, results interface{}) *BookAPIError {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	matched := []bson.M{}
+	for _, doc := range c.docs {
+		ok, err := matchFilter(doc, filter)
+		if err != nil {
+			return NewDatabaseOperationError(err.Error())
+		}
+
+		if ok {
+			matched = append(matched, doc)
+		}
+	}
+
+	sortDocuments(matched, findOptions.Sort)
+
+	start, end := findOptions.Skip, int64(len(matched))
+	if start > end {
+		start = end
+	}
+	if findOptions.Limit > 0 && start+findOptions.Limit < end {
+		end = start + findOptions.Limit
+	}
+
+	if err := decodeDocuments(matched[start:end], results); err != nil {
+		return NewDatabaseOperationError(err.Error())
+	}
+
+	return nil
+}
+
+func (c *memoryCollection) count(filter Filter) (int64, *BookAPIError) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var count int64
+	for _, doc := range c.docs {
+		ok, err := matchFilter(doc, filter)
+		if err != nil {
+			return 0, NewDatabaseOperationError(err.Error())
+		}
+
+		if ok {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (c *memoryCollection) insert(entity interface{}) (string, *BookAPIError) {
+	doc, id, err := newDocument(entity)
+	if err != nil {
+		return "", NewPersistError(err.Error())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.indexOf(id) >= 0 {
+		return "", NewPersistError("duplicate id " + id)
+	}
+
+	if err := c.checkUnique(doc, -1); err != nil {
+		return "", err
+	}
+
+	c.docs = append(c.docs, doc)
+	return id, nil
+}
+
+func (c *memoryCollection) update(id string, condition Filter, fields Fields) (bool, *BookAPIError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := c.conditionalIndexOf(id, condition)
+	if index < 0 || err != nil {
+		return false, err
+	}
+
+	updated := bson.M{}
+	for field, value := range c.docs[index] {
+		updated[field] = value
+	}
+	for field, value := range fields {
+		if field != "_id" {
+			updated[field] = value
+		}
+	}
+
+	// the values are normalized to their bson types so that they compare with the stored ones
+	raw, marshalErr := bson.Marshal(updated)
+	if marshalErr != nil {
+		return false, NewDatabaseOperationError(marshalErr.Error())
+	}
+
+	normalized := bson.M{}
+	if unmarshalErr := bson.Unmarshal(raw, &normalized); unmarshalErr != nil {
+		return false, NewDatabaseOperationError(unmarshalErr.Error())
+	}
+
+	if err := c.checkUnique(normalized, index); err != nil {
+		return false, err
+	}
+
+	c.docs[index] = normalized
+	return true, nil
+}
+
+func (c *memoryCollection) delete(id string, condition Filter) (bool, *BookAPIError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index, err := c.conditionalIndexOf(id, condition)
+	if index < 0 || err != nil {
+		return false, err
+	}
+
+	c.docs = append(c.docs[:index], c.docs[index+1:]...)
+	return true, nil
+}
+
+// conditionalIndexOf returns the position of the document with the ID, or -1 when absent or not matching the condition.
+// Callers must hold the lock.
+func (c *memoryCollection) conditionalIndexOf(id string, condition Filter) (int, *BookAPIError) {
+	index := c.indexOf(id)
+	if index < 0 {
+		return -1, nil
+	}
+
+	matched, err := matchFilter(c.docs[index], condition)
+	if err != nil {
+		return -1, NewDatabaseOperationError(err.Error())
+	}
+
+	if !matched {
+		return -1, nil
+	}
+
+	return index, nil
+}
+
+// checkUnique returns an Already Exists error if a document other than the one at the position has the values of a
+// unique key of the document. Callers must hold the lock.
+func (c *memoryCollection) checkUnique(doc bson.M, position int) *BookAPIError {
+	for _, key := range c.unique {
+		for i, other := range c.docs {
+			if i != position && sameKey(doc, other, key) {
+				return NewDuplicateKeyError(fmt.Sprintf("duplicate key %s", strings.Join(key, ", ")))
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameKey reports whether both documents have the same values of the key fields
+func sameKey(doc bson.M, other bson.M, key []string) bool {
+	for _, field := range key {
+		if !valuesEqual(doc[field], other[field]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// indexOf returns the position of the document with the ID or -1 when absent. Callers must hold the lock.
+func (c *memoryCollection) indexOf(id string) int {
+	for i, doc := range c.docs {
+		if matched, _ := matchFilter(doc, idFilter(id)); matched {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// snapshot returns a copy of every stored document
+func (c *memoryCollection) snapshot() []bson.M {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	docs := make([]bson.M, len(c.docs))
+	copy(docs, c.docs)
+	return docs
+}
+
+// restore replaces the stored documents
+func (c *memoryCollection) restore(docs []bson.M) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docs = docs
+}
+
+// sortDocuments orders the documents by the sort fields
+func sortDocuments(docs []bson.M, sortFields []Sort) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, field := range sortFields {
+			cmp, _ := compareValues(docs[i][field.Field], docs[j][field.Field])
+			if cmp != 0 {
+				return (cmp < 0) == (field.Order != DESC)
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,518 @@
+package domain
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryRepo struct {
+	mu    sync.RWMutex
+	books []Book
+	index *searchIndex
+}
+
+// FindAll Queries the in-memory store with optional filters on custom attributes and find options
+// It returns a list of paginated Books or an API Error Response
+func (r *memoryRepo) FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books, err := r.matching(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if sortErr := sortBooks(books, findOptions.Sort); sortErr != nil {
+		return nil, NewDatabaseOperationError(sortErr.Error())
+	}
+
+	return paginate(books, findOptions.Skip, findOptions.Limit), nil
+}
+
+// Count Counts the Books in the in-memory store matching the filter
+// It returns the number of Books or an API Error Response
+func (r *memoryRepo) Count(filter Filter) (int64, *BookAPIError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books, err := r.matching(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(books)), nil
+}
+
+// Search Queries the search index of the in-memory store for the Books matching any term of the text
+// It returns the page of Books selected by the find options, most relevant first, with the number of matching Books
+func (r *memoryRepo) Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := r.index.search(text)
+	hits := SearchHits{}
+	for _, result := range searchPage(results, findOptions) {
+		if index := r.indexOf(result.id); index >= 0 {
+			hits = append(hits, SearchHit{Book: r.books[index], Score: result.score})
+		}
+	}
+
+	return hits, int64(len(results)), nil
+}
+
+// FindOne Queries the in-memory store for a specific Book
+// It returns one Book or an API Error Response
+func (r *memoryRepo) FindOne(id string) (Book, *BookAPIError) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if index := r.indexOf(id); index >= 0 {
+		return r.books[index], nil
+	}
+
+	return Book{}, NewNotFoundError(id)
+}
+
+// Delete Hard deletes the Book with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *memoryRepo) Delete(id string, condition Filter) *BookAPIError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.conditionalIndexOf(id, condition)
+	if err != nil {
+		return err
+	}
+
+	r.books = append(r.books[:index], r.books[index+1:]...)
+	r.index.remove(id)
+	return nil
+}
+
+// Update sets the fields of the Book with the specified ID when it matches the condition, incrementing its version
+// It returns an API Error Response if failed
+func (r *memoryRepo) Update(id string, condition Filter, updatedFields Fields) *BookAPIError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := r.conditionalIndexOf(id, condition)
+	if err != nil {
+		return err
+	}
+
+	updated, updateErr := applyUpdate(r.books[index], updatedFields)
+	if updateErr != nil {
+		return NewDatabaseOperationError(updateErr.Error())
+	}
+
+	r.books[index] = updated
+	r.index.add(updated)
+	return nil
+}
+
+// IsExistingEntry Checks the store if it has a Book with the same unique composite fields
+// Author, Title, Publish_Date
+// It returns a boolean
+func (r *memoryRepo) IsExistingEntry(book Book) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, existing := range r.books {
+		if existing.Author == book.Author && existing.Title == book.Title && existing.PublishDate == book.PublishDate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Save Saves the Book Payload, generating an ID when it has none
+// It returns the persisted Book ID or an API Error Response if failed
+func (r *memoryRepo) Save(book Book) (string, *BookAPIError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if book.ID.IsZero() {
+		book.ID = primitive.NewObjectID()
+	}
+
+	if r.indexOf(book.ID.Hex()) >= 0 {
+		return "", NewPersistError(fmt.Sprintf("duplicate id %s", book.ID.Hex()))
+	}
+
+	book.Version = 1
+
+	r.books = append(r.books, book)
+	r.index.add(book)
+	return book.ID.Hex(), nil
+}
+
+// matching returns the stored Books matching the filter, callers must hold the lock
+func (r *memoryRepo) matching(filter Filter) (Books, *BookAPIError) {
+	var books = Books{}
+	for _, book := range r.books {
+		doc, err := toDocument(book)
+		if err != nil {
+			return nil, NewDatabaseOperationError(err.Error())
+		}
+
+		matched, matchErr := matchFilter(doc, filter)
+		if matchErr != nil {
+			return nil, NewDatabaseOperationError(matchErr.Error())
+		}
+
+		if matched {
+			books = append(books, book)
+		}
+	}
+
+	return books, nil
+}
+
+// indexOf returns the position of the Book with the specified ID or -1 when absent.
+// Callers must hold the lock.
+func (r *memoryRepo) indexOf(id string) int {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return -1
+	}
+
+	for i, book := range r.books {
+		if book.ID == objectID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// conditionalIndexOf returns the position of the Book with the specified ID.
+// It returns a Not Found error when absent and a Precondition Failed error when the Book does not match the condition.
+// Callers must hold the lock.
+func (r *memoryRepo) conditionalIndexOf(id string, condition Filter) (int, *BookAPIError) {
+	index := r.indexOf(id)
+	if index < 0 {
+		return -1, NewNotFoundError(id)
+	}
+
+	doc, err := toDocument(r.books[index])
+	if err != nil {
+		return -1, NewDatabaseOperationError(err.Error())
+	}
+
+	matched, matchErr := matchFilter(doc, condition)
+	if matchErr != nil {
+		return -1, NewDatabaseOperationError(matchErr.Error())
+	}
+
+	if !matched {
+		return -1, NewPreconditionFailedError(bookKind, id)
+	}
+
+	return index, nil
+}
+
+// snapshot returns a copy of every stored Book
+func (r *memoryRepo) snapshot() Books {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	books := make(Books, len(r.books))
+	copy(books, r.books)
+	return books
+}
+
+// restore replaces the stored Books
+func (r *memoryRepo) restore(books Books) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.books = books
+	r.index.reset(books)
+}
+
+// NewMemoryRepository Initializes an empty thread-safe in-memory repository
+func NewMemoryRepository() Repository {
+	return newMemoryRepo(Books{})
+}
+
+// newMemoryRepo returns a store of the Books with their search index
+func newMemoryRepo(books Books) *memoryRepo {
+	index := newSearchIndex()
+	index.reset(books)
+	return &memoryRepo{books: books, index: index}
+}
+
+// toDocument converts a Book into its bson document representation
+func toDocument(book Book) (bson.M, error) {
+	return toBSONDocument(book)
+}
+
+// toBSONDocument converts an entity into its bson document representation
+func toBSONDocument(entity interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := bson.M{}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// fromDocument converts a bson document back into a Book
+func fromDocument(doc bson.M) (Book, error) {
+	var book Book
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return book, err
+	}
+
+	err = bson.Unmarshal(raw, &book)
+	return book, err
+}
+
+// applyUpdate sets the fields on the Book and increments its version, the ID and version are never set
+func applyUpdate(book Book, fields Fields) (Book, error) {
+	doc, err := toDocument(book)
+	if err != nil {
+		return book, err
+	}
+
+	for field, value := range fields {
+		if field != "_id" && field != "version" {
+			doc[field] = value
+		}
+	}
+
+	updated, err := fromDocument(doc)
+	updated.Version = book.Version + 1
+	return updated, err
+}
+
+// matchFilter reports whether the document satisfies the filter expression tree
+func matchFilter(doc bson.M, filter Filter) (bool, error) {
+	if filter.IsEmpty() {
+		return true, nil
+	}
+
+	if filter.Query != nil {
+		return matchQuery(doc[filter.Query.Field], *filter.Query)
+	}
+
+	for _, child := range filter.Children {
+		matched, err := matchFilter(doc, child)
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case filter.Operator == Or && matched:
+			return true, nil
+		case filter.Operator == And && !matched:
+			return false, nil
+		case filter.Operator == Not:
+			return !matched, nil
+		}
+	}
+
+	switch filter.Operator {
+	case And:
+		return true, nil
+	case Or:
+		return false, nil
+	}
+
+	return false, fmt.Errorf("unsupported logical operator %s", filter.Operator)
+}
+
+// matchQuery evaluates a single field condition
+func matchQuery(value interface{}, query Query) (bool, error) {
+	// a nil value stands for a missing or null field
+	if query.Value == nil && (query.Operator == Equals || query.Operator == DoesNotEqual) {
+		return (value == nil) == (query.Operator == Equals), nil
+	}
+
+	switch query.Operator {
+	case Equals:
+		return valuesEqual(value, query.Value), nil
+	case DoesNotEqual:
+		return !valuesEqual(value, query.Value), nil
+	case In:
+		candidates, ok := query.Value.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s requires a list of values", query.Operator)
+		}
+		for _, candidate := range candidates {
+			if valuesEqual(value, candidate) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case StartsWith, Contains:
+		text, ok := toString(value)
+		if !ok {
+			return false, nil
+		}
+		text, pattern := strings.ToLower(text), strings.ToLower(fmt.Sprint(query.Value))
+		if query.Operator == StartsWith {
+			return strings.HasPrefix(text, pattern), nil
+		}
+		return strings.Contains(text, pattern), nil
+	case LessThan, GreaterThan, GreaterThanOrEqual, LessThanOrEqual:
+		cmp, ok := compareValues(value, query.Value)
+		if !ok {
+			return false, nil
+		}
+		return (query.Operator == LessThan && cmp < 0) || (query.Operator == GreaterThan && cmp > 0) ||
+			(query.Operator == GreaterThanOrEqual && cmp >= 0) || (query.Operator == LessThanOrEqual && cmp <= 0), nil
+	}
+
+	return false, fmt.Errorf("unsupported query operator %s", query.Operator)
+}
+
+// sortBooks orders the books by the sort fields
+func sortBooks(books Books, sortFields []Sort) error {
+	if len(sortFields) == 0 {
+		return nil
+	}
+
+	docs := make(map[primitive.ObjectID]bson.M, len(books))
+	for _, book := range books {
+		doc, err := toDocument(book)
+		if err != nil {
+			return err
+		}
+		docs[book.ID] = doc
+	}
+
+	sort.SliceStable(books, func(i, j int) bool {
+		for _, field := range sortFields {
+			cmp, _ := compareValues(docs[books[i].ID][field.Field], docs[books[j].ID][field.Field])
+			if cmp != 0 {
+				return (cmp < 0) == (field.Order != DESC)
+			}
+		}
+		return false
+	})
+
+	return nil
+}
+
+// paginate applies the skip and limit find options
+func paginate(books Books, skip int64, limit int64) Books {
+	if skip < 0 {
+		skip = 0
+	}
+
+	if skip >= int64(len(books)) {
+		return Books{}
+	}
+
+	end := int64(len(books))
+	if limit > 0 && skip+limit < end {
+		end = skip + limit
+	}
+
+	return books[skip:end]
+}
+
+func valuesEqual(left interface{}, right interface{}) bool {
+	cmp, ok := compareValues(left, right)
+	return ok && cmp == 0
+}
+
+// compareValues compares numbers with numbers, times with times and strings with strings.
+// It returns false when the values are not comparable.
+func compareValues(left interface{}, right interface{}) (int, bool) {
+	if l, ok := toTime(left); ok {
+		r, ok := toTime(right)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case l.Before(r):
+			return -1, true
+		case l.After(r):
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if l, ok := toFloat64(left); ok {
+		r, ok := toFloat64(right)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case l < r:
+			return -1, true
+		case l > r:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	l, lok := toString(left)
+	r, rok := toString(right)
+	if !lok || !rok {
+		return 0, false
+	}
+
+	switch {
+	case l < r:
+		return -1, true
+	case l > r:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case primitive.ObjectID:
+		return v.Hex(), true
+	}
+
+	return "", false
+}
+
+func toTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case primitive.DateTime:
+		return time.Unix(int64(v)/1000, int64(v)%1000*int64(time.Millisecond)), true
+	}
+
+	return time.Time{}, false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case Status:
+		return float64(v), true
+	}
+
+	return 0, false
+}
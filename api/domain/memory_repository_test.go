@@ -0,0 +1,223 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"sync"
+	"testing"
+)
+
+func sampleBooks() Books {
+	return Books{
+		{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, Rating: 3, PublishDate: "2008"},
+		{Author: "Paulo Coelho", Title: "The Alchemist", Publisher: "HarperCollins", Status: CheckedOut, Rating: 2, PublishDate: "1988"},
+		{Author: "Robert Martin", Title: "Clean Architecture", Publisher: "Prentice Hall", Status: CheckedOut, Rating: 1, PublishDate: "2017"},
+	}
+}
+
+func seedRepository(t *testing.T, repo Repository) Repository {
+	for _, book := range sampleBooks() {
+		if _, err := repo.Save(book); err != nil {
+			t.Fatalf(`Unable to seed repository: %s`, err.Error())
+		}
+	}
+
+	return repo
+}
+
+func seedMemoryRepository(t *testing.T) Repository {
+	return seedRepository(t, NewMemoryRepository())
+}
+
+// expressionFilterCases filters exercising every operator, shared by the repository backends
+var expressionFilterCases = []struct {
+	name     string
+	filter   Filter
+	expected int
+}{
+	{"empty", Filter{}, 3},
+	{"or", AnyOf(NewQueryFilter("publish_date", Equals, "1988"), NewQueryFilter("rating", Equals, int64(1))), 2},
+	{"not", Negate(NewQueryFilter("author", Equals, "Robert Martin")), 1},
+	{"in", NewQueryFilter("rating", In, []interface{}{int64(1), int64(3)}), 2},
+	{"prefix", NewQueryFilter("title", StartsWith, "clean"), 2},
+	{"contains", NewQueryFilter("publisher", Contains, "COLLINS"), 1},
+	{"nested", AllOf(NewQueryFilter("status", Equals, int64(CheckedOut)), Negate(AnyOf(NewQueryFilter("rating", GreaterThan, int64(1)), NewQueryFilter("title", Contains, "%")))), 1},
+}
+
+func TestMemoryRepo_FindAll_WithExpressionFilters(t *testing.T) {
+	repo := seedMemoryRepository(t)
+
+	for _, c := range expressionFilterCases {
+		books, err := repo.FindAll(c.filter, FindOptions{})
+		assert.Nil(t, err, c.name)
+		assert.Len(t, books, c.expected, c.name)
+	}
+}
+
+func TestMemoryRepo_Count(t *testing.T) {
+	repo := seedMemoryRepository(t)
+
+	for _, c := range expressionFilterCases {
+		count, err := repo.Count(c.filter)
+		assert.Nil(t, err, c.name)
+		assert.Equal(t, int64(c.expected), count, c.name)
+	}
+}
+
+func TestMemoryRepo_SaveAndFindOne(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, err := repo.Save(Book{Author: "thg090020", Title: "Test Title", PublishDate: "2019"})
+
+	assert.Nil(t, err, `Invalid response.. Expected no error but Got %s\n`, err)
+	assert.NotEmpty(t, id)
+
+	book, findErr := repo.FindOne(id)
+	assert.Nil(t, findErr)
+	assert.Equal(t, id, book.ID.Hex())
+	assert.Equal(t, "thg090020", book.Author)
+}
+
+func TestMemoryRepo_FindOne_WithNotFound(t *testing.T) {
+	repo := seedMemoryRepository(t)
+	_, err := repo.FindOne("5ca7c76f9287bd3832d96f15")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, NotFoundError, err.errorType)
+}
+
+func TestMemoryRepo_FindAll_WithFilters(t *testing.T) {
+	repo := seedMemoryRepository(t)
+
+	books, err := repo.FindAll(AllOf(
+		NewQueryFilter("author", Equals, "Robert Martin"),
+		NewQueryFilter("status", Equals, int64(CheckedOut)),
+	), FindOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, books, 1)
+	assert.Equal(t, "Clean Architecture", books[0].Title)
+
+	books, err = repo.FindAll(NewQueryFilter("rating", GreaterThanOrEqual, 2), FindOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, books, 2)
+
+	books, err = repo.FindAll(NewQueryFilter("publish_date", LessThan, "2010"), FindOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, books, 2)
+}
+
+func TestMemoryRepo_FindAll_WithSortAndPagination(t *testing.T) {
+	repo := seedMemoryRepository(t)
+	findOptions := FindOptions{Sort: []Sort{{Field: "rating", Order: DESC}}, Skip: 1, Limit: 1}
+
+	books, err := repo.FindAll(Filter{}, findOptions)
+	assert.Nil(t, err)
+	assert.Len(t, books, 1)
+	assert.Equal(t, "The Alchemist", books[0].Title)
+
+	books, err = repo.FindAll(Filter{}, FindOptions{Skip: 10, Limit: 10})
+	assert.Nil(t, err)
+	assert.Len(t, books, 0)
+}
+
+func TestMemoryRepo_FindAll_WithMultiFieldSort(t *testing.T) {
+	repo := seedMemoryRepository(t)
+	findOptions := FindOptions{Sort: []Sort{{Field: "author", Order: DESC}, {Field: "publish_date", Order: ASC}, {Field: "_id", Order: ASC}}}
+
+	books, err := repo.FindAll(Filter{}, findOptions)
+	assert.Nil(t, err)
+	assert.Equal(t, "Clean Code", books[0].Title)
+	assert.Equal(t, "Clean Architecture", books[1].Title)
+	assert.Equal(t, "The Alchemist", books[2].Title)
+}
+
+func TestMemoryRepo_Update(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "thg090020", Status: CheckedIn, PublishDate: "2019"})
+
+	err := repo.Update(id, Filter{}, Fields{"status": CheckedOut})
+	assert.Nil(t, err)
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, CheckedOut, book.Status)
+	assert.Equal(t, "thg090020", book.Author)
+}
+
+func TestMemoryRepo_Update_WithVersionCondition(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "thg090020", Status: CheckedIn, PublishDate: "2019"})
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, int64(1), book.Version)
+
+	assert.Nil(t, repo.Update(id, versionCondition(Versions{1}), Fields{"status": CheckedOut, "version": int64(7)}))
+	book, _ = repo.FindOne(id)
+	assert.Equal(t, int64(2), book.Version)
+
+	err := repo.Update(id, versionCondition(Versions{1}), Fields{"status": CheckedIn})
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+
+	err = repo.Delete(id, versionCondition(Versions{1}))
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+
+	err = repo.Update("5ca7c76f9287bd3832d96f15", versionCondition(Versions{1}), Fields{"status": CheckedIn})
+	assert.NotNil(t, err)
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	book, _ = repo.FindOne(id)
+	assert.Equal(t, CheckedOut, book.Status)
+	assert.Nil(t, repo.Delete(id, versionCondition(Versions{2})))
+}
+
+func TestMemoryRepo_FindAll_WithUnsupportedOperator(t *testing.T) {
+	repo := seedMemoryRepository(t)
+
+	_, err := repo.FindAll(NewQueryFilter("rating", "~", 1), FindOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestMemoryRepo_Delete(t *testing.T) {
+	repo := seedMemoryRepository(t)
+	books, _ := repo.FindAll(Filter{}, FindOptions{})
+
+	assert.Nil(t, repo.Delete(books[0].ID.Hex(), Filter{}))
+
+	_, err := repo.FindOne(books[0].ID.Hex())
+	assert.NotNil(t, err)
+
+	remaining, _ := repo.FindAll(Filter{}, FindOptions{})
+	assert.Len(t, remaining, 2)
+}
+
+func TestMemoryRepo_IsExistingEntry(t *testing.T) {
+	repo := seedMemoryRepository(t)
+
+	assert.True(t, repo.IsExistingEntry(Book{Author: "Robert Martin", Title: "Clean Code", PublishDate: "2008"}))
+	assert.False(t, repo.IsExistingEntry(Book{Author: "Robert Martin", Title: "Clean Code", PublishDate: "2009"}))
+}
+
+func TestMemoryRepo_ConcurrentSaves(t *testing.T) {
+	repo := NewMemoryRepository()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = repo.Save(Book{Author: "thg090020"})
+		}()
+	}
+	wg.Wait()
+
+	books, _ := repo.FindAll(Filter{}, FindOptions{})
+	assert.Len(t, books, 50)
+}
+
+func TestRepo_NewRepositoryWithMemoryEngine(t *testing.T) {
+	_ = os.Setenv("storage_engine", "memory")
+	defer os.Unsetenv("storage_engine")
+
+	repo, err := NewRepository()
+	assert.Nil(t, err)
+	assert.NotNil(t, repo)
+}
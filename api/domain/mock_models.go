@@ -6,8 +6,6 @@ package domain
 
 import (
 	gomock "github.com/golang/mock/gomock"
-	bson "go.mongodb.org/mongo-driver/bson"
-	options "go.mongodb.org/mongo-driver/mongo/options"
 	reflect "reflect"
 )
 
@@ -35,18 +33,49 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // FindAll mocks base method
-func (m *MockRepository) FindAll(filters bson.M, findOptions *options.FindOptions) (Books, *BookAPIError) {
+func (m *MockRepository) FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", filters, findOptions)
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
 	ret0, _ := ret[0].(Books)
 	ret1, _ := ret[1].(*BookAPIError)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll
-func (mr *MockRepositoryMockRecorder) FindAll(filters, findOptions interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRepository)(nil).FindAll), filters, findOptions)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRepository)(nil).FindAll), filter, findOptions)
+}
+
+// Count mocks base method
+func (m *MockRepository) Count(filter Filter) (int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count
+func (mr *MockRepositoryMockRecorder) Count(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockRepository)(nil).Count), filter)
+}
+
+// Search mocks base method
+func (m *MockRepository) Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", text, findOptions)
+	ret0, _ := ret[0].(SearchHits)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(*BookAPIError)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search
+func (mr *MockRepositoryMockRecorder) Search(text, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockRepository)(nil).Search), text, findOptions)
 }
 
 // FindOne mocks base method
@@ -65,31 +94,31 @@ func (mr *MockRepositoryMockRecorder) FindOne(id interface{}) *gomock.Call {
 }
 
 // Update mocks base method
-func (m *MockRepository) Update(id string, fields bson.D) *BookAPIError {
+func (m *MockRepository) Update(id string, condition Filter, fields Fields) *BookAPIError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", id, fields)
+	ret := m.ctrl.Call(m, "Update", id, condition, fields)
 	ret0, _ := ret[0].(*BookAPIError)
 	return ret0
 }
 
 // Update indicates an expected call of Update
-func (mr *MockRepositoryMockRecorder) Update(id, fields interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Update(id, condition, fields interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), id, fields)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), id, condition, fields)
 }
 
 // Delete mocks base method
-func (m *MockRepository) Delete(id string) *BookAPIError {
+func (m *MockRepository) Delete(id string, condition Filter) *BookAPIError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", id, condition)
 	ret0, _ := ret[0].(*BookAPIError)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockRepositoryMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Delete(id, condition interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), id, condition)
 }
 
 // IsExistingEntry mocks base method
@@ -145,18 +174,64 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // FindAll mocks base method
-func (m *MockService) FindAll(filters bson.M, findOptions *options.FindOptions) (Books, *BookAPIError) {
+func (m *MockService) FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", filters, findOptions)
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
 	ret0, _ := ret[0].(Books)
 	ret1, _ := ret[1].(*BookAPIError)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll
-func (mr *MockServiceMockRecorder) FindAll(filters, findOptions interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockService)(nil).FindAll), filter, findOptions)
+}
+
+// Count mocks base method
+func (m *MockService) Count(filter Filter) (int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count
+func (mr *MockServiceMockRecorder) Count(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockService)(nil).Count), filter)
+}
+
+// Search mocks base method
+func (m *MockService) Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", text, findOptions)
+	ret0, _ := ret[0].(SearchHits)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(*BookAPIError)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search
+func (mr *MockServiceMockRecorder) Search(text, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockService)(nil).Search), text, findOptions)
+}
+
+// FindSimilar mocks base method
+func (m *MockService) FindSimilar(filter Filter, conditions []Query, findOptions FindOptions) (FuzzyResult, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSimilar", filter, conditions, findOptions)
+	ret0, _ := ret[0].(FuzzyResult)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindSimilar indicates an expected call of FindSimilar
+func (mr *MockServiceMockRecorder) FindSimilar(filter, conditions, findOptions interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockService)(nil).FindAll), filters, findOptions)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilar", reflect.TypeOf((*MockService)(nil).FindSimilar), filter, conditions, findOptions)
 }
 
 // FindOne mocks base method
@@ -175,59 +250,221 @@ func (mr *MockServiceMockRecorder) FindOne(id interface{}) *gomock.Call {
 }
 
 // Update mocks base method
-func (m *MockService) Update(id string, book Book) *BookAPIError {
+func (m *MockService) Update(id string, versions Versions, book Book) *BookAPIError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", id, book)
+	ret := m.ctrl.Call(m, "Update", id, versions, book)
 	ret0, _ := ret[0].(*BookAPIError)
 	return ret0
 }
 
 // Update indicates an expected call of Update
-func (mr *MockServiceMockRecorder) Update(id, book interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Update(id, versions, book interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), id, versions, book)
+}
+
+// Patch mocks base method
+func (m *MockService) Patch(id string, versions Versions, patch BookPatch) (Book, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Patch", id, versions, patch)
+	ret0, _ := ret[0].(Book)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (mr *MockServiceMockRecorder) Patch(id, versions, patch interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockService)(nil).Update), id, book)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockService)(nil).Patch), id, versions, patch)
 }
 
 // Delete mocks base method
-func (m *MockService) Delete(id string) *BookAPIError {
+func (m *MockService) Delete(id string, versions Versions) *BookAPIError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", id, versions)
 	ret0, _ := ret[0].(*BookAPIError)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(id, versions interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), id, versions)
 }
 
 // CheckOut mocks base method
-func (m *MockService) CheckOut(id string) *BookAPIError {
+func (m *MockService) CheckOut(id string, versions Versions, request LoanRequest) (Loan, *BookAPIError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckOut", id)
-	ret0, _ := ret[0].(*BookAPIError)
-	return ret0
+	ret := m.ctrl.Call(m, "CheckOut", id, versions, request)
+	ret0, _ := ret[0].(Loan)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
 }
 
 // CheckOut indicates an expected call of CheckOut
-func (mr *MockServiceMockRecorder) CheckOut(id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) CheckOut(id, versions, request interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOut", reflect.TypeOf((*MockService)(nil).CheckOut), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOut", reflect.TypeOf((*MockService)(nil).CheckOut), id, versions, request)
 }
 
 // CheckIn mocks base method
-func (m *MockService) CheckIn(id string) *BookAPIError {
+func (m *MockService) CheckIn(id string, versions Versions) *BookAPIError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckIn", id)
+	ret := m.ctrl.Call(m, "CheckIn", id, versions)
 	ret0, _ := ret[0].(*BookAPIError)
 	return ret0
 }
 
 // CheckIn indicates an expected call of CheckIn
-func (mr *MockServiceMockRecorder) CheckIn(id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) CheckIn(id, versions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIn", reflect.TypeOf((*MockService)(nil).CheckIn), id, versions)
+}
+
+// CheckOutCopy mocks base method
+func (m *MockService) CheckOutCopy(id, copyID string, versions Versions, request LoanRequest) (Loan, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckOutCopy", id, copyID, versions, request)
+	ret0, _ := ret[0].(Loan)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// CheckOutCopy indicates an expected call of CheckOutCopy
+func (mr *MockServiceMockRecorder) CheckOutCopy(id, copyID, versions, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOutCopy", reflect.TypeOf((*MockService)(nil).CheckOutCopy), id, copyID, versions, request)
+}
+
+// CheckInCopy mocks base method
+func (m *MockService) CheckInCopy(id, copyID string, versions Versions) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckInCopy", id, copyID, versions)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// CheckInCopy indicates an expected call of CheckInCopy
+func (mr *MockServiceMockRecorder) CheckInCopy(id, copyID, versions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInCopy", reflect.TypeOf((*MockService)(nil).CheckInCopy), id, copyID, versions)
+}
+
+// Transition mocks base method
+func (m *MockService) Transition(id string, versions Versions, status Status) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transition", id, versions, status)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Transition indicates an expected call of Transition
+func (mr *MockServiceMockRecorder) Transition(id, versions, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transition", reflect.TypeOf((*MockService)(nil).Transition), id, versions, status)
+}
+
+// TransitionCopy mocks base method
+func (m *MockService) TransitionCopy(id, copyID string, versions Versions, status Status) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionCopy", id, copyID, versions, status)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// TransitionCopy indicates an expected call of TransitionCopy
+func (mr *MockServiceMockRecorder) TransitionCopy(id, copyID, versions, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionCopy", reflect.TypeOf((*MockService)(nil).TransitionCopy), id, copyID, versions, status)
+}
+
+// Loans mocks base method
+func (m *MockService) Loans(id string) (Loans, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Loans", id)
+	ret0, _ := ret[0].(Loans)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Loans indicates an expected call of Loans
+func (mr *MockServiceMockRecorder) Loans(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Loans", reflect.TypeOf((*MockService)(nil).Loans), id)
+}
+
+// PlaceHold mocks base method
+func (m *MockService) PlaceHold(id string, request HoldRequest) (Hold, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlaceHold", id, request)
+	ret0, _ := ret[0].(Hold)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// PlaceHold indicates an expected call of PlaceHold
+func (mr *MockServiceMockRecorder) PlaceHold(id, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlaceHold", reflect.TypeOf((*MockService)(nil).PlaceHold), id, request)
+}
+
+// CancelHold mocks base method
+func (m *MockService) CancelHold(id, holdID string) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelHold", id, holdID)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// CancelHold indicates an expected call of CancelHold
+func (mr *MockServiceMockRecorder) CancelHold(id, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelHold", reflect.TypeOf((*MockService)(nil).CancelHold), id, holdID)
+}
+
+// Hold mocks base method
+func (m *MockService) Hold(id, holdID string) (Hold, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hold", id, holdID)
+	ret0, _ := ret[0].(Hold)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Hold indicates an expected call of Hold
+func (mr *MockServiceMockRecorder) Hold(id, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hold", reflect.TypeOf((*MockService)(nil).Hold), id, holdID)
+}
+
+// Holds mocks base method
+func (m *MockService) Holds(id string) (Holds, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Holds", id)
+	ret0, _ := ret[0].(Holds)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Holds indicates an expected call of Holds
+func (mr *MockServiceMockRecorder) Holds(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Holds", reflect.TypeOf((*MockService)(nil).Holds), id)
+}
+
+// ExpireHolds mocks base method
+func (m *MockService) ExpireHolds() (int, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds
+func (mr *MockServiceMockRecorder) ExpireHolds() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckIn", reflect.TypeOf((*MockService)(nil).CheckIn), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockService)(nil).ExpireHolds))
 }
 
 // Create mocks base method
@@ -246,15 +483,1093 @@ func (mr *MockServiceMockRecorder) Create(book interface{}) *gomock.Call {
 }
 
 // Rate mocks base method
-func (m *MockService) Rate(id string, rate int) *BookAPIError {
+func (m *MockService) Rate(id string, rate float64, request RatingRequest) (Rating, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rate", id, rate, request)
+	ret0, _ := ret[0].(Rating)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Rate indicates an expected call of Rate
+func (mr *MockServiceMockRecorder) Rate(id, rate, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rate", reflect.TypeOf((*MockService)(nil).Rate), id, rate, request)
+}
+
+// Ratings mocks base method
+func (m *MockService) Ratings(id string) (RatingSummary, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ratings", id)
+	ret0, _ := ret[0].(RatingSummary)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Ratings indicates an expected call of Ratings
+func (mr *MockServiceMockRecorder) Ratings(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ratings", reflect.TypeOf((*MockService)(nil).Ratings), id)
+}
+
+// RescaleRatings mocks base method
+func (m *MockService) RescaleRatings() (int, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescaleRatings")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// RescaleRatings indicates an expected call of RescaleRatings
+func (mr *MockServiceMockRecorder) RescaleRatings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescaleRatings", reflect.TypeOf((*MockService)(nil).RescaleRatings))
+}
+
+// MockPatronRepository is a mock of PatronRepository interface
+type MockPatronRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPatronRepositoryMockRecorder
+}
+
+// MockPatronRepositoryMockRecorder is the mock recorder for MockPatronRepository
+type MockPatronRepositoryMockRecorder struct {
+	mock *MockPatronRepository
+}
+
+// NewMockPatronRepository creates a new mock instance
+func NewMockPatronRepository(ctrl *gomock.Controller) *MockPatronRepository {
+	mock := &MockPatronRepository{ctrl: ctrl}
+	mock.recorder = &MockPatronRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPatronRepository) EXPECT() *MockPatronRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockPatronRepository) FindAll(filter Filter, findOptions FindOptions) (Patrons, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(Patrons)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockPatronRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockPatronRepository)(nil).FindAll), filter, findOptions)
+}
+
+// FindOne mocks base method
+func (m *MockPatronRepository) FindOne(id string) (Patron, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOne", id)
+	ret0, _ := ret[0].(Patron)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindOne indicates an expected call of FindOne
+func (mr *MockPatronRepositoryMockRecorder) FindOne(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockPatronRepository)(nil).FindOne), id)
+}
+
+// Update mocks base method
+func (m *MockPatronRepository) Update(id string, fields Fields) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, fields)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockPatronRepositoryMockRecorder) Update(id, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPatronRepository)(nil).Update), id, fields)
+}
+
+// Delete mocks base method
+func (m *MockPatronRepository) Delete(id string) *BookAPIError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rate", id, rate)
+	ret := m.ctrl.Call(m, "Delete", id)
 	ret0, _ := ret[0].(*BookAPIError)
 	return ret0
 }
 
-// Rate indicates an expected call of Rate
-func (mr *MockServiceMockRecorder) Rate(id, rate interface{}) *gomock.Call {
+// Delete indicates an expected call of Delete
+func (mr *MockPatronRepositoryMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPatronRepository)(nil).Delete), id)
+}
+
+// Save mocks base method
+func (m *MockPatronRepository) Save(patron Patron) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", patron)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockPatronRepositoryMockRecorder) Save(patron interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockPatronRepository)(nil).Save), patron)
+}
+
+// MockLoanRepository is a mock of LoanRepository interface
+type MockLoanRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoanRepositoryMockRecorder
+}
+
+// MockLoanRepositoryMockRecorder is the mock recorder for MockLoanRepository
+type MockLoanRepositoryMockRecorder struct {
+	mock *MockLoanRepository
+}
+
+// NewMockLoanRepository creates a new mock instance
+func NewMockLoanRepository(ctrl *gomock.Controller) *MockLoanRepository {
+	mock := &MockLoanRepository{ctrl: ctrl}
+	mock.recorder = &MockLoanRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoanRepository) EXPECT() *MockLoanRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockLoanRepository) FindAll(filter Filter, findOptions FindOptions) (Loans, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(Loans)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockLoanRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockLoanRepository)(nil).FindAll), filter, findOptions)
+}
+
+// Update mocks base method
+func (m *MockLoanRepository) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, condition, fields)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockLoanRepositoryMockRecorder) Update(id, condition, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockLoanRepository)(nil).Update), id, condition, fields)
+}
+
+// Save mocks base method
+func (m *MockLoanRepository) Save(loan Loan) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", loan)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockLoanRepositoryMockRecorder) Save(loan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockLoanRepository)(nil).Save), loan)
+}
+
+// MockLoanService is a mock of LoanService interface
+type MockLoanService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoanServiceMockRecorder
+}
+
+// MockLoanServiceMockRecorder is the mock recorder for MockLoanService
+type MockLoanServiceMockRecorder struct {
+	mock *MockLoanService
+}
+
+// NewMockLoanService creates a new mock instance
+func NewMockLoanService(ctrl *gomock.Controller) *MockLoanService {
+	mock := &MockLoanService{ctrl: ctrl}
+	mock.recorder = &MockLoanServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoanService) EXPECT() *MockLoanServiceMockRecorder {
+	return m.recorder
+}
+
+// Overdue mocks base method
+func (m *MockLoanService) Overdue() (OverdueLoans, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Overdue")
+	ret0, _ := ret[0].(OverdueLoans)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Overdue indicates an expected call of Overdue
+func (mr *MockLoanServiceMockRecorder) Overdue() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Overdue", reflect.TypeOf((*MockLoanService)(nil).Overdue))
+}
+
+// MarkOverdue mocks base method
+func (m *MockLoanService) MarkOverdue() (int, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOverdue")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// MarkOverdue indicates an expected call of MarkOverdue
+func (mr *MockLoanServiceMockRecorder) MarkOverdue() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOverdue", reflect.TypeOf((*MockLoanService)(nil).MarkOverdue))
+}
+
+// MockHoldRepository is a mock of HoldRepository interface
+type MockHoldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldRepositoryMockRecorder
+}
+
+// MockHoldRepositoryMockRecorder is the mock recorder for MockHoldRepository
+type MockHoldRepositoryMockRecorder struct {
+	mock *MockHoldRepository
+}
+
+// NewMockHoldRepository creates a new mock instance
+func NewMockHoldRepository(ctrl *gomock.Controller) *MockHoldRepository {
+	mock := &MockHoldRepository{ctrl: ctrl}
+	mock.recorder = &MockHoldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHoldRepository) EXPECT() *MockHoldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockHoldRepository) FindAll(filter Filter, findOptions FindOptions) (Holds, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(Holds)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockHoldRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockHoldRepository)(nil).FindAll), filter, findOptions)
+}
+
+// Update mocks base method
+func (m *MockHoldRepository) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, condition, fields)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockHoldRepositoryMockRecorder) Update(id, condition, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockHoldRepository)(nil).Update), id, condition, fields)
+}
+
+// Delete mocks base method
+func (m *MockHoldRepository) Delete(id string, condition Filter) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id, condition)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockHoldRepositoryMockRecorder) Delete(id, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHoldRepository)(nil).Delete), id, condition)
+}
+
+// Save mocks base method
+func (m *MockHoldRepository) Save(hold Hold) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", hold)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockHoldRepositoryMockRecorder) Save(hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockHoldRepository)(nil).Save), hold)
+}
+
+// MockRatingRepository is a mock of RatingRepository interface
+type MockRatingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRatingRepositoryMockRecorder
+}
+
+// MockRatingRepositoryMockRecorder is the mock recorder for MockRatingRepository
+type MockRatingRepositoryMockRecorder struct {
+	mock *MockRatingRepository
+}
+
+// NewMockRatingRepository creates a new mock instance
+func NewMockRatingRepository(ctrl *gomock.Controller) *MockRatingRepository {
+	mock := &MockRatingRepository{ctrl: ctrl}
+	mock.recorder = &MockRatingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRatingRepository) EXPECT() *MockRatingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockRatingRepository) FindAll(filter Filter, findOptions FindOptions) (Ratings, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(Ratings)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockRatingRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRatingRepository)(nil).FindAll), filter, findOptions)
+}
+
+// Update mocks base method
+func (m *MockRatingRepository) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, condition, fields)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockRatingRepositoryMockRecorder) Update(id, condition, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRatingRepository)(nil).Update), id, condition, fields)
+}
+
+// Save mocks base method
+func (m *MockRatingRepository) Save(rating Rating) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", rating)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockRatingRepositoryMockRecorder) Save(rating interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRatingRepository)(nil).Save), rating)
+}
+
+// Scale mocks base method
+func (m *MockRatingRepository) Scale() (RatingScale, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Scale")
+	ret0, _ := ret[0].(RatingScale)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Scale indicates an expected call of Scale
+func (mr *MockRatingRepositoryMockRecorder) Scale() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scale", reflect.TypeOf((*MockRatingRepository)(nil).Scale))
+}
+
+// SaveScale mocks base method
+func (m *MockRatingRepository) SaveScale(scale RatingScale) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveScale", scale)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// SaveScale indicates an expected call of SaveScale
+func (mr *MockRatingRepositoryMockRecorder) SaveScale(scale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveScale", reflect.TypeOf((*MockRatingRepository)(nil).SaveScale), scale)
+}
+
+// MockReviewRepository is a mock of ReviewRepository interface
+type MockReviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewRepositoryMockRecorder
+}
+
+// MockReviewRepositoryMockRecorder is the mock recorder for MockReviewRepository
+type MockReviewRepositoryMockRecorder struct {
+	mock *MockReviewRepository
+}
+
+// NewMockReviewRepository creates a new mock instance
+func NewMockReviewRepository(ctrl *gomock.Controller) *MockReviewRepository {
+	mock := &MockReviewRepository{ctrl: ctrl}
+	mock.recorder = &MockReviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReviewRepository) EXPECT() *MockReviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockReviewRepository) FindAll(filter Filter, findOptions FindOptions) (Reviews, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(Reviews)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockReviewRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockReviewRepository)(nil).FindAll), filter, findOptions)
+}
+
+// Count mocks base method
+func (m *MockReviewRepository) Count(filter Filter) (int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count
+func (mr *MockReviewRepositoryMockRecorder) Count(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockReviewRepository)(nil).Count), filter)
+}
+
+// FindOne mocks base method
+func (m *MockReviewRepository) FindOne(id string) (Review, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOne", id)
+	ret0, _ := ret[0].(Review)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindOne indicates an expected call of FindOne
+func (mr *MockReviewRepositoryMockRecorder) FindOne(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockReviewRepository)(nil).FindOne), id)
+}
+
+// Update mocks base method
+func (m *MockReviewRepository) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, condition, fields)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockReviewRepositoryMockRecorder) Update(id, condition, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReviewRepository)(nil).Update), id, condition, fields)
+}
+
+// Delete mocks base method
+func (m *MockReviewRepository) Delete(id string, condition Filter) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id, condition)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockReviewRepositoryMockRecorder) Delete(id, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReviewRepository)(nil).Delete), id, condition)
+}
+
+// Save mocks base method
+func (m *MockReviewRepository) Save(review Review) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", review)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockReviewRepositoryMockRecorder) Save(review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockReviewRepository)(nil).Save), review)
+}
+
+// MockReviewVoteRepository is a mock of ReviewVoteRepository interface
+type MockReviewVoteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewVoteRepositoryMockRecorder
+}
+
+// MockReviewVoteRepositoryMockRecorder is the mock recorder for MockReviewVoteRepository
+type MockReviewVoteRepositoryMockRecorder struct {
+	mock *MockReviewVoteRepository
+}
+
+// NewMockReviewVoteRepository creates a new mock instance
+func NewMockReviewVoteRepository(ctrl *gomock.Controller) *MockReviewVoteRepository {
+	mock := &MockReviewVoteRepository{ctrl: ctrl}
+	mock.recorder = &MockReviewVoteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReviewVoteRepository) EXPECT() *MockReviewVoteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockReviewVoteRepository) FindAll(filter Filter, findOptions FindOptions) (ReviewVotes, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(ReviewVotes)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockReviewVoteRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockReviewVoteRepository)(nil).FindAll), filter, findOptions)
+}
+
+// Count mocks base method
+func (m *MockReviewVoteRepository) Count(filter Filter) (int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count
+func (mr *MockReviewVoteRepositoryMockRecorder) Count(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockReviewVoteRepository)(nil).Count), filter)
+}
+
+// Delete mocks base method
+func (m *MockReviewVoteRepository) Delete(id string, condition Filter) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id, condition)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockReviewVoteRepositoryMockRecorder) Delete(id, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReviewVoteRepository)(nil).Delete), id, condition)
+}
+
+// Save mocks base method
+func (m *MockReviewVoteRepository) Save(vote ReviewVote) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", vote)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockReviewVoteRepositoryMockRecorder) Save(vote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockReviewVoteRepository)(nil).Save), vote)
+}
+
+// MockCopyRepository is a mock of CopyRepository interface
+type MockCopyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCopyRepositoryMockRecorder
+}
+
+// MockCopyRepositoryMockRecorder is the mock recorder for MockCopyRepository
+type MockCopyRepositoryMockRecorder struct {
+	mock *MockCopyRepository
+}
+
+// NewMockCopyRepository creates a new mock instance
+func NewMockCopyRepository(ctrl *gomock.Controller) *MockCopyRepository {
+	mock := &MockCopyRepository{ctrl: ctrl}
+	mock.recorder = &MockCopyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCopyRepository) EXPECT() *MockCopyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockCopyRepository) FindAll(filter Filter, findOptions FindOptions) (Copies, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", filter, findOptions)
+	ret0, _ := ret[0].(Copies)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockCopyRepositoryMockRecorder) FindAll(filter, findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockCopyRepository)(nil).FindAll), filter, findOptions)
+}
+
+// FindOne mocks base method
+func (m *MockCopyRepository) FindOne(id string) (Copy, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOne", id)
+	ret0, _ := ret[0].(Copy)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindOne indicates an expected call of FindOne
+func (mr *MockCopyRepositoryMockRecorder) FindOne(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockCopyRepository)(nil).FindOne), id)
+}
+
+// Update mocks base method
+func (m *MockCopyRepository) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, condition, fields)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockCopyRepositoryMockRecorder) Update(id, condition, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCopyRepository)(nil).Update), id, condition, fields)
+}
+
+// Delete mocks base method
+func (m *MockCopyRepository) Delete(id string, condition Filter) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id, condition)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockCopyRepositoryMockRecorder) Delete(id, condition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCopyRepository)(nil).Delete), id, condition)
+}
+
+// Save mocks base method
+func (m *MockCopyRepository) Save(bookCopy Copy) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", bookCopy)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save
+func (mr *MockCopyRepositoryMockRecorder) Save(bookCopy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockCopyRepository)(nil).Save), bookCopy)
+}
+
+// MockCopyService is a mock of CopyService interface
+type MockCopyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCopyServiceMockRecorder
+}
+
+// MockCopyServiceMockRecorder is the mock recorder for MockCopyService
+type MockCopyServiceMockRecorder struct {
+	mock *MockCopyService
+}
+
+// NewMockCopyService creates a new mock instance
+func NewMockCopyService(ctrl *gomock.Controller) *MockCopyService {
+	mock := &MockCopyService{ctrl: ctrl}
+	mock.recorder = &MockCopyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCopyService) EXPECT() *MockCopyServiceMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockCopyService) FindAll(bookID string) (Copies, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", bookID)
+	ret0, _ := ret[0].(Copies)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockCopyServiceMockRecorder) FindAll(bookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockCopyService)(nil).FindAll), bookID)
+}
+
+// FindOne mocks base method
+func (m *MockCopyService) FindOne(bookID, id string) (Copy, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOne", bookID, id)
+	ret0, _ := ret[0].(Copy)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindOne indicates an expected call of FindOne
+func (mr *MockCopyServiceMockRecorder) FindOne(bookID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockCopyService)(nil).FindOne), bookID, id)
+}
+
+// Update mocks base method
+func (m *MockCopyService) Update(bookID, id string, versions Versions, bookCopy Copy) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", bookID, id, versions, bookCopy)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockCopyServiceMockRecorder) Update(bookID, id, versions, bookCopy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCopyService)(nil).Update), bookID, id, versions, bookCopy)
+}
+
+// Delete mocks base method
+func (m *MockCopyService) Delete(bookID, id string, versions Versions) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", bookID, id, versions)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockCopyServiceMockRecorder) Delete(bookID, id, versions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCopyService)(nil).Delete), bookID, id, versions)
+}
+
+// Create mocks base method
+func (m *MockCopyService) Create(bookID string, bookCopy Copy) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", bookID, bookCopy)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockCopyServiceMockRecorder) Create(bookID, bookCopy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCopyService)(nil).Create), bookID, bookCopy)
+}
+
+// Availability mocks base method
+func (m *MockCopyService) Availability(bookID string) (Availability, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Availability", bookID)
+	ret0, _ := ret[0].(Availability)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Availability indicates an expected call of Availability
+func (mr *MockCopyServiceMockRecorder) Availability(bookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Availability", reflect.TypeOf((*MockCopyService)(nil).Availability), bookID)
+}
+
+// MockReviewService is a mock of ReviewService interface
+type MockReviewService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReviewServiceMockRecorder
+}
+
+// MockReviewServiceMockRecorder is the mock recorder for MockReviewService
+type MockReviewServiceMockRecorder struct {
+	mock *MockReviewService
+}
+
+// NewMockReviewService creates a new mock instance
+func NewMockReviewService(ctrl *gomock.Controller) *MockReviewService {
+	mock := &MockReviewService{ctrl: ctrl}
+	mock.recorder = &MockReviewServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReviewService) EXPECT() *MockReviewServiceMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockReviewService) FindAll(bookID string, request ReviewRequest) (Reviews, int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", bookID, request)
+	ret0, _ := ret[0].(Reviews)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(*BookAPIError)
+	return ret0, ret1, ret2
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockReviewServiceMockRecorder) FindAll(bookID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockReviewService)(nil).FindAll), bookID, request)
+}
+
+// FindOne mocks base method
+func (m *MockReviewService) FindOne(bookID, id string) (Review, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOne", bookID, id)
+	ret0, _ := ret[0].(Review)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindOne indicates an expected call of FindOne
+func (mr *MockReviewServiceMockRecorder) FindOne(bookID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockReviewService)(nil).FindOne), bookID, id)
+}
+
+// Create mocks base method
+func (m *MockReviewService) Create(bookID string, review Review) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", bookID, review)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockReviewServiceMockRecorder) Create(bookID, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReviewService)(nil).Create), bookID, review)
+}
+
+// Update mocks base method
+func (m *MockReviewService) Update(bookID, id string, review Review) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", bookID, id, review)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockReviewServiceMockRecorder) Update(bookID, id, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockReviewService)(nil).Update), bookID, id, review)
+}
+
+// Delete mocks base method
+func (m *MockReviewService) Delete(bookID, id, patronID string) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", bookID, id, patronID)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockReviewServiceMockRecorder) Delete(bookID, id, patronID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockReviewService)(nil).Delete), bookID, id, patronID)
+}
+
+// Vote mocks base method
+func (m *MockReviewService) Vote(bookID, id string, request VoteRequest) (Review, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Vote", bookID, id, request)
+	ret0, _ := ret[0].(Review)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Vote indicates an expected call of Vote
+func (mr *MockReviewServiceMockRecorder) Vote(bookID, id, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Vote", reflect.TypeOf((*MockReviewService)(nil).Vote), bookID, id, request)
+}
+
+// Queue mocks base method
+func (m *MockReviewService) Queue(request ReviewRequest) (Reviews, int64, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", request)
+	ret0, _ := ret[0].(Reviews)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(*BookAPIError)
+	return ret0, ret1, ret2
+}
+
+// Queue indicates an expected call of Queue
+func (mr *MockReviewServiceMockRecorder) Queue(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockReviewService)(nil).Queue), request)
+}
+
+// Moderate mocks base method
+func (m *MockReviewService) Moderate(id string, status ReviewStatus) (Review, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Moderate", id, status)
+	ret0, _ := ret[0].(Review)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Moderate indicates an expected call of Moderate
+func (mr *MockReviewServiceMockRecorder) Moderate(id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Moderate", reflect.TypeOf((*MockReviewService)(nil).Moderate), id, status)
+}
+
+// MockPatronService is a mock of PatronService interface
+type MockPatronService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPatronServiceMockRecorder
+}
+
+// MockPatronServiceMockRecorder is the mock recorder for MockPatronService
+type MockPatronServiceMockRecorder struct {
+	mock *MockPatronService
+}
+
+// NewMockPatronService creates a new mock instance
+func NewMockPatronService(ctrl *gomock.Controller) *MockPatronService {
+	mock := &MockPatronService{ctrl: ctrl}
+	mock.recorder = &MockPatronServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPatronService) EXPECT() *MockPatronServiceMockRecorder {
+	return m.recorder
+}
+
+// FindAll mocks base method
+func (m *MockPatronService) FindAll(findOptions FindOptions) (Patrons, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAll", findOptions)
+	ret0, _ := ret[0].(Patrons)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindAll indicates an expected call of FindAll
+func (mr *MockPatronServiceMockRecorder) FindAll(findOptions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockPatronService)(nil).FindAll), findOptions)
+}
+
+// FindOne mocks base method
+func (m *MockPatronService) FindOne(id string) (Patron, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOne", id)
+	ret0, _ := ret[0].(Patron)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// FindOne indicates an expected call of FindOne
+func (mr *MockPatronServiceMockRecorder) FindOne(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockPatronService)(nil).FindOne), id)
+}
+
+// Update mocks base method
+func (m *MockPatronService) Update(id string, patron Patron) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", id, patron)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockPatronServiceMockRecorder) Update(id, patron interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPatronService)(nil).Update), id, patron)
+}
+
+// Delete mocks base method
+func (m *MockPatronService) Delete(id string) *BookAPIError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", id)
+	ret0, _ := ret[0].(*BookAPIError)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockPatronServiceMockRecorder) Delete(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPatronService)(nil).Delete), id)
+}
+
+// Create mocks base method
+func (m *MockPatronService) Create(patron Patron) (string, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", patron)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockPatronServiceMockRecorder) Create(patron interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPatronService)(nil).Create), patron)
+}
+
+// Loans mocks base method
+func (m *MockPatronService) Loans(id string) (Loans, *BookAPIError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Loans", id)
+	ret0, _ := ret[0].(Loans)
+	ret1, _ := ret[1].(*BookAPIError)
+	return ret0, ret1
+}
+
+// Loans indicates an expected call of Loans
+func (mr *MockPatronServiceMockRecorder) Loans(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rate", reflect.TypeOf((*MockService)(nil).Rate), id, rate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Loans", reflect.TypeOf((*MockPatronService)(nil).Loans), id)
 }
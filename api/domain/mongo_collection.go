@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoCollection collection of documents stored in a MongoDB collection
+type mongoCollection struct {
+	collection *mongo.Collection
+}
+
+func (c *mongoCollection) find(filter Filter, findOptions FindOptions, results interface{}) *BookAPIError {
+	mongoFilter, filterErr := toMongoFilter(filter)
+	if filterErr != nil {
+		return NewDatabaseOperationError(filterErr.Error())
+	}
+
+	cur, colErr := c.collection.Find(nil, mongoFilter, toMongoFindOptions(findOptions))
+	if colErr != nil {
+		return NewDatabaseOperationError(colErr.Error())
+	}
+	defer cur.Close(nil)
+
+	docs := []bson.M{}
+	for cur.Next(nil) {
+		doc := bson.M{}
+		if decodeErr := cur.Decode(&doc); decodeErr != nil {
+			return NewDatabaseOperationError(decodeErr.Error())
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if curErr := cur.Err(); curErr != nil {
+		return NewDatabaseOperationError(curErr.Error())
+	}
+
+	if decodeErr := decodeDocuments(docs, results); decodeErr != nil {
+		return NewDatabaseOperationError(decodeErr.Error())
+	}
+
+	return nil
+}
+
+func (c *mongoCollection) count(filter Filter) (int64, *BookAPIError) {
+	mongoFilter, filterErr := toMongoFilter(filter)
+	if filterErr != nil {
+		return 0, NewDatabaseOperationError(filterErr.Error())
+	}
+
+	count, countErr := c.collection.CountDocuments(nil, mongoFilter)
+	if countErr != nil {
+		return 0, NewDatabaseOperationError(countErr.Error())
+	}
+
+	return count, nil
+}
+
+func (c *mongoCollection) insert(entity interface{}) (string, *BookAPIError) {
+	doc, id, err := newDocument(entity)
+	if err != nil {
+		return "", NewPersistError(err.Error())
+	}
+
+	if _, insertErr := c.collection.InsertOne(nil, doc); insertErr != nil {
+		if isDuplicateKey(insertErr) {
+			return "", NewDuplicateKeyError(insertErr.Error())
+		}
+		return "", NewPersistError(insertErr.Error())
+	}
+
+	return id, nil
+}
+
+func (c *mongoCollection) update(id string, condition Filter, fields Fields) (bool, *BookAPIError) {
+	filter, filterErr := toMongoFilter(AllOf(idFilter(id), condition))
+	if filterErr != nil {
+		return false, NewDatabaseOperationError(filterErr.Error())
+	}
+
+	set := bson.M{}
+	for field, value := range fields {
+		if field != "_id" {
+			set[field] = value
+		}
+	}
+
+	result, updateErr := c.collection.UpdateOne(nil, filter, bson.M{"$set": set})
+	if updateErr != nil {
+		if isDuplicateKey(updateErr) {
+			return false, NewDuplicateKeyError(updateErr.Error())
+		}
+		return false, NewDatabaseOperationError(updateErr.Error())
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (c *mongoCollection) delete(id string, condition Filter) (bool, *BookAPIError) {
+	filter, filterErr := toMongoFilter(AllOf(idFilter(id), condition))
+	if filterErr != nil {
+		return false, NewDatabaseOperationError(filterErr.Error())
+	}
+
+	result, deleteErr := c.collection.DeleteOne(nil, filter)
+	if deleteErr != nil {
+		return false, NewDatabaseOperationError(deleteErr.Error())
+	}
+
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
+	"strings"
+)
+
+// mongoOperators maps query operators to their MongoDB equivalent
+var mongoOperators = map[QueryOperator]string{
+	Equals:             "$eq",
+	LessThan:           "$lt",
+	GreaterThan:        "$gt",
+	GreaterThanOrEqual: "$gte",
+	LessThanOrEqual:    "$lte",
+	DoesNotEqual:       "$ne",
+	In:                 "$in",
+}
+
+// toMongoFilter translates the filter expression tree into a MongoDB query document
+func toMongoFilter(filter Filter) (bson.M, error) {
+	if filter.IsEmpty() {
+		return bson.M{}, nil
+	}
+
+	if filter.Query != nil {
+		return toMongoCondition(*filter.Query)
+	}
+
+	children := bson.A{}
+	for _, child := range filter.Children {
+		doc, err := toMongoFilter(child)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, doc)
+	}
+
+	switch filter.Operator {
+	case And:
+		return bson.M{"$and": children}, nil
+	case Or:
+		return bson.M{"$or": children}, nil
+	case Not:
+		return bson.M{"$nor": children}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported logical operator %s", filter.Operator)
+}
+
+func toMongoCondition(query Query) (bson.M, error) {
+	switch query.Operator {
+	case StartsWith:
+		pattern := "^" + regexp.QuoteMeta(fmt.Sprint(query.Value))
+		return bson.M{query.Field: bson.M{"$regex": pattern, "$options": "i"}}, nil
+	case Contains:
+		pattern := regexp.QuoteMeta(fmt.Sprint(query.Value))
+		return bson.M{query.Field: bson.M{"$regex": pattern, "$options": "i"}}, nil
+	}
+
+	operator, ok := mongoOperators[query.Operator]
+	if !ok {
+		return nil, fmt.Errorf("unsupported query operator %s", query.Operator)
+	}
+
+	return bson.M{query.Field: bson.M{operator: query.Value}}, nil
+}
+
+// toMongoFindOptions translates the find options into MongoDB Collection options
+func toMongoFindOptions(findOptions FindOptions) *options.FindOptions {
+	mongoOptions := options.Find().SetSkip(findOptions.Skip)
+	if findOptions.Limit > 0 {
+		mongoOptions = mongoOptions.SetLimit(findOptions.Limit)
+	}
+
+	if len(findOptions.Sort) > 0 {
+		sort := bson.D{}
+		for _, field := range findOptions.Sort {
+			sort = append(sort, bson.E{Key: field.Field, Value: int(field.Order)})
+		}
+		mongoOptions = mongoOptions.SetSort(sort)
+	}
+
+	return mongoOptions
+}
+
+// toMongoUpdate translates the fields into a MongoDB $set update document incrementing the version
+func toMongoUpdate(fields Fields) bson.D {
+	set := bson.M{}
+	for field, value := range fields {
+		if field != "_id" && field != "version" {
+			set[field] = value
+		}
+	}
+
+	update := bson.D{{Key: "$inc", Value: bson.M{"version": 1}}}
+	if len(set) > 0 {
+		update = append(bson.D{{Key: "$set", Value: set}}, update...)
+	}
+
+	return update
+}
+
+// toMongoConditionalFilter translates the condition on the Book with the specified ID into a MongoDB filter
+func toMongoConditionalFilter(id string, condition Filter) (bson.M, error) {
+	objectID, _ := primitive.ObjectIDFromHex(id)
+	return toMongoFilter(AllOf(NewQueryFilter("_id", Equals, objectID), condition))
+}
+
+// toMongoTextSearch translates the search text into a MongoDB $text filter and the options
+// selecting the page of the most relevant documents, projecting their relevance into score
+func toMongoTextSearch(text string, findOptions FindOptions) (bson.M, *options.FindOptions) {
+	// terms only, so that - or quotes in the text are not read as negations or phrases
+	filter := bson.M{"$text": bson.M{"$search": strings.Join(Tokenize(text), " ")}}
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+
+	mongoOptions := toMongoFindOptions(FindOptions{Limit: findOptions.Limit, Skip: findOptions.Skip})
+	return filter, mongoOptions.SetProjection(score).SetSort(score)
+}
+
+// mongoTextIndex text index of the searchable Book fields, weighted as the in-process search index.
+// The language none disables stemming and stop words, matching the in-process tokenization
+func mongoTextIndex() mongo.IndexModel {
+	keys := bson.D{}
+	weights := bson.M{}
+	for _, field := range []string{"title", "author", "publisher"} {
+		keys = append(keys, bson.E{Key: field, Value: "text"})
+		weights[field] = int(searchFieldWeights[field])
+	}
+
+	return mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName("books_text").SetWeights(weights).SetDefaultLanguage("none"),
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestMongoQuery_ToMongoFilter(t *testing.T) {
+	filter := AllOf(
+		NewQueryFilter("rating", GreaterThanOrEqual, int64(2)),
+		Negate(NewQueryFilter("status", In, []interface{}{int64(1)})),
+		AnyOf(NewQueryFilter("title", StartsWith, "C++"), NewQueryFilter("author", DoesNotEqual, "Paulo Coelho")),
+	)
+
+	doc, err := toMongoFilter(filter)
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{"$and": bson.A{
+		bson.M{"rating": bson.M{"$gte": int64(2)}},
+		bson.M{"$nor": bson.A{bson.M{"status": bson.M{"$in": []interface{}{int64(1)}}}}},
+		bson.M{"$or": bson.A{
+			bson.M{"title": bson.M{"$regex": `^C\+\+`, "$options": "i"}},
+			bson.M{"author": bson.M{"$ne": "Paulo Coelho"}},
+		}},
+	}}, doc)
+
+	empty, _ := toMongoFilter(Filter{})
+	assert.Equal(t, bson.M{}, empty)
+
+	_, err = toMongoFilter(NewQueryFilter("rating", "~", 1))
+	assert.NotNil(t, err)
+}
+
+func TestMongoQuery_ToMongoTextSearch(t *testing.T) {
+	filter, findOptions := toMongoTextSearch(`"Clean" -Código`, FindOptions{Limit: 5, Skip: 10, Sort: []Sort{{Field: "rating", Order: DESC}}})
+
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+	assert.Equal(t, bson.M{"$text": bson.M{"$search": "clean codigo"}}, filter)
+	assert.Equal(t, int64(5), *findOptions.Limit)
+	assert.Equal(t, int64(10), *findOptions.Skip)
+	assert.Equal(t, score, findOptions.Sort)
+	assert.Equal(t, score, findOptions.Projection)
+}
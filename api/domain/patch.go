@@ -0,0 +1,378 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// mergePatchMediaType media type of the RFC 7386 JSON merge patch bodies
+	mergePatchMediaType = "application/merge-patch+json"
+	// jsonPatchMediaType media type of the RFC 6902 JSON patch bodies
+	jsonPatchMediaType = "application/json-patch+json"
+)
+
+// BookPatch changes to the fields of a Book
+type BookPatch interface {
+	// Apply returns the Book with the changes applied, the Book itself is left untouched
+	Apply(book Book) (Book, *BookAPIError)
+}
+
+// MergePatch RFC 7386 JSON merge patch, i.e. {"rating": 2, "publisher": null}.
+// Fields present replace the Book fields, null fields reset them to their zero value
+type MergePatch []byte
+
+// JSONPatch RFC 6902 JSON patch, a list of add, remove, replace, move, copy and test operations
+// on the Book fields, i.e. [{"op": "test", "path": "/rating", "value": 1}, {"op": "replace", "path": "/rating", "value": 2}]
+type JSONPatch []byte
+
+// patchOperation a single operation of a JSON patch
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply merges the patch into the Book.
+// It returns a Malformed Body error if the patch is not a JSON object or the merged Book has unknown or mistyped fields
+func (patch MergePatch) Apply(book Book) (Book, *BookAPIError) {
+	var changes interface{}
+	if err := decodeStrict(patch, &changes); err != nil {
+		return Book{}, err
+	}
+
+	if _, ok := changes.(map[string]interface{}); !ok {
+		return Book{}, NewMalformedBodyError("Merge patch must be a JSON object")
+	}
+
+	doc, err := bookDocument(book)
+	if err != nil {
+		return Book{}, err
+	}
+
+	return decodeBook(mergeValue(doc, changes))
+}
+
+// Apply applies the operations in order to the Book, all of them or none.
+// It returns a Malformed Body error if an operation is malformed and a Patch Conflict error
+// if a path does not exist in the Book or a test operation fails
+func (patch JSONPatch) Apply(book Book) (Book, *BookAPIError) {
+	var operations []patchOperation
+	if err := decodeStrict(patch, &operations); err != nil {
+		return Book{}, err
+	}
+
+	doc, err := bookDocument(book)
+	if err != nil {
+		return Book{}, err
+	}
+
+	for i, operation := range operations {
+		if doc, err = operation.apply(doc); err != nil {
+			err.msg = fmt.Sprintf("Operation %d: %s", i, err.msg)
+			return Book{}, err
+		}
+	}
+
+	return decodeBook(doc)
+}
+
+func (operation patchOperation) apply(doc interface{}) (interface{}, *BookAPIError) {
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if len(operation.Value) == 0 {
+			return nil, NewMalformedBodyError(fmt.Sprintf("%s needs a value", operation.Op))
+		}
+
+		var value interface{}
+		if unmarshalErr := json.Unmarshal(operation.Value, &value); unmarshalErr != nil {
+			return nil, NewMalformedBodyError(fmt.Sprintf("%s has a malformed value", operation.Op))
+		}
+
+		switch operation.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			return replaceValue(doc, path, value)
+		}
+
+		current, getErr := getValue(doc, path)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, NewPatchConflictError(fmt.Sprintf("test failed, %s is %s", operation.Path, toJSON(current)))
+		}
+		return doc, nil
+
+	case "remove":
+		return removeValue(doc, path)
+
+	case "move", "copy":
+		from, fromErr := parsePointer(operation.From)
+		if fromErr != nil {
+			return nil, fromErr
+		}
+
+		value, getErr := getValue(doc, from)
+		if getErr != nil {
+			return nil, getErr
+		}
+
+		if operation.Op == "copy" {
+			return addValue(doc, path, copyValue(value))
+		}
+
+		if strings.HasPrefix(operation.Path+"/", operation.From+"/") && operation.Path != operation.From {
+			return nil, NewMalformedBodyError(fmt.Sprintf("cannot move %s into itself", operation.From))
+		}
+
+		if doc, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	}
+
+	return nil, NewMalformedBodyError(fmt.Sprintf("unknown op %q", operation.Op))
+}
+
+// bookDocument returns the JSON document of the Book as decoded into an interface{}
+func bookDocument(book Book) (interface{}, *BookAPIError) {
+	data, err := json.Marshal(book)
+	if err != nil {
+		return nil, NewMalformedBodyError(err.Error())
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, NewMalformedBodyError(err.Error())
+	}
+
+	return doc, nil
+}
+
+// decodeBook strictly decodes the patched JSON document into a Book
+func decodeBook(doc interface{}) (Book, *BookAPIError) {
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return Book{}, NewMalformedBodyError("Patched Book must be a JSON object")
+	}
+
+	var book Book
+	if err := decodeStrict([]byte(toJSON(doc)), &book); err != nil {
+		return Book{}, err
+	}
+
+	return book, nil
+}
+
+// mergeValue returns the target with the RFC 7386 merge patch applied
+func mergeValue(target interface{}, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	merged, ok := target.(map[string]interface{})
+	if !ok {
+		merged = map[string]interface{}{}
+	}
+
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+		} else {
+			merged[key] = mergeValue(merged[key], value)
+		}
+	}
+
+	return merged
+}
+
+// parsePointer splits the RFC 6901 JSON pointer into its unescaped reference tokens, none for the whole document
+func parsePointer(pointer string) ([]string, *BookAPIError) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, NewMalformedBodyError(fmt.Sprintf("path %q must start with /", pointer))
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	return tokens, nil
+}
+
+func getValue(doc interface{}, path []string) (interface{}, *BookAPIError) {
+	for i := range path {
+		child, err := childValue(doc, path[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		doc = child
+	}
+
+	return doc, nil
+}
+
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, *BookAPIError) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(doc, path, func(parent interface{}) (interface{}, *BookAPIError) {
+		key := path[len(path)-1]
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index := len(node)
+			if key != "-" {
+				var err *BookAPIError
+				if index, err = arrayIndex(path, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			return append(node[:index], append([]interface{}{value}, node[index:]...)...), nil
+		}
+
+		return nil, notFoundPath(path[:len(path)-1])
+	})
+}
+
+func removeValue(doc interface{}, path []string) (interface{}, *BookAPIError) {
+	if len(path) == 0 {
+		return nil, NewMalformedBodyError("cannot remove the whole Book")
+	}
+
+	if _, err := getValue(doc, path); err != nil {
+		return nil, err
+	}
+
+	return updateParent(doc, path, func(parent interface{}) (interface{}, *BookAPIError) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			delete(node, path[len(path)-1])
+			return node, nil
+		case []interface{}:
+			index, _ := arrayIndex(path, len(node)-1)
+			return append(node[:index], node[index+1:]...), nil
+		}
+
+		return nil, notFoundPath(path)
+	})
+}
+
+func replaceValue(doc interface{}, path []string, value interface{}) (interface{}, *BookAPIError) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	if _, err := getValue(doc, path); err != nil {
+		return nil, err
+	}
+
+	return updateParent(doc, path, func(parent interface{}) (interface{}, *BookAPIError) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[path[len(path)-1]] = value
+			return node, nil
+		case []interface{}:
+			index, _ := arrayIndex(path, len(node)-1)
+			node[index] = value
+			return node, nil
+		}
+
+		return nil, notFoundPath(path)
+	})
+}
+
+// updateParent replaces the parent of the path value with the result of update, returning the updated document
+func updateParent(doc interface{}, path []string, update func(parent interface{}) (interface{}, *BookAPIError)) (interface{}, *BookAPIError) {
+	if len(path) == 1 {
+		return update(doc)
+	}
+
+	child, err := childValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := updateParent(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = updated
+	case []interface{}:
+		index, _ := arrayIndex(path[:1], len(node)-1)
+		node[index] = updated
+	}
+
+	return doc, nil
+}
+
+// childValue returns the member or element of the document named by the last token of the path
+func childValue(doc interface{}, path []string) (interface{}, *BookAPIError) {
+	key := path[len(path)-1]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if child, ok := node[key]; ok {
+			return child, nil
+		}
+	case []interface{}:
+		index, err := arrayIndex(path, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+		return node[index], nil
+	}
+
+	return nil, notFoundPath(path)
+}
+
+// arrayIndex parses the last token of the path as an index of the array no greater than max
+func arrayIndex(path []string, max int) (int, *BookAPIError) {
+	key := path[len(path)-1]
+	index, err := strconv.Atoi(key)
+	if err != nil || index < 0 || index > max || (len(key) > 1 && key[0] == '0') {
+		return 0, notFoundPath(path)
+	}
+
+	return index, nil
+}
+
+func notFoundPath(path []string) *BookAPIError {
+	tokens := make([]string, len(path))
+	for i, token := range path {
+		tokens[i] = strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+	}
+
+	return NewPatchConflictError(fmt.Sprintf("path /%s does not exist", strings.Join(tokens, "/")))
+}
+
+func copyValue(value interface{}) interface{} {
+	var copied interface{}
+	json.Unmarshal([]byte(toJSON(value)), &copied)
+	return copied
+}
+
+func toJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
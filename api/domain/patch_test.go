@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func newPatchBook() Book {
+	id, _ := primitive.ObjectIDFromHex("5ca7c76f9287bd3832d96f15")
+	return Book{
+		ID:          id,
+		Author:      "Robert Martin",
+		Title:       "Clean Code",
+		Publisher:   "Prentice Hall",
+		Status:      CheckedIn,
+		Rating:      2,
+		PublishDate: "2008",
+	}
+}
+
+func TestMergePatch_Apply(t *testing.T) {
+	book := newPatchBook()
+
+	patched, err := MergePatch(`{"rating": 3, "publisher": null}`).Apply(book)
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, patched.Rating)
+	assert.Equal(t, "", patched.Publisher)
+	assert.Equal(t, book.Title, patched.Title)
+	assert.Equal(t, book.ID, patched.ID)
+	assert.Equal(t, 2.0, book.Rating, "the Book itself is left untouched")
+}
+
+func TestMergePatch_Apply_WithInvalidPatches(t *testing.T) {
+	cases := []struct {
+		name   string
+		patch  string
+		fields []FieldError
+	}{
+		{"malformed", `{"rating": `, nil},
+		{"not an object", `[{"rating": 3}]`, nil},
+		{"unknown field", `{"name": "Clean Code"}`, []FieldError{{Field: "name", Rule: "unknown", Message: "name is not a Book field"}}},
+		{"mistyped field", `{"rating": "high"}`, []FieldError{{Field: "rating", Rule: "type", Message: "rating must be a number"}}},
+	}
+
+	for _, c := range cases {
+		_, err := MergePatch(c.patch).Apply(newPatchBook())
+		assert.NotNil(t, err, c.name)
+		assert.Equal(t, MalformedBody, err.errorType, c.name)
+		assert.Equal(t, c.fields, err.fields, c.name)
+	}
+}
+
+func TestJSONPatch_Apply(t *testing.T) {
+	patched, err := JSONPatch(`[
+		{"op": "test", "path": "/rating", "value": 2},
+		{"op": "replace", "path": "/rating", "value": 3},
+		{"op": "copy", "from": "/author", "path": "/publisher"},
+		{"op": "move", "from": "/publish_date", "path": "/publish_date"},
+		{"op": "remove", "path": "/status"},
+		{"op": "add", "path": "/title", "value": "Clean Coder"}
+	]`).Apply(newPatchBook())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, patched.Rating)
+	assert.Equal(t, "Robert Martin", patched.Publisher)
+	assert.Equal(t, "2008", patched.PublishDate)
+	assert.Equal(t, Status(0), patched.Status)
+	assert.Equal(t, "Clean Coder", patched.Title)
+}
+
+func TestJSONPatch_Apply_WithInvalidPatches(t *testing.T) {
+	cases := []struct {
+		name      string
+		patch     string
+		errorType OperationError
+	}{
+		{"not a list", `{"op": "remove", "path": "/rating"}`, MalformedBody},
+		{"unknown op", `[{"op": "increment", "path": "/rating"}]`, MalformedBody},
+		{"missing value", `[{"op": "replace", "path": "/rating"}]`, MalformedBody},
+		{"relative path", `[{"op": "remove", "path": "rating"}]`, MalformedBody},
+		{"whole Book removed", `[{"op": "remove", "path": ""}]`, MalformedBody},
+		{"unknown field added", `[{"op": "add", "path": "/name", "value": "Clean Code"}]`, MalformedBody},
+		{"missing path", `[{"op": "replace", "path": "/name", "value": "Clean Code"}]`, PatchConflict},
+		{"missing from", `[{"op": "move", "from": "/name", "path": "/title"}]`, PatchConflict},
+		{"failed test", `[{"op": "test", "path": "/rating", "value": 5}, {"op": "replace", "path": "/rating", "value": 3}]`, PatchConflict},
+	}
+
+	for _, c := range cases {
+		_, err := JSONPatch(c.patch).Apply(newPatchBook())
+		assert.NotNil(t, err, c.name)
+		assert.Equal(t, c.errorType, err.errorType, c.name)
+	}
+}
+
+func TestJSONPatch_Apply_Nested(t *testing.T) {
+	doc := map[string]interface{}{"a/b": []interface{}{"x", "z"}, "c~d": map[string]interface{}{}}
+	path, _ := parsePointer("/a~1b/1")
+	patched, err := addValue(doc, path, "y")
+	assert.Nil(t, err)
+
+	path, _ = parsePointer("/c~0d/e")
+	patched, err = addValue(patched, path, "f")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"a/b": []interface{}{"x", "y", "z"}, "c~d": map[string]interface{}{"e": "f"}}, patched)
+
+	path, _ = parsePointer("/a~1b/01")
+	_, err = removeValue(patched, path)
+	assert.NotNil(t, err)
+	assert.Equal(t, "path /a~1b/01 does not exist", err.Error())
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/temesxgn/redeam/api/utils"
+	"net/http"
+)
+
+// A PatronController - action handler for Patron API
+type PatronController struct {
+	service PatronService
+}
+
+// GetAll handles REST API Get '/patrons' Endpoint, the Patrons by name
+func (c *PatronController) GetAll(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+
+	patrons, err := c.service.FindAll(FindOptions{})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(patrons)
+	responseBuilder.OK(w, data)
+}
+
+// GetByID handles REST API Get '/patrons/{id}' Endpoint
+func (c *PatronController) GetByID(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	patron, err := c.service.FindOne(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(patron)
+	responseBuilder.OK(w, data)
+}
+
+// Create handles REST API POST '/patrons' Endpoint
+func (c *PatronController) Create(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+
+	var patron Patron
+	if err := decodeJSON(r, &patron); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	id, createError := c.service.Create(patron)
+	if createError != nil {
+		writeError(w, r, createError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(id))
+}
+
+// Update handles REST API PUT '/patrons/{id}' Endpoint
+func (c *PatronController) Update(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	var patron Patron
+	if err := decodeJSON(r, &patron); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if updateError := c.service.Update(id, patron); updateError != nil {
+		writeError(w, r, updateError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(""))
+}
+
+// Delete handles REST API DELETE '/patrons/{id}' Endpoint
+func (c *PatronController) Delete(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	if deleteError := c.service.Delete(id); deleteError != nil {
+		writeError(w, r, deleteError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(""))
+}
+
+// Loans handles REST API Get '/patrons/{id}/loans' Endpoint, the loan history of the Patron, newest first
+func (c *PatronController) Loans(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	loans, err := c.service.Loans(id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(loans)
+	responseBuilder.OK(w, data)
+}
+
+// NewPatronController Creates PatronController instance
+func NewPatronController(service PatronService) *PatronController {
+	return &PatronController{service: service}
+}
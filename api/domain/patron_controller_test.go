@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newPatronServer(service PatronService) *httptest.Server {
+	patronController := NewPatronController(service)
+	router := chi.NewRouter()
+	router.Get("/patrons", patronController.GetAll)
+	router.Post("/patrons", patronController.Create)
+	router.Get("/patrons/{id}", patronController.GetByID)
+	router.Delete("/patrons/{id}", patronController.Delete)
+	router.Get("/patrons/{id}/loans", patronController.Loans)
+
+	return httptest.NewServer(router)
+}
+
+func TestPatronController_Create(t *testing.T) {
+	patronService := NewMockPatronService(gomock.NewController(t))
+	patronService.EXPECT().Create(Patron{Name: "Ada Lovelace", Email: "ada@example.com"}).Return("1", nil)
+
+	server := newPatronServer(patronService)
+	defer server.Close()
+
+	res, _ := http.Post(server.URL+"/patrons", ContentType, strings.NewReader(`{"name": "Ada Lovelace", "email": "ada@example.com"}`))
+	defer closeBody(res.Body)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "1", string(body))
+
+	malformed, _ := http.Post(server.URL+"/patrons", ContentType, strings.NewReader(`{"name": 1}`))
+	defer closeBody(malformed.Body)
+	assert.Equal(t, http.StatusBadRequest, malformed.StatusCode)
+}
+
+func TestPatronController_GetAllAndGetByID(t *testing.T) {
+	patron := Patron{ID: primitive.NewObjectID(), Name: "Ada Lovelace", Email: "ada@example.com"}
+	patronService := NewMockPatronService(gomock.NewController(t))
+	patronService.EXPECT().FindAll(FindOptions{}).Return(Patrons{patron}, nil)
+	patronService.EXPECT().FindOne(patron.ID.Hex()).Return(patron, nil)
+
+	server := newPatronServer(patronService)
+	defer server.Close()
+
+	res, _ := http.Get(server.URL + "/patrons")
+	defer closeBody(res.Body)
+	var patrons Patrons
+	_ = json.NewDecoder(res.Body).Decode(&patrons)
+	assert.Equal(t, Patrons{patron}, patrons)
+
+	one, _ := http.Get(fmt.Sprintf("%s/patrons/%s", server.URL, patron.ID.Hex()))
+	defer closeBody(one.Body)
+	var found Patron
+	_ = json.NewDecoder(one.Body).Decode(&found)
+	assert.Equal(t, patron, found)
+}
+
+func TestPatronController_Delete_WithOpenLoans(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	patronService := NewMockPatronService(gomock.NewController(t))
+	patronService.EXPECT().Delete(id).Return(NewOpenLoansError(id))
+
+	server := newPatronServer(patronService)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/patrons/%s", server.URL, id), nil)
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var problem Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+	assert.Equal(t, OpenLoans, problem.Code)
+}
+
+func TestPatronController_Loans_WithNotFound(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	patronService := NewMockPatronService(gomock.NewController(t))
+	patronService.EXPECT().Loans(id).Return(nil, NewPatronNotFoundError(id))
+
+	server := newPatronServer(patronService)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/patrons/%s/loans", server.URL, id))
+	defer closeBody(res.Body)
+
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
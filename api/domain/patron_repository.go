@@ -0,0 +1,78 @@
+package domain
+
+type patronRepo struct {
+	collection collection
+}
+
+// FindAll Queries the patrons collection with optional filters and find options
+// It returns a list of Patrons or an API Error Response
+func (r *patronRepo) FindAll(filter Filter, findOptions FindOptions) (Patrons, *BookAPIError) {
+	patrons := Patrons{}
+	if err := r.collection.find(filter, findOptions, &patrons); err != nil {
+		return nil, err
+	}
+
+	return patrons, nil
+}
+
+// FindOne Queries the patrons collection for a specific Patron
+// It returns one Patron or an API Error Response
+func (r *patronRepo) FindOne(id string) (Patron, *BookAPIError) {
+	patrons, err := r.FindAll(idFilter(id), FindOptions{Limit: 1})
+	if err != nil {
+		return Patron{}, err
+	}
+
+	if len(patrons) == 0 {
+		return Patron{}, NewPatronNotFoundError(id)
+	}
+
+	return patrons[0], nil
+}
+
+// Update sets the fields of the Patron with the specified ID
+// It returns an API Error Response if failed
+func (r *patronRepo) Update(id string, fields Fields) *BookAPIError {
+	updated, err := r.collection.update(id, Filter{}, fields)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return NewPatronNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Delete Hard deletes the Patron with the specified ID
+// It returns an API Error Response if failed
+func (r *patronRepo) Delete(id string) *BookAPIError {
+	deleted, err := r.collection.delete(id, Filter{})
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return NewPatronNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Save Saves the Patron Payload, generating an ID when it has none
+// It returns the persisted Patron ID or an API Error Response if failed
+func (r *patronRepo) Save(patron Patron) (string, *BookAPIError) {
+	return r.collection.insert(patron)
+}
+
+// NewPatronRepository Initializes the patrons repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewPatronRepository() (PatronRepository, *BookAPIError) {
+	collection, err := newCollection("patrons")
+	if err != nil {
+		return nil, err
+	}
+
+	return &patronRepo{collection: collection}, nil
+}
@@ -0,0 +1,104 @@
+package domain
+
+type patronService struct {
+	patrons PatronRepository
+	loans   LoanRepository
+}
+
+func (s *patronService) FindAll(options FindOptions) (Patrons, *BookAPIError) {
+	if len(options.Sort) == 0 {
+		options.Sort = []Sort{{Field: "name", Order: ASC}}
+	}
+
+	return s.patrons.FindAll(Filter{}, options)
+}
+
+func (s *patronService) FindOne(id string) (Patron, *BookAPIError) {
+	return s.patrons.FindOne(id)
+}
+
+func (s *patronService) Create(patron Patron) (string, *BookAPIError) {
+	if validationError := patron.Validate(); validationError != nil {
+		return "", validationError
+	}
+
+	if err := s.checkEmail(patron.Email, ""); err != nil {
+		return "", err
+	}
+
+	id, err := s.patrons.Save(patron)
+	if err != nil && err.errorType == ExistingRecord {
+		// created with the same email since it was checked
+		return "", NewPatronAlreadyExistsError(patron.Email)
+	}
+
+	return id, err
+}
+
+func (s *patronService) Update(id string, patron Patron) *BookAPIError {
+	if validationError := patron.Validate(); validationError != nil {
+		return validationError
+	}
+
+	if _, err := s.patrons.FindOne(id); err != nil {
+		return err
+	}
+
+	if err := s.checkEmail(patron.Email, id); err != nil {
+		return err
+	}
+
+	err := s.patrons.Update(id, Fields{"name": patron.Name, "email": patron.Email})
+	if err != nil && err.errorType == ExistingRecord {
+		return NewPatronAlreadyExistsError(patron.Email)
+	}
+
+	return err
+}
+
+func (s *patronService) Delete(id string) *BookAPIError {
+	if _, err := s.patrons.FindOne(id); err != nil {
+		return err
+	}
+
+	open := AllOf(NewQueryFilter("patron_id", Equals, id), NewQueryFilter("returned_at", Equals, nil))
+	loans, err := s.loans.FindAll(open, FindOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	if len(loans) > 0 {
+		return NewOpenLoansError(id)
+	}
+
+	return s.patrons.Delete(id)
+}
+
+func (s *patronService) Loans(id string) (Loans, *BookAPIError) {
+	if _, err := s.patrons.FindOne(id); err != nil {
+		return nil, err
+	}
+
+	return s.loans.FindAll(NewQueryFilter("patron_id", Equals, id), newestLoansFirst)
+}
+
+// checkEmail returns an Already Exists error if a Patron other than the one with the ID has the email
+func (s *patronService) checkEmail(email string, id string) *BookAPIError {
+	patrons, err := s.patrons.FindAll(NewQueryFilter("email", Equals, email), FindOptions{Limit: 2})
+	if err != nil {
+		return err
+	}
+
+	for _, patron := range patrons {
+		if patron.ID.Hex() != id {
+			return NewPatronAlreadyExistsError(email)
+		}
+	}
+
+	return nil
+}
+
+// NewPatronService creates instance of the Patron service
+func NewPatronService(patrons PatronRepository, loans LoanRepository) PatronService {
+	return &patronService{patrons: patrons, loans: loans}
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestPatronService_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	patronRepo := NewMockPatronRepository(ctrl)
+	patronService := NewPatronService(patronRepo, NewMockLoanRepository(ctrl))
+	patron := Patron{Name: "Ada Lovelace", Email: "ada@example.com"}
+
+	patronRepo.EXPECT().FindAll(NewQueryFilter("email", Equals, patron.Email), gomock.Any()).Return(Patrons{}, nil)
+	patronRepo.EXPECT().Save(patron).Return("1", nil)
+	id, err := patronService.Create(patron)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", id)
+
+	patronRepo.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(Patrons{{ID: primitive.NewObjectID()}}, nil)
+	_, err = patronService.Create(patron)
+	assert.Equal(t, ExistingRecord, err.errorType)
+
+	// created with the same email between the check and the save
+	patronRepo.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(Patrons{}, nil)
+	patronRepo.EXPECT().Save(patron).Return("", NewDuplicateKeyError("E11000 duplicate key error"))
+	_, err = patronService.Create(patron)
+	assert.Equal(t, NewPatronAlreadyExistsError(patron.Email), err)
+
+	_, err = patronService.Create(Patron{Name: "Ada Lovelace", Email: "ada"})
+	assert.Equal(t, []FieldError{{Field: "email", Rule: "email", Message: "must be in a valid format"}}, err.fields)
+}
+
+func TestPatronService_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	patronRepo := NewMockPatronRepository(ctrl)
+	patronService := NewPatronService(patronRepo, NewMockLoanRepository(ctrl))
+	patron := Patron{ID: primitive.NewObjectID(), Name: "Ada Lovelace", Email: "ada@example.com"}
+	id := patron.ID.Hex()
+
+	// keeping its own email is no conflict
+	patronRepo.EXPECT().FindOne(id).Return(patron, nil)
+	patronRepo.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(Patrons{patron}, nil)
+	patronRepo.EXPECT().Update(id, Fields{"name": "Augusta Ada King", "email": patron.Email}).Return(nil)
+	assert.Nil(t, patronService.Update(id, Patron{Name: "Augusta Ada King", Email: patron.Email}))
+
+	patronRepo.EXPECT().FindOne(id).Return(Patron{}, NewPatronNotFoundError(id))
+	assert.Equal(t, NotFoundError, patronService.Update(id, patron).errorType)
+}
+
+func TestPatronService_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	patronRepo, loanRepo := NewMockPatronRepository(ctrl), NewMockLoanRepository(ctrl)
+	patronService := NewPatronService(patronRepo, loanRepo)
+	id := primitive.NewObjectID().Hex()
+	open := AllOf(NewQueryFilter("patron_id", Equals, id), NewQueryFilter("returned_at", Equals, nil))
+
+	patronRepo.EXPECT().FindOne(id).Return(Patron{}, nil).Times(2)
+	loanRepo.EXPECT().FindAll(open, FindOptions{Limit: 1}).Return(Loans{{BookID: "1"}}, nil)
+	err := patronService.Delete(id)
+	assert.NotNil(t, err)
+	assert.Equal(t, OpenLoans, err.errorType)
+
+	loanRepo.EXPECT().FindAll(open, FindOptions{Limit: 1}).Return(Loans{}, nil)
+	patronRepo.EXPECT().Delete(id).Return(nil)
+	assert.Nil(t, patronService.Delete(id))
+}
+
+func TestPatronService_Loans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	patronRepo, loanRepo := NewMockPatronRepository(ctrl), NewMockLoanRepository(ctrl)
+	patronService := NewPatronService(patronRepo, loanRepo)
+	id := primitive.NewObjectID().Hex()
+	loans := Loans{{ID: primitive.NewObjectID(), PatronID: id}}
+
+	patronRepo.EXPECT().FindOne(id).Return(Patron{}, nil)
+	loanRepo.EXPECT().FindAll(NewQueryFilter("patron_id", Equals, id), newestLoansFirst).Return(loans, nil)
+	history, err := patronService.Loans(id)
+	assert.Nil(t, err)
+	assert.Equal(t, loans, history)
+
+	patronRepo.EXPECT().FindOne(id).Return(Patron{}, NewPatronNotFoundError(id))
+	_, err = patronService.Loans(id)
+	assert.Equal(t, NotFoundError, err.errorType)
+}
@@ -0,0 +1,518 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// QueryBuilder - helper methods to translate request query params into repository queries
+type QueryBuilder struct{}
+
+const (
+	defaultSize  = 10
+	defaultPage  = 1
+	defaultOrder = ASC
+	defaultSort  = "_id"
+)
+
+// reservedParams query params that are not a condition on a Book field
+var reservedParams = map[string]bool{
+	"page":   true,
+	"size":   true,
+	"filter": true,
+	"sort":   true,
+	"after":  true,
+	"before": true,
+	"fuzzy":  true,
+}
+
+// sortableFields Book fields, by bson name, that results may be sorted on
+var sortableFields = map[string]bool{
+	"_id":          true,
+	"author":       true,
+	"title":        true,
+	"publisher":    true,
+	"status":       true,
+	"rating":       true,
+	"publish_date": true,
+	// rating aggregates
+	"rating_average": true,
+	"rating_count":   true,
+}
+
+// reviewSortableFields Review fields, by bson name, that reviews may be sorted on
+var reviewSortableFields = map[string]bool{
+	"_id":           true,
+	"helpful_votes": true,
+	"created_at":    true,
+	"updated_at":    true,
+}
+
+// queryStringOperators operators accepted between a field and its value, longest first
+var queryStringOperators = []QueryOperator{
+	GreaterThanOrEqual,
+	LessThanOrEqual,
+	DoesNotEqual,
+	StartsWith,
+	Contains,
+	Equals,
+	LessThan,
+	GreaterThan,
+}
+
+// PageRequest the parsed query params of a listing request
+type PageRequest struct {
+	// Filter the conditions of the request, Books found are further restricted by the cursor
+	Filter      Filter
+	FindOptions FindOptions
+	Page        int64
+	Size        int64
+	// After / Before set when paginating with a cursor instead of page
+	After  *Cursor
+	Before *Cursor
+	// Fuzzy author and title conditions matched approximately, set with the fuzzy param
+	Fuzzy  []Query
+	keyset Filter
+}
+
+// GetQueryParams returns the filter, find options and pagination restricting query results.
+// The sort param lists the fields to sort on, i.e. -rating,title; the filter param holds a RSQL expression, see ParseRSQL.
+// The after and before params hold a cursor returned by a previous page and take precedence over page.
+// With fuzzy=true the author and title equality conditions match similar values instead, see Similarity.
+// Every other param but page and size is a condition on a Book field, i.e. rating>=2&publish_date<2000.
+// Conditions are combined with AND, a value may list alternatives separated by | which are combined with OR,
+// and a field prefixed with ! negates the condition.
+// It returns a Validation Error if a condition is malformed
+func (builder *QueryBuilder) GetQueryParams(r *http.Request) (PageRequest, *BookAPIError) {
+	request := PageRequest{
+		Page:        defaultPage,
+		Size:        defaultSize,
+		FindOptions: FindOptions{Sort: []Sort{{Field: defaultSort, Order: defaultOrder}}},
+	}
+	conditions := []Filter{}
+	sortRequested, fuzzy := false, false
+
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+
+		key, value := param, ""
+		if i := strings.Index(param, "="); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+
+		switch key {
+		case "size":
+			request.Size = parsePositive(value, request.Size)
+
+		case "page":
+			request.Page = parsePositive(value, request.Page)
+
+		case "sort":
+			sortFields, err := parseSort(value, sortableFields)
+			if err != nil {
+				return request, err
+			}
+			request.FindOptions.Sort = sortFields
+			sortRequested = true
+
+		case "fuzzy":
+			enabled, parseErr := strconv.ParseBool(value)
+			if parseErr != nil {
+				return request, NewValidationError("fuzzy must be true or false")
+			}
+			fuzzy = enabled
+
+		case "after", "before":
+			token, unescapeErr := url.QueryUnescape(value)
+			if unescapeErr != nil {
+				return request, NewValidationError("invalid cursor")
+			}
+
+			cursor, err := DecodeCursor(token)
+			if err != nil {
+				return request, err
+			}
+
+			if key == "after" {
+				request.After = &cursor
+			} else {
+				request.Before = &cursor
+			}
+
+		case "filter":
+			expression, unescapeErr := url.QueryUnescape(value)
+			if unescapeErr != nil {
+				return request, NewValidationError("malformed filter param")
+			}
+
+			condition, err := ParseRSQL(expression)
+			if err != nil {
+				return request, err
+			}
+			conditions = append(conditions, condition)
+
+		default:
+			condition, err := parseCondition(param)
+			if err != nil {
+				return request, err
+			}
+			conditions = append(conditions, condition)
+		}
+	}
+
+	// one more Book than the page size tells whether another page follows
+	request.FindOptions.Limit = request.Size + 1
+	request.FindOptions.Skip = request.Size * (request.Page - 1)
+
+	if fuzzy {
+		exact := []Filter{}
+		for _, condition := range conditions {
+			if query := condition.Query; query != nil && query.Operator == Equals && fuzzyFields[query.Field] {
+				request.Fuzzy = append(request.Fuzzy, *query)
+			} else {
+				exact = append(exact, condition)
+			}
+		}
+
+		if len(request.Fuzzy) == 0 {
+			return request, NewValidationError("fuzzy needs an author or title condition")
+		}
+
+		if request.cursor() != nil {
+			return request, NewValidationError("fuzzy results cannot be paginated with a cursor")
+		}
+
+		// the number of similar Books is known once they are scored, no need for one more
+		conditions = exact
+		request.FindOptions.Limit = request.Size
+	}
+
+	if cursor := request.cursor(); cursor != nil {
+		if request.After != nil && request.Before != nil {
+			return request, NewValidationError("after and before cannot be combined")
+		}
+
+		if sortRequested && !cursor.matchesSort(request.FindOptions.Sort) {
+			return request, NewValidationError("cursor does not match the sort")
+		}
+
+		keyset, err := cursor.KeysetFilter(request.Before != nil)
+		if err != nil {
+			return request, err
+		}
+
+		request.keyset = keyset
+		request.FindOptions.Sort = cursor.Sort
+		request.FindOptions.Skip = 0
+		if request.Before != nil {
+			request.FindOptions.Sort = reverseSort(cursor.Sort)
+		}
+	}
+
+	request.Filter = AllOf(conditions...)
+	return request, nil
+}
+
+// FindFilter returns the Filter of the Books of the requested page
+func (request PageRequest) FindFilter() Filter {
+	return AllOf(request.Filter, request.keyset)
+}
+
+// ResultPage the Books of a page with the cursors of its neighbouring pages, nil when there is none
+type ResultPage struct {
+	Books Books
+	Next  *Cursor
+	Prev  *Cursor
+}
+
+// Paginate trims the Books found for the request to the page size, puts them back in the
+// requested sort order and returns them with the cursors of the next and previous pages
+func (request PageRequest) Paginate(books Books) ResultPage {
+	more := int64(len(books)) > request.Size
+	if more {
+		books = books[:request.Size]
+	}
+
+	page := ResultPage{Books: books}
+	if len(books) == 0 {
+		return page
+	}
+
+	if request.Before != nil {
+		page.Books = make(Books, len(books))
+		for i, book := range books {
+			page.Books[len(books)-1-i] = book
+		}
+	}
+
+	first, last := page.Books[0], page.Books[len(page.Books)-1]
+	sortFields := request.FindOptions.Sort
+	if request.Before != nil {
+		sortFields = request.Before.Sort
+	}
+
+	hasNext := more || request.Before != nil
+	hasPrev := (more && request.Before != nil) || request.After != nil || (request.Before == nil && request.FindOptions.Skip > 0)
+	if hasNext {
+		next := NewCursor(last, sortFields)
+		page.Next = &next
+	}
+	if hasPrev {
+		prev := NewCursor(first, sortFields)
+		page.Prev = &prev
+	}
+
+	return page
+}
+
+func (request PageRequest) cursor() *Cursor {
+	if request.After != nil {
+		return request.After
+	}
+
+	return request.Before
+}
+
+// formatSort returns the sort param parsing into the sort
+func formatSort(sortFields []Sort) string {
+	fields := make([]string, 0, len(sortFields))
+	for _, field := range sortFields {
+		if field.Order == DESC {
+			fields = append(fields, "-"+field.Field)
+		} else {
+			fields = append(fields, field.Field)
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
+
+func reverseSort(sortFields []Sort) []Sort {
+	reversed := make([]Sort, len(sortFields))
+	for i, field := range sortFields {
+		reversed[i] = Sort{Field: field.Field, Order: -field.Order}
+	}
+
+	return reversed
+}
+
+// SearchRequest the parsed query params of a full-text search request
+type SearchRequest struct {
+	Text        string
+	FindOptions FindOptions
+	Page        int64
+	Size        int64
+}
+
+// GetSearchParams returns the text and pagination of a full-text search, the text is the q param.
+// It returns a Validation Error if a param is not q, page or size
+func (builder *QueryBuilder) GetSearchParams(r *http.Request) (SearchRequest, *BookAPIError) {
+	request := SearchRequest{Page: defaultPage, Size: defaultSize}
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+
+		key, value := param, ""
+		if i := strings.Index(param, "="); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+
+		switch key {
+		case "q":
+			text, err := url.QueryUnescape(value)
+			if err != nil {
+				return request, NewValidationError("malformed q param")
+			}
+			request.Text = text
+
+		case "size":
+			request.Size = parsePositive(value, request.Size)
+
+		case "page":
+			request.Page = parsePositive(value, request.Page)
+
+		default:
+			return request, NewValidationError(fmt.Sprintf("unknown search param %s", key))
+		}
+	}
+
+	request.FindOptions = FindOptions{Limit: request.Size, Skip: request.Size * (request.Page - 1)}
+	return request, nil
+}
+
+// ReviewRequest the parsed query params of a listing of Reviews
+type ReviewRequest struct {
+	// Status the moderation status of the Reviews listed, empty for the default of the listing
+	Status      ReviewStatus
+	FindOptions FindOptions
+	Page        int64
+	Size        int64
+}
+
+// GetReviewParams returns the status, sort and pagination of a listing of Reviews.
+// The sort param lists the Review fields to sort on, i.e. -helpful_votes; the status param is the moderation status.
+// It returns a Validation Error if a param is not status, sort, page or size or if its value is invalid
+func (builder *QueryBuilder) GetReviewParams(r *http.Request) (ReviewRequest, *BookAPIError) {
+	request := ReviewRequest{Page: defaultPage, Size: defaultSize}
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+
+		key, value := param, ""
+		if i := strings.Index(param, "="); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+
+		switch key {
+		case "status":
+			switch status := ReviewStatus(value); status {
+			case ReviewPending, ReviewApproved, ReviewRejected:
+				request.Status = status
+			default:
+				return request, NewValidationError("status must be pending, approved or rejected")
+			}
+
+		case "sort":
+			sortFields, err := parseSort(value, reviewSortableFields)
+			if err != nil {
+				return request, err
+			}
+			request.FindOptions.Sort = sortFields
+
+		case "size":
+			request.Size = parsePositive(value, request.Size)
+
+		case "page":
+			request.Page = parsePositive(value, request.Page)
+
+		default:
+			return request, NewValidationError(fmt.Sprintf("unknown review param %s", key))
+		}
+	}
+
+	request.FindOptions.Limit = request.Size
+	request.FindOptions.Skip = request.Size * (request.Page - 1)
+	return request, nil
+}
+
+// parsePositive returns the positive number of the param value, or the fallback when it is not one
+func parsePositive(value string, fallback int64) int64 {
+	if number, _ := strconv.ParseInt(value, 10, 64); number > 0 {
+		return number
+	}
+
+	return fallback
+}
+
+// parseCondition parses a single field condition param such as author=Robert+Martin or rating>=2
+func parseCondition(param string) (Filter, *BookAPIError) {
+	text, err := url.QueryUnescape(param)
+	if err != nil {
+		return Filter{}, NewValidationError(fmt.Sprintf("malformed query param %s", param))
+	}
+
+	negate := strings.HasPrefix(text, "!")
+	if negate {
+		text = text[1:]
+	}
+
+	field, operator, value, found := splitCondition(text)
+	if !found || field == "" {
+		return Filter{}, NewValidationError(fmt.Sprintf("missing operator in query param %s", text))
+	}
+
+	if reservedParams[field] || !IsBookField(field) {
+		return Filter{}, NewValidationError(fmt.Sprintf("unknown field %s", field))
+	}
+
+	values := []interface{}{}
+	for _, alternative := range strings.Split(value, "|") {
+		parsed, parseErr := ParseFieldValue(field, alternative)
+		if parseErr != nil {
+			return Filter{}, parseErr
+		}
+		values = append(values, parsed)
+	}
+
+	var filter Filter
+	switch {
+	case len(values) == 1:
+		filter = NewQueryFilter(field, operator, values[0])
+	case operator == Equals:
+		filter = NewQueryFilter(field, In, values)
+	default:
+		alternatives := make([]Filter, 0, len(values))
+		for _, v := range values {
+			alternatives = append(alternatives, NewQueryFilter(field, operator, v))
+		}
+		filter = AnyOf(alternatives...)
+	}
+
+	if negate {
+		return Negate(filter), nil
+	}
+
+	return filter, nil
+}
+
+// parseSort parses a comma separated list of fields, each optionally prefixed with - for descending order.
+// Results are always finally sorted on _id so that pages are deterministic.
+// It returns a Validation Error if a field is not one of the sortable ones
+func parseSort(param string, sortable map[string]bool) ([]Sort, *BookAPIError) {
+	text, err := url.QueryUnescape(param)
+	if err != nil {
+		return nil, NewValidationError(fmt.Sprintf("malformed sort param %s", param))
+	}
+
+	sortFields := []Sort{}
+	seen := map[string]bool{}
+	for _, field := range strings.Split(text, ",") {
+		order := ASC
+		switch {
+		case strings.HasPrefix(field, "-"):
+			field, order = field[1:], DESC
+		case strings.HasPrefix(field, "+"):
+			field = field[1:]
+		}
+
+		if !sortable[field] {
+			return nil, NewValidationError(fmt.Sprintf("cannot sort on field %s", field))
+		}
+
+		if seen[field] {
+			return nil, NewValidationError(fmt.Sprintf("duplicate sort field %s", field))
+		}
+		seen[field] = true
+
+		sortFields = append(sortFields, Sort{Field: field, Order: order})
+	}
+
+	if !seen[defaultSort] {
+		sortFields = append(sortFields, Sort{Field: defaultSort, Order: defaultOrder})
+	}
+
+	return sortFields, nil
+}
+
+// splitCondition splits the text around the first operator
+func splitCondition(text string) (string, QueryOperator, string, bool) {
+	start := strings.IndexAny(text, "=<>!^*")
+	if start < 0 {
+		return "", "", "", false
+	}
+
+	for _, operator := range queryStringOperators {
+		if strings.HasPrefix(text[start:], string(operator)) {
+			return text[:start], operator, text[start+len(operator):], true
+		}
+	}
+
+	return "", "", "", false
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newQueryRequest(rawQuery string) *http.Request {
+	return &http.Request{URL: &url.URL{Path: "/books", RawQuery: rawQuery}}
+}
+
+func TestQueryBuilder_GetQueryParams_Defaults(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest(""))
+
+	assert.Nil(t, err)
+	assert.True(t, request.Filter.IsEmpty())
+	assert.Equal(t, int64(defaultSize+1), request.FindOptions.Limit)
+	assert.Equal(t, int64(0), request.FindOptions.Skip)
+}
+
+func TestQueryBuilder_GetQueryParams_WithComparisons(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest("rating>=2&publish_date<2000&author=Robert+Martin&page=3&size=5"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, AllOf(
+		NewQueryFilter("rating", GreaterThanOrEqual, float64(2)),
+		NewQueryFilter("publish_date", LessThan, "2000"),
+		NewQueryFilter("author", Equals, "Robert Martin"),
+	), request.Filter)
+	assert.Equal(t, int64(6), request.FindOptions.Limit)
+	assert.Equal(t, int64(10), request.FindOptions.Skip)
+}
+
+func TestQueryBuilder_GetQueryParams_WithAlternativesAndNegation(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest("status=1|2&!title^=Clean&rating>1|0"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, AllOf(
+		NewQueryFilter("status", In, []interface{}{CheckedIn, CheckedOut}),
+		Negate(NewQueryFilter("title", StartsWith, "Clean")),
+		AnyOf(NewQueryFilter("rating", GreaterThan, float64(1)), NewQueryFilter("rating", GreaterThan, float64(0))),
+	), request.Filter)
+}
+
+func TestQueryBuilder_GetQueryParams_WithStatusNames(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest("status=CheckedOut|OnHoldShelf&status!=4"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, AllOf(
+		NewQueryFilter("status", In, []interface{}{CheckedOut, OnHoldShelf}),
+		NewQueryFilter("status", DoesNotEqual, Lost),
+	), request.Filter)
+
+	_, err = builder.GetQueryParams(newQueryRequest("status=Stolen"))
+	assert.Equal(t, ValidationError, err.errorType)
+}
+
+func TestQueryBuilder_GetQueryParams_WithInvalidParams(t *testing.T) {
+	builder := QueryBuilder{}
+	for _, rawQuery := range []string{"unknown=1", "rating=abc", "rating", "page>=2", "rating_distribution=1", "rating_scale=0-3/1"} {
+		_, err := builder.GetQueryParams(newQueryRequest(rawQuery))
+		assert.NotNil(t, err, rawQuery)
+		assert.Equal(t, ValidationError, err.errorType, rawQuery)
+	}
+
+	// a map has no value to compare with and a hidden field is not in the responses
+	_, err := builder.GetQueryParams(newQueryRequest("rating_distribution=1"))
+	assert.Equal(t, "unknown field rating_distribution", err.msg)
+	_, err = builder.GetQueryParams(newQueryRequest("rating_scale=0-3/1"))
+	assert.Equal(t, "unknown field rating_scale", err.msg)
+}
+
+func TestQueryBuilder_GetQueryParams_WithSort(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest("sort=-rating,title,publish_date"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Sort{
+		{Field: "rating", Order: DESC},
+		{Field: "title", Order: ASC},
+		{Field: "publish_date", Order: ASC},
+		{Field: "_id", Order: ASC},
+	}, request.FindOptions.Sort)
+
+	request, err = builder.GetQueryParams(newQueryRequest("sort=-_id"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Sort{{Field: "_id", Order: DESC}}, request.FindOptions.Sort)
+}
+
+func TestQueryBuilder_GetQueryParams_WithInvalidSort(t *testing.T) {
+	builder := QueryBuilder{}
+	for _, rawQuery := range []string{"sort=name", "sort=", "sort=rating,-rating", "sort=-"} {
+		_, err := builder.GetQueryParams(newQueryRequest(rawQuery))
+		assert.NotNil(t, err, rawQuery)
+		assert.Equal(t, ValidationError, err.errorType, rawQuery)
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type ratingRepo struct {
+	collection collection
+	// scales holds the scale of the stored Ratings, a single document once they were rescaled
+	scales collection
+}
+
+// storedRatingScale document of the scale of the stored Ratings, the step of its values rather than whether they
+// are halves so that every engine stores numbers only
+type storedRatingScale struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty"`
+	Min  float64            `bson:"min"`
+	Max  float64            `bson:"max"`
+	Step float64            `bson:"step"`
+}
+
+// FindAll Queries the ratings collection with optional filters and find options
+// It returns a list of Ratings or an API Error Response
+func (r *ratingRepo) FindAll(filter Filter, findOptions FindOptions) (Ratings, *BookAPIError) {
+	ratings := Ratings{}
+	if err := r.collection.find(filter, findOptions, &ratings); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// Update sets the fields of the Rating with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *ratingRepo) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	updated, err := r.collection.update(id, condition, fields)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return NewRatingNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Save Saves the Rating Payload, generating an ID when it has none
+// It returns the persisted Rating ID or an API Error Response if failed
+func (r *ratingRepo) Save(rating Rating) (string, *BookAPIError) {
+	return r.collection.insert(rating)
+}
+
+// Scale Queries the scale the Ratings are stored on, DefaultRatingScale until they are rescaled
+// It returns the RatingScale or an API Error Response
+func (r *ratingRepo) Scale() (RatingScale, *BookAPIError) {
+	stored := []storedRatingScale{}
+	if err := r.scales.find(Filter{}, FindOptions{Limit: 1}, &stored); err != nil {
+		return RatingScale{}, err
+	}
+
+	if len(stored) == 0 {
+		return DefaultRatingScale, nil
+	}
+
+	return RatingScale{Min: stored[0].Min, Max: stored[0].Max, HalfSteps: stored[0].Step < 1}, nil
+}
+
+// SaveScale records the scale the Ratings are stored on
+// It returns an API Error Response if failed
+func (r *ratingRepo) SaveScale(scale RatingScale) *BookAPIError {
+	stored := []storedRatingScale{}
+	if err := r.scales.find(Filter{}, FindOptions{Limit: 1}, &stored); err != nil {
+		return err
+	}
+
+	if len(stored) == 0 {
+		_, err := r.scales.insert(storedRatingScale{Min: scale.Min, Max: scale.Max, Step: scale.step()})
+		return err
+	}
+
+	_, err := r.scales.update(stored[0].ID.Hex(), Filter{}, Fields{"min": scale.Min, "max": scale.Max, "step": scale.step()})
+	return err
+}
+
+// NewRatingRepository Initializes the ratings repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewRatingRepository() (RatingRepository, *BookAPIError) {
+	collection, err := newCollection("ratings")
+	if err != nil {
+		return nil, err
+	}
+
+	scales, err := newCollection("rating_scales")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ratingRepo{collection: collection, scales: scales}, nil
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-ozzo/ozzo-validation"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxRating highest bound of a rating scale, so a distribution counts a handful of values
+const maxRating = 100
+
+// RatingScale the ratings a Patron may give a Book: every whole number from Min to Max, and the halves between them
+// with HalfSteps
+type RatingScale struct {
+	Min       float64
+	Max       float64
+	HalfSteps bool
+}
+
+// DefaultRatingScale whole ratings from 0 to 3, the scale of the Ratings stored before it was configurable
+var DefaultRatingScale = RatingScale{Min: 0, Max: 3}
+
+// ratingScale the scale of the Ratings, set on startup by SetRatingScale
+var ratingScale = DefaultRatingScale
+
+// NewRatingScale returns the rating scale configured by the rating_min, rating_max and rating_half_steps environment
+// variables, defaulting to DefaultRatingScale
+// It returns an API Error Response if a variable is invalid
+func NewRatingScale() (RatingScale, *BookAPIError) {
+	scale := DefaultRatingScale
+
+	if value, present := os.LookupEnv("rating_min"); present {
+		min, err := strconv.Atoi(value)
+		if err != nil || min < 0 {
+			return RatingScale{}, NewMissingEnvVariable("rating_min must be a whole number, i.e. 1")
+		}
+		scale.Min = float64(min)
+	}
+
+	if value, present := os.LookupEnv("rating_max"); present {
+		max, err := strconv.Atoi(value)
+		if err != nil || max > maxRating {
+			return RatingScale{}, NewMissingEnvVariable(fmt.Sprintf("rating_max must be a whole number up to %d, i.e. 5", maxRating))
+		}
+		scale.Max = float64(max)
+	}
+
+	if value, present := os.LookupEnv("rating_half_steps"); present {
+		halfSteps, err := strconv.ParseBool(value)
+		if err != nil {
+			return RatingScale{}, NewMissingEnvVariable("rating_half_steps must be true or false")
+		}
+		scale.HalfSteps = halfSteps
+	}
+
+	if scale.Min >= scale.Max {
+		return RatingScale{}, NewMissingEnvVariable("rating_min must be lower than rating_max")
+	}
+
+	return scale, nil
+}
+
+// SetRatingScale makes the scale the one Books and Ratings are validated and aggregated on
+func SetRatingScale(scale RatingScale) {
+	ratingScale = scale
+}
+
+// step returns the difference between two neighbouring ratings of the scale
+func (s RatingScale) step() float64 {
+	if s.HalfSteps {
+		return 0.5
+	}
+
+	return 1
+}
+
+// Values returns the ratings of the scale, worst first
+func (s RatingScale) Values() []float64 {
+	values := []float64{}
+	for value := s.Min; value <= s.Max; value += s.step() {
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// Contains reports whether the value is a rating of the scale
+func (s RatingScale) Contains(value float64) bool {
+	return value >= s.Min && value <= s.Max && s.Round(value) == value
+}
+
+// Round returns the rating of the scale nearest to the value
+func (s RatingScale) Round(value float64) float64 {
+	value = math.Max(s.Min, math.Min(s.Max, value))
+	return s.Min + math.Round((value-s.Min)/s.step())*s.step()
+}
+
+// Rescale returns the rating of the scale at the same place as the value on the other scale
+func (s RatingScale) Rescale(value float64, from RatingScale) float64 {
+	return s.Round(s.Min + (value-from.Min)*(s.Max-s.Min)/(from.Max-from.Min))
+}
+
+// Parse returns the rating of the text, i.e. the rate route param.
+// It returns a Validation Error naming the field if the text is not a rating of the scale
+func (s RatingScale) Parse(field string, text string) (float64, *BookAPIError) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || !s.Contains(value) {
+		return 0, NewInvalidFieldError(field, "in", fmt.Sprintf("%s %s", field, s.describe()))
+	}
+
+	return value, nil
+}
+
+// Validate checks the value is a rating of the scale. Unlike the ozzo-validation rules a 0 value is checked too
+func (s RatingScale) Validate(value interface{}) error {
+	value, _ = validation.Indirect(value)
+	if rating, ok := value.(float64); !ok || !s.Contains(rating) {
+		return errors.New(s.describe())
+	}
+
+	return nil
+}
+
+// unratedOr returns the rule of the rating of a Book: a rating of the scale, or 0 while nobody rated the Book
+func (s RatingScale) unratedOr() validation.Rule {
+	return validation.By(func(value interface{}) error {
+		if validation.IsEmpty(value) {
+			return nil
+		}
+
+		return s.Validate(value)
+	})
+}
+
+// describe returns the rule a rating of the scale follows
+func (s RatingScale) describe() string {
+	if s.HalfSteps {
+		return fmt.Sprintf("must be a rating from %g to %g in steps of 0.5", s.Min, s.Max)
+	}
+
+	return fmt.Sprintf("must be a whole rating from %g to %g", s.Min, s.Max)
+}
+
+// key returns the name of the scale a rescaled Rating or Book records, i.e. 1-5/0.5
+func (s RatingScale) key() string {
+	return fmt.Sprintf("%s-%s/%s", ratingKey(s.Min), ratingKey(s.Max), ratingKey(s.step()))
+}
+
+// parseRatingScale returns the scale named by the key, false if it names none such as the empty key of the Ratings
+// and Books never rescaled
+func parseRatingScale(key string) (RatingScale, bool) {
+	bounds := strings.SplitN(key, "/", 2)
+	values := strings.SplitN(bounds[0], "-", 2)
+	if len(bounds) != 2 || len(values) != 2 {
+		return RatingScale{}, false
+	}
+
+	min, minErr := strconv.ParseFloat(values[0], 64)
+	max, maxErr := strconv.ParseFloat(values[1], 64)
+	step, stepErr := strconv.ParseFloat(bounds[1], 64)
+	if minErr != nil || maxErr != nil || stepErr != nil {
+		return RatingScale{}, false
+	}
+
+	return RatingScale{Min: min, Max: max, HalfSteps: step < 1}, true
+}
+
+// ratingKey returns the key of the rating in a RatingDistribution, i.e. 2 or 2.5
+func ratingKey(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
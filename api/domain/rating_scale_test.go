@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestNewRatingScale(t *testing.T) {
+	scale, err := NewRatingScale()
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultRatingScale, scale)
+
+	_ = os.Setenv("rating_min", "1")
+	_ = os.Setenv("rating_max", "5")
+	_ = os.Setenv("rating_half_steps", "true")
+	defer func() {
+		_ = os.Unsetenv("rating_min")
+		_ = os.Unsetenv("rating_max")
+		_ = os.Unsetenv("rating_half_steps")
+	}()
+
+	scale, err = NewRatingScale()
+	assert.Nil(t, err)
+	assert.Equal(t, RatingScale{Min: 1, Max: 5, HalfSteps: true}, scale)
+
+	for variable, value := range map[string]string{"rating_min": "5", "rating_max": "101", "rating_half_steps": "sometimes"} {
+		_ = os.Setenv(variable, value)
+		_, err = NewRatingScale()
+		assert.Equal(t, MissingEnvVariable, err.errorType, variable)
+		_ = os.Setenv(variable, map[string]string{"rating_min": "1", "rating_max": "5", "rating_half_steps": "true"}[variable])
+	}
+}
+
+func TestRatingScale(t *testing.T) {
+	scale := RatingScale{Min: 1, Max: 5, HalfSteps: true}
+
+	assert.Equal(t, []float64{1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5}, scale.Values())
+	assert.True(t, scale.Contains(4.5))
+	assert.False(t, scale.Contains(4.25))
+	assert.False(t, DefaultRatingScale.Contains(2.5))
+	assert.Equal(t, 4.5, scale.Round(4.4))
+	assert.Equal(t, 5.0, scale.Round(9))
+	assert.Equal(t, 2.0, DefaultRatingScale.Rescale(3, scale), "3 out of 1 to 5 is halfway, nearest to 2 out of 0 to 3")
+	assert.Equal(t, 2.5, scale.Rescale(1, DefaultRatingScale), "1 out of 0 to 3 is nearest to 2.5 out of 1 to 5")
+
+	rating, err := scale.Parse("rate", "4.5")
+	assert.Nil(t, err)
+	assert.Equal(t, 4.5, rating)
+
+	for _, text := range []string{"high", "0", "4.25", "5.5"} {
+		_, err = scale.Parse("rate", text)
+		assert.Equal(t, []FieldError{{Field: "rate", Rule: "in", Message: "rate must be a rating from 1 to 5 in steps of 0.5"}}, err.fields, text)
+	}
+}
+
+func TestRatingScale_Key(t *testing.T) {
+	scale := RatingScale{Min: 1, Max: 5, HalfSteps: true}
+	assert.Equal(t, "1-5/0.5", scale.key())
+	assert.Equal(t, "0-3/1", DefaultRatingScale.key())
+
+	for _, want := range []RatingScale{scale, DefaultRatingScale} {
+		parsed, ok := parseRatingScale(want.key())
+		assert.True(t, ok)
+		assert.Equal(t, want, parsed)
+	}
+
+	for _, key := range []string{"", "1-5", "1/0.5", "one-5/1"} {
+		_, ok := parseRatingScale(key)
+		assert.False(t, ok, key)
+	}
+}
@@ -0,0 +1,316 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// testRatings exercises the ratings of the Patrons stored in the collection and the aggregates they keep on the Book
+func testRatings(t *testing.T, ratings collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	other, _ := repo.Save(Book{Author: "Paulo Coelho", Title: "The Alchemist", Publisher: "HarperCollins", Status: CheckedIn, PublishDate: "1988"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	bookService := &service{repository: repo, patrons: patrons, ratings: &ratingRepo{collection: ratings},
+		policy: DefaultLoanPolicy, now: func() time.Time { return now }}
+
+	rating, err := bookService.Rate(id, 3, RatingRequest{PatronID: ada})
+	assert.Nil(t, err)
+	assert.Equal(t, 3.0, rating.Value)
+	assert.False(t, rating.ID.IsZero())
+
+	now = now.Add(time.Minute)
+	_, err = bookService.Rate(id, 2, RatingRequest{PatronID: alan})
+	assert.Nil(t, err)
+
+	// rating the Book again changes the rating of the Patron, the last rater does not win
+	now = now.Add(time.Minute)
+	again, err := bookService.Rate(id, 1, RatingRequest{PatronID: ada})
+	assert.Nil(t, err)
+	assert.Equal(t, rating.ID, again.ID)
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, 1.5, book.RatingAverage)
+	assert.Equal(t, 2, book.RatingCount)
+	assert.Equal(t, RatingDistribution{"0": 0, "1": 1, "2": 1, "3": 0}, book.RatingDistribution)
+	assert.Equal(t, 2.0, book.Rating)
+
+	summary, err := bookService.Ratings(id)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, summary.Average)
+	assert.Equal(t, 2, summary.Count)
+	if assert.Len(t, summary.Ratings, 2) {
+		assert.Equal(t, ada, summary.Ratings[0].PatronID)
+		assert.Equal(t, 1.0, summary.Ratings[0].Value)
+	}
+
+	_, err = bookService.Rate(other, 3, RatingRequest{PatronID: alan})
+	assert.Nil(t, err)
+	found, _ := bookService.FindAll(NewQueryFilter("rating_average", GreaterThanOrEqual, 1.5),
+		FindOptions{Sort: []Sort{{Field: "rating_average", Order: DESC}}})
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, "The Alchemist", found[0].Title)
+	}
+
+	_, err = bookService.Rate(id, 9, RatingRequest{PatronID: ada})
+	assert.Equal(t, []FieldError{{Field: "rating", Rule: "in", Message: "must be a whole rating from 0 to 3"}}, err.fields)
+	_, err = bookService.Rate(id, 2, RatingRequest{})
+	assert.Equal(t, []FieldError{{Field: "patron_id", Rule: "required", Message: "patron_id cannot be blank"}}, err.fields)
+	_, err = bookService.Rate(id, 2, RatingRequest{PatronID: "5ca7c76f9287bd3832d96f15"})
+	assert.Equal(t, ValidationError, err.errorType)
+	_, err = bookService.Rate("5ca7c76f9287bd3832d96f15", 2, RatingRequest{PatronID: ada})
+	assert.Equal(t, NotFoundError, err.errorType)
+	_, err = bookService.Ratings("5ca7c76f9287bd3832d96f15")
+	assert.Equal(t, NotFoundError, err.errorType)
+}
+
+func TestService_Rate(t *testing.T) {
+	testRatings(t, newMemoryCollection(nil))
+}
+
+func TestService_Rate_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testRatings(t, newSQLiteCollection(t, path, "ratings"))
+}
+
+// concurrentRatings misses the Ratings on the first lookup, as when another request of the Patron saves one right after it
+type concurrentRatings struct {
+	RatingRepository
+	missed bool
+}
+
+func (r *concurrentRatings) FindAll(filter Filter, findOptions FindOptions) (Ratings, *BookAPIError) {
+	if !r.missed {
+		r.missed = true
+		return Ratings{}, nil
+	}
+
+	return r.RatingRepository.FindAll(filter, findOptions)
+}
+
+// testConcurrentRatings exercises a Patron rating a Book twice at once, the ratings stored in the collection keeping a
+// single Rating per Patron and Book
+func testConcurrentRatings(t *testing.T, ratings collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	bookService := &service{repository: repo, patrons: patrons, ratings: &ratingRepo{collection: ratings}, policy: DefaultLoanPolicy, now: loanTime}
+
+	rating, err := bookService.Rate(id, 3, RatingRequest{PatronID: ada})
+	assert.Nil(t, err)
+
+	bookService.ratings = &concurrentRatings{RatingRepository: bookService.ratings}
+	again, err := bookService.Rate(id, 1, RatingRequest{PatronID: ada})
+	assert.Nil(t, err)
+	assert.Equal(t, rating.ID, again.ID)
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, 1, book.RatingCount)
+	assert.Equal(t, 1.0, book.RatingAverage)
+}
+
+func TestService_Rate_Concurrently(t *testing.T) {
+	testConcurrentRatings(t, newMemoryCollection(nil, uniqueKeys["ratings"]...))
+}
+
+func TestService_Rate_Concurrently_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	testConcurrentRatings(t, newSQLiteCollection(t, path, "ratings"))
+}
+
+func TestService_RatingAggregates_AreNotWritable(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	assert.Nil(t, repo.Update(id, Filter{}, Fields{"rating_average": 2.5, "rating_count": 2}))
+	bookService := NewService(repo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+
+	_, err := bookService.Patch(id, AnyVersion, MergePatch(`{"rating_count": 5}`))
+	assert.Equal(t, []FieldError{{Field: "rating_count", Rule: "immutable", Message: "rating_count cannot be changed"}}, err.fields)
+
+	book, _ := repo.FindOne(id)
+	book.RatingAverage, book.RatingCount = 0, 0
+	assert.Nil(t, bookService.Update(id, AnyVersion, book))
+
+	book, _ = repo.FindOne(id)
+	assert.Equal(t, 2.5, book.RatingAverage)
+	assert.Equal(t, 2, book.RatingCount)
+}
+
+// testRescaleRatings exercises moving the Ratings stored in the collections to another rating scale
+func testRescaleRatings(t *testing.T, ratings collection, scales collection) {
+	defer SetRatingScale(DefaultRatingScale)
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	legacy, _ := repo.Save(Book{Author: "Paulo Coelho", Title: "The Alchemist", Publisher: "HarperCollins", Status: CheckedIn, PublishDate: "1988", Rating: 2, RatingCount: 1})
+	lowest, _ := repo.Save(Book{Author: "Dan Brown", Title: "Digital Fortress", Publisher: "St. Martin's", Status: CheckedIn, PublishDate: "1998", RatingCount: 1})
+	unrated, _ := repo.Save(Book{Author: "Donald Knuth", Title: "Concrete Mathematics", Publisher: "Addison-Wesley", Status: CheckedIn, PublishDate: "1989"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+
+	bookService := &service{repository: repo, patrons: patrons, ratings: &ratingRepo{collection: ratings, scales: scales},
+		policy: DefaultLoanPolicy, now: loanTime}
+	_, _ = bookService.Rate(id, 3, RatingRequest{PatronID: ada})
+	_, _ = bookService.Rate(id, 2, RatingRequest{PatronID: alan})
+
+	rescaled, err := bookService.RescaleRatings()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, rescaled, "the Ratings are already on the rating scale")
+
+	SetRatingScale(RatingScale{Min: 1, Max: 5})
+	rescaled, err = bookService.RescaleRatings()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, rescaled)
+
+	summary, _ := bookService.Ratings(id)
+	if assert.Len(t, summary.Ratings, 2) {
+		assert.Equal(t, 4.0, summary.Ratings[0].Value, "2 out of 0 to 3 is nearest to 4 out of 1 to 5")
+		assert.Equal(t, 5.0, summary.Ratings[1].Value)
+	}
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, 4.5, book.RatingAverage)
+	assert.Equal(t, 5.0, book.Rating)
+	assert.Equal(t, RatingDistribution{"1": 0, "2": 0, "3": 0, "4": 1, "5": 1}, book.RatingDistribution)
+
+	book, _ = repo.FindOne(legacy)
+	assert.Equal(t, 4.0, book.Rating)
+
+	// 0 out of 0 to 3 is a rating, the lowest one, while a Book nobody rated stays unrated
+	book, _ = repo.FindOne(lowest)
+	assert.Equal(t, 1.0, book.Rating)
+	book, _ = repo.FindOne(unrated)
+	assert.Equal(t, 0.0, book.Rating)
+
+	scale, _ := bookService.ratings.Scale()
+	assert.Equal(t, RatingScale{Min: 1, Max: 5}, scale)
+	rescaled, err = bookService.RescaleRatings()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, rescaled, "the Ratings are moved once")
+
+	_, err = bookService.Rate(id, 0, RatingRequest{PatronID: ada})
+	assert.Equal(t, []FieldError{{Field: "rating", Rule: "in", Message: "must be a whole rating from 1 to 5"}}, err.fields)
+}
+
+func TestService_RescaleRatings(t *testing.T) {
+	testRescaleRatings(t, newMemoryCollection(nil), newMemoryCollection(nil))
+}
+
+func TestService_RescaleRatings_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	ratings, scales := newSQLiteCollection(t, path, "ratings"), newSQLiteCollection(t, path, "rating_scales")
+	testRescaleRatings(t, ratings, scales)
+}
+
+// interruptedCollection fails the writes once the allowed number of them is made, as a stopping process would
+type interruptedCollection struct {
+	collection
+	// writes is the number of writes left, negative when they are not limited
+	writes int
+}
+
+func (c *interruptedCollection) write() *BookAPIError {
+	if c.writes == 0 {
+		return NewDatabaseOperationError("interrupted")
+	}
+
+	if c.writes > 0 {
+		c.writes--
+	}
+
+	return nil
+}
+
+func (c *interruptedCollection) insert(entity interface{}) (string, *BookAPIError) {
+	if err := c.write(); err != nil {
+		return "", err
+	}
+
+	return c.collection.insert(entity)
+}
+
+func (c *interruptedCollection) update(id string, condition Filter, fields Fields) (bool, *BookAPIError) {
+	if err := c.write(); err != nil {
+		return false, err
+	}
+
+	return c.collection.update(id, condition, fields)
+}
+
+// testRescaleRatingsInterrupted exercises running the rescale again after it stopped partway
+func testRescaleRatingsInterrupted(t *testing.T, ratings collection, scales collection) {
+	defer SetRatingScale(DefaultRatingScale)
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	legacy, _ := repo.Save(Book{Author: "Paulo Coelho", Title: "The Alchemist", Publisher: "HarperCollins", Status: CheckedIn, PublishDate: "1988", Rating: 2, RatingCount: 1})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+
+	interruptedRatings := &interruptedCollection{collection: ratings, writes: -1}
+	interruptedScales := &interruptedCollection{collection: scales, writes: -1}
+	bookService := &service{repository: repo, patrons: patrons,
+		ratings: &ratingRepo{collection: interruptedRatings, scales: interruptedScales}, policy: DefaultLoanPolicy, now: loanTime}
+	_, _ = bookService.Rate(id, 3, RatingRequest{PatronID: ada})
+	_, _ = bookService.Rate(id, 2, RatingRequest{PatronID: alan})
+	SetRatingScale(RatingScale{Min: 1, Max: 5})
+
+	// stopped after moving the first Rating
+	interruptedRatings.writes = 1
+	rescaled, err := bookService.RescaleRatings()
+	if assert.NotNil(t, err) {
+		assert.Equal(t, DbConnectionError, err.errorType)
+	}
+	assert.Equal(t, 1, rescaled)
+
+	// stopped after moving every Rating and the Book but before recording the scale
+	interruptedRatings.writes, interruptedScales.writes = -1, 0
+	rescaled, err = bookService.RescaleRatings()
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, rescaled, "the Rating moved before is not moved again")
+
+	interruptedScales.writes = -1
+	rescaled, err = bookService.RescaleRatings()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, rescaled)
+
+	summary, _ := bookService.Ratings(id)
+	if assert.Len(t, summary.Ratings, 2) {
+		assert.Equal(t, 4.0, summary.Ratings[0].Value)
+		assert.Equal(t, 5.0, summary.Ratings[1].Value)
+	}
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, 4.5, book.RatingAverage)
+	assert.Equal(t, RatingDistribution{"1": 0, "2": 0, "3": 0, "4": 1, "5": 1}, book.RatingDistribution)
+
+	book, _ = repo.FindOne(legacy)
+	assert.Equal(t, 4.0, book.Rating, "the rating written with the Book is moved once")
+
+	scale, _ := bookService.ratings.Scale()
+	assert.Equal(t, RatingScale{Min: 1, Max: 5}, scale)
+}
+
+func TestService_RescaleRatings_Interrupted(t *testing.T) {
+	testRescaleRatingsInterrupted(t, newMemoryCollection(nil), newMemoryCollection(nil))
+}
+
+func TestService_RescaleRatings_Interrupted_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	ratings, scales := newSQLiteCollection(t, path, "ratings"), newSQLiteCollection(t, path, "rating_scales")
+	testRescaleRatingsInterrupted(t, ratings, scales)
+}
@@ -2,11 +2,14 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -14,10 +17,20 @@ type repo struct{}
 
 var db *mongo.Collection
 
+var (
+	mongoMu       sync.Mutex
+	mongoDatabase *mongo.Database
+)
+
 // FindAll Queries MongoDB with optional filters on custom attributes and Collection options
 // It returns a list of paginated Books or an API Error Response
-func (r *repo) FindAll(filters bson.M, findOptions *options.FindOptions) (Books, *BookAPIError) {
-	cur, colErr := db.Find(nil, filters, findOptions)
+func (r *repo) FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError) {
+	mongoFilter, filterErr := toMongoFilter(filter)
+	if filterErr != nil {
+		return nil, NewDatabaseOperationError(filterErr.Error())
+	}
+
+	cur, colErr := db.Find(nil, mongoFilter, toMongoFindOptions(findOptions))
 	if colErr != nil {
 		return nil, NewDatabaseOperationError(colErr.Error())
 	}
@@ -40,6 +53,53 @@ func (r *repo) FindAll(filters bson.M, findOptions *options.FindOptions) (Books,
 	return books, nil
 }
 
+// Count Counts the documents of the MongoDB collection matching the filter
+// It returns the number of Books or an API Error Response
+func (r *repo) Count(filter Filter) (int64, *BookAPIError) {
+	mongoFilter, filterErr := toMongoFilter(filter)
+	if filterErr != nil {
+		return 0, NewDatabaseOperationError(filterErr.Error())
+	}
+
+	count, countErr := db.CountDocuments(nil, mongoFilter)
+	if countErr != nil {
+		return 0, NewDatabaseOperationError(countErr.Error())
+	}
+
+	return count, nil
+}
+
+// Search Queries the MongoDB text index for the Books matching any term of the text
+// It returns the page of Books selected by the find options, most relevant first, with the number of matching Books
+func (r *repo) Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError) {
+	mongoFilter, mongoOptions := toMongoTextSearch(text, findOptions)
+	cur, colErr := db.Find(nil, mongoFilter, mongoOptions)
+	if colErr != nil {
+		return nil, 0, NewDatabaseOperationError(colErr.Error())
+	}
+
+	var hits = SearchHits{}
+	for cur.Next(nil) {
+		hit := SearchHit{}
+		if decodeErr := cur.Decode(&hit); decodeErr != nil {
+			return nil, 0, NewDatabaseOperationError(decodeErr.Error())
+		}
+
+		hits = append(hits, hit)
+	}
+
+	if curErr := cur.Err(); curErr != nil {
+		return nil, 0, NewDatabaseOperationError(curErr.Error())
+	}
+
+	count, countErr := db.CountDocuments(nil, mongoFilter)
+	if countErr != nil {
+		return nil, 0, NewDatabaseOperationError(countErr.Error())
+	}
+
+	return hits, count, nil
+}
+
 // FineOne Queries MongoDB for a specific Book
 // It returns one Book or an API Error Response
 func (r *repo) FindOne(id string) (Book, *BookAPIError) {
@@ -54,28 +114,43 @@ func (r *repo) FindOne(id string) (Book, *BookAPIError) {
 	return book, nil
 }
 
-// Delete Hard deletes the Book with the specified ID
+// Delete Hard deletes the Book with the specified ID when it matches the condition
 // It returns an API Error Response if failed
-func (r *repo) Delete(id string) *BookAPIError {
-	objectID, _ := primitive.ObjectIDFromHex(id)
-	_, deleteError := db.DeleteOne(nil, bson.D{{"_id", objectID}})
+func (r *repo) Delete(id string, condition Filter) *BookAPIError {
+	filter, filterErr := toMongoConditionalFilter(id, condition)
+	if filterErr != nil {
+		return NewDatabaseOperationError(filterErr.Error())
+	}
+
+	result, deleteError := db.DeleteOne(nil, filter)
 	if deleteError != nil {
 		return NewDatabaseOperationError(deleteError.Error())
 	}
 
+	if result.DeletedCount == 0 {
+		return conditionFailure(id, r.FindOne)
+	}
+
 	return nil
 }
 
-// Update updates the Book with the specified ID
+// Update updates the Book with the specified ID when it matches the condition, incrementing its version
 // It returns an API Error Response if failed
-func (r *repo) Update(id string, updatedFields bson.D) *BookAPIError {
-	objectID, _ := primitive.ObjectIDFromHex(id)
-	filter := bson.D{{"_id", objectID}}
-	_, updateError := db.UpdateOne(nil, filter, updatedFields)
+func (r *repo) Update(id string, condition Filter, updatedFields Fields) *BookAPIError {
+	filter, filterErr := toMongoConditionalFilter(id, condition)
+	if filterErr != nil {
+		return NewDatabaseOperationError(filterErr.Error())
+	}
+
+	result, updateError := db.UpdateOne(nil, filter, toMongoUpdate(updatedFields))
 	if updateError != nil {
 		return NewDatabaseOperationError(updateError.Error())
 	}
 
+	if result.MatchedCount == 0 {
+		return conditionFailure(id, r.FindOne)
+	}
+
 	return nil
 }
 
@@ -96,6 +171,7 @@ func (r *repo) IsExistingEntry(book Book) bool {
 // Save Saves the Book Payload
 // It returns the persisted Book ID or an API Error Response if failed
 func (r *repo) Save(book Book) (string, *BookAPIError) {
+	book.Version = 1
 	created, insertError := db.InsertOne(nil, book)
 	if insertError != nil {
 		return "", NewPersistError(insertError.Error())
@@ -104,31 +180,119 @@ func (r *repo) Save(book Book) (string, *BookAPIError) {
 	return created.InsertedID.(primitive.ObjectID).Hex(), nil
 }
 
-// NewRepository Initializes a repository instance
+// NewRepository Initializes the repository selected by the storage_engine environment variable,
+// defaulting to MongoDB
 // It returns an API Error Response if failed
 func NewRepository() (Repository, *BookAPIError) {
-	server, serverPresent := os.LookupEnv("mongodb_url")
-	if !serverPresent {
-		return nil, NewMissingEnvVariable("need to set mongodb_url environment variable")
+	engine, _ := os.LookupEnv("storage_engine")
+	switch engine {
+	case "", "mongodb":
+		return newMongoRepository()
+	case "memory":
+		return NewMemoryRepository(), nil
+	case "file":
+		path, err := lookupEnv("storage_path")
+		if err != nil {
+			return nil, err
+		}
+
+		return NewFileRepository(path)
+	case "sql":
+		driver, err := lookupEnv("sql_driver")
+		if err != nil {
+			return nil, err
+		}
+
+		dsn, err := lookupEnv("sql_dsn")
+		if err != nil {
+			return nil, err
+		}
+
+		return NewSQLRepository(driver, dsn)
+	default:
+		return nil, NewMissingEnvVariable(fmt.Sprintf("unsupported storage_engine %s", engine))
+	}
+}
+
+// newMongoRepository Initializes a MongoDB backed repository instance
+// It returns an API Error Response if failed
+func newMongoRepository() (Repository, *BookAPIError) {
+	collection, err := lookupEnv("collection_name")
+	if err != nil {
+		return nil, err
 	}
 
-	dbName, dbPresent := os.LookupEnv("database_name")
-	if !dbPresent {
-		return nil, NewMissingEnvVariable("need to set database_name environment variable")
+	database, err := connectMongo()
+	if err != nil {
+		return nil, err
 	}
 
-	collection, collectionPresent := os.LookupEnv("collection_name")
-	if !collectionPresent {
-		return nil, NewMissingEnvVariable("need to set collection_name environment variable")
+	db = database.Collection(collection)
+
+	// search is unavailable until the text index exists, the rest of the API does not depend on it
+	indexCtx, cancelIndex := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelIndex()
+	if _, indexErr := db.Indexes().CreateOne(indexCtx, mongoTextIndex()); indexErr != nil {
+		log.Println("Unable to create the text index:", indexErr.Error())
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), 2*time.Second)
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(server))
+	// Books saved before versioning get version 0, so that their ETag can be matched
+	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelMigrate()
+	unversioned := bson.M{"version": bson.M{"$exists": false}}
+	if _, migrateErr := db.UpdateMany(migrateCtx, unversioned, bson.M{"$set": bson.M{"version": 0}}); migrateErr != nil {
+		log.Println("Unable to version the existing books:", migrateErr.Error())
+	}
 
-	if err != nil {
-		return nil, NewDatabaseOperationError(err.Error())
+	// Books saved before the Patrons rated them get no rating aggregates, so that they sort and filter as unrated
+	aggregateCtx, cancelAggregate := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelAggregate()
+	unaggregated := bson.M{"rating_count": bson.M{"$exists": false}}
+	noRatings := bson.M{"$set": bson.M{"rating_average": 0.0, "rating_count": 0}}
+	if _, migrateErr := db.UpdateMany(aggregateCtx, unaggregated, noRatings); migrateErr != nil {
+		log.Println("Unable to add the rating aggregates of the existing books:", migrateErr.Error())
 	}
 
-	db = client.Database(dbName).Collection(collection)
 	return &repo{}, nil
 }
+
+// connectMongo connects once to the MongoDB server and returns the database named by the environment,
+// shared by the Books and the other collections
+// It returns an API Error Response if failed
+func connectMongo() (*mongo.Database, *BookAPIError) {
+	mongoMu.Lock()
+	defer mongoMu.Unlock()
+
+	if mongoDatabase != nil {
+		return mongoDatabase, nil
+	}
+
+	server, err := lookupEnv("mongodb_url")
+	if err != nil {
+		return nil, err
+	}
+
+	dbName, err := lookupEnv("database_name")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ := context.WithTimeout(context.Background(), 2*time.Second)
+	client, connectErr := mongo.Connect(ctx, options.Client().ApplyURI(server))
+	if connectErr != nil {
+		return nil, NewDatabaseOperationError(connectErr.Error())
+	}
+
+	mongoDatabase = client.Database(dbName)
+	return mongoDatabase, nil
+}
+
+// conditionFailure returns why a conditional write changed no Book:
+// a Not Found error when the Book does not exist, a Precondition Failed error when it does not match the condition
+func conditionFailure(id string, findOne func(id string) (Book, *BookAPIError)) *BookAPIError {
+	if _, err := findOne(id); err != nil {
+		return err
+	}
+
+	return NewPreconditionFailedError(bookKind, id)
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// maxBodyBytes largest request body accepted, far above any valid Book
+const maxBodyBytes = 64 << 10
+
+// jsonMediaType media type of the Book request bodies
+const jsonMediaType = "application/json"
+
+// decodeJSON strictly decodes the JSON request body into v.
+// It returns an Unsupported Media Type error if the body is not application/json, a Payload Too Large error
+// if it exceeds maxBodyBytes and a Malformed Body error if it is not a single JSON document of known fields
+func decodeJSON(r *http.Request, v interface{}) *BookAPIError {
+	data, err := readBody(r, jsonMediaType)
+	if err != nil {
+		return err
+	}
+
+	return decodeStrict(data, v)
+}
+
+// readPatch returns the patch held by the request body, a JSON merge patch or a JSON patch depending on its media type
+func readPatch(r *http.Request) (BookPatch, *BookAPIError) {
+	data, err := readBody(r, mergePatchMediaType, jsonPatchMediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == jsonPatchMediaType {
+		return JSONPatch(data), nil
+	}
+
+	return MergePatch(data), nil
+}
+
+// decodeStrict decodes the single JSON document of data into v, rejecting fields v does not have
+func decodeStrict(data []byte, v interface{}) *BookAPIError {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if decodeErr := decoder.Decode(v); decodeErr != nil {
+		return toMalformedBodyError(decodeErr)
+	}
+
+	if _, trailingErr := decoder.Token(); trailingErr != io.EOF {
+		return NewMalformedBodyError("Request body must contain a single JSON document")
+	}
+
+	return nil
+}
+
+// readBody returns the request body after checking its media type is one of the supported ones and its size
+func readBody(r *http.Request, supported ...string) ([]byte, *BookAPIError) {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, parseErr := mime.ParseMediaType(contentType)
+	if parseErr != nil || !contains(supported, mediaType) {
+		return nil, NewUnsupportedMediaTypeError(contentType, strings.Join(supported, " or "))
+	}
+
+	if r.Body == nil {
+		return nil, NewMalformedBodyError("Request body is empty")
+	}
+
+	data, readErr := ioutil.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if readErr != nil {
+		return nil, NewMalformedBodyError(readErr.Error())
+	}
+
+	if len(data) > maxBodyBytes {
+		return nil, NewPayloadTooLargeError(maxBodyBytes)
+	}
+
+	return data, nil
+}
+
+// toMalformedBodyError describes why the body could not be decoded, naming the offending field when there is one
+func toMalformedBodyError(err error) *BookAPIError {
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return NewMalformedBodyError(fmt.Sprintf("Request body has malformed JSON at offset %d", e.Offset))
+	case *json.UnmarshalTypeError:
+		field := e.Field
+		if field == "" && e.Type == statusType {
+			// rejected by Status.UnmarshalJSON, which not every encoding/json names the field of
+			field = "status"
+		}
+		return NewMalformedFieldError(field, "type", fmt.Sprintf("%s must be %s", field, jsonTypeName(e.Type)))
+	}
+
+	if err == io.EOF {
+		return NewMalformedBodyError("Request body is empty")
+	}
+
+	if err == io.ErrUnexpectedEOF {
+		return NewMalformedBodyError("Request body has malformed JSON")
+	}
+
+	// encoding/json reports unknown fields as json: unknown field "name"
+	if field := strings.TrimPrefix(err.Error(), "json: unknown field "); field != err.Error() {
+		name := strings.Trim(field, `"`)
+		return NewMalformedFieldError(name, "unknown", fmt.Sprintf("%s is not a Book field", name))
+	}
+
+	return NewMalformedBodyError(err.Error())
+}
+
+// jsonTypeName returns the JSON type decoding into the Go type
+func jsonTypeName(t reflect.Type) string {
+	if t == statusType {
+		return "a status, i.e. CheckedIn"
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+
+	return "a string"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
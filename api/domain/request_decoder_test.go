@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"bytes"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newBodyRequest(contentType string, body string) *http.Request {
+	r, _ := http.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	return r
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var book Book
+	err := decodeJSON(newBodyRequest("application/json; charset=utf-8", `{"author": "Robert Martin", "rating": 3}`), &book)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Robert Martin", book.Author)
+	assert.Equal(t, 3.0, book.Rating)
+}
+
+func TestDecodeJSON_WithInvalidBodies(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		errorType   OperationError
+		fields      []FieldError
+	}{
+		{"missing content type", "", `{}`, UnsupportedMediaType, nil},
+		{"form content type", "application/x-www-form-urlencoded", `author=Robert`, UnsupportedMediaType, nil},
+		{"too large", ContentType, `{"author": "` + strings.Repeat("a", maxBodyBytes) + `"}`, PayloadTooLarge, nil},
+		{"empty", ContentType, ``, MalformedBody, nil},
+		{"syntax", ContentType, `{"author": }`, MalformedBody, nil},
+		{"truncated", ContentType, `{"author": "Robert`, MalformedBody, nil},
+		{"trailing document", ContentType, `{} {}`, MalformedBody, nil},
+		{"unknown field", ContentType, `{"auhtor": "Robert"}`, MalformedBody, []FieldError{{Field: "auhtor", Rule: "unknown", Message: "auhtor is not a Book field"}}},
+		{"wrong type", ContentType, `{"rating": "high"}`, MalformedBody, []FieldError{{Field: "rating", Rule: "type", Message: "rating must be a number"}}},
+		{"unknown status", ContentType, `{"status": "Stolen"}`, MalformedBody, []FieldError{{Field: "status", Rule: "type", Message: "status must be a status, i.e. CheckedIn"}}},
+	}
+
+	for _, c := range cases {
+		var book Book
+		err := decodeJSON(newBodyRequest(c.contentType, c.body), &book)
+		if assert.NotNil(t, err, c.name) {
+			assert.Equal(t, c.errorType, err.errorType, c.name)
+			assert.Equal(t, c.fields, err.fields, c.name)
+		}
+	}
+}
+
+func TestController_Create_WithUnsupportedMediaType(t *testing.T) {
+	bookController := NewController(NewMockService(gomock.NewController(t)))
+	r := newBodyRequest("text/plain", `{}`)
+	wr := httptest.NewRecorder()
+	bookController.Create(wr, r)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, wr.Code)
+	assert.True(t, bytes.Contains(wr.Body.Bytes(), []byte(`"code":"UnsupportedMediaType"`)))
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Links URLs of the listing pages, empty when there is no such page
+type Links struct {
+	Self  string `json:"self"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	First string `json:"first"`
+	Last  string `json:"last"`
+}
+
+// PageResponse envelope of a paginated listing of Books
+type PageResponse struct {
+	Data SearchHits `json:"data"`
+	// Page number of the page, omitted when paginating with a cursor
+	Page  int64 `json:"page,omitempty"`
+	Size  int64 `json:"size"`
+	Total int64 `json:"total"`
+	Links Links `json:"links"`
+	// DidYouMean closest author and title values when a fuzzy listing is empty
+	DidYouMean map[string]string `json:"did_you_mean,omitempty"`
+}
+
+// SearchResponse envelope of a page of full-text search results, most relevant first
+type SearchResponse struct {
+	Data  SearchHits `json:"data"`
+	Query string     `json:"query"`
+	Page  int64      `json:"page"`
+	Size  int64      `json:"size"`
+	Total int64      `json:"total"`
+	Links Links      `json:"links"`
+}
+
+// ReviewPageResponse envelope of a page of Reviews
+type ReviewPageResponse struct {
+	Data  Reviews `json:"data"`
+	Page  int64   `json:"page"`
+	Size  int64   `json:"size"`
+	Total int64   `json:"total"`
+	Links Links   `json:"links"`
+}
+
+// NewPageResponse returns the envelope of the page found for the request out of total matching Books.
+// Links keep the filters of the request, next and prev continue from the page cursors when paginating with a cursor
+func NewPageResponse(r *http.Request, request PageRequest, page ResultPage, total int64) PageResponse {
+	hits := make(SearchHits, 0, len(page.Books))
+	for _, book := range page.Books {
+		hits = append(hits, SearchHit{Book: book})
+	}
+
+	base := linkParams(r, request)
+	response := PageResponse{
+		Data:  hits,
+		Size:  request.Size,
+		Total: total,
+		Links: offsetLinks(r, base, request.Page, request.Size, total),
+	}
+
+	if request.After == nil && request.Before == nil {
+		response.Page = request.Page
+		return response
+	}
+
+	response.Links.Next, response.Links.Prev = "", ""
+	if page.Next != nil {
+		response.Links.Next = pageURL(r, base, "after="+url.QueryEscape(page.Next.Encode()))
+	}
+	if page.Prev != nil {
+		response.Links.Prev = pageURL(r, base, "before="+url.QueryEscape(page.Prev.Encode()))
+	}
+
+	return response
+}
+
+// NewFuzzyPageResponse returns the envelope of the page of Books similar to the fuzzy conditions of the request
+func NewFuzzyPageResponse(r *http.Request, request PageRequest, result FuzzyResult) PageResponse {
+	return PageResponse{
+		Data:       result.Hits,
+		Page:       request.Page,
+		Size:       request.Size,
+		Total:      result.Total,
+		Links:      offsetLinks(r, linkParams(r, request), request.Page, request.Size, result.Total),
+		DidYouMean: result.DidYouMean,
+	}
+}
+
+// NewSearchResponse returns the envelope of the search results found for the request out of total matching Books
+func NewSearchResponse(r *http.Request, request SearchRequest, hits SearchHits, total int64) SearchResponse {
+	base := []string{"q=" + url.QueryEscape(request.Text), "size=" + strconv.FormatInt(request.Size, 10)}
+	return SearchResponse{
+		Data:  hits,
+		Query: request.Text,
+		Page:  request.Page,
+		Size:  request.Size,
+		Total: total,
+		Links: offsetLinks(r, base, request.Page, request.Size, total),
+	}
+}
+
+// NewReviewPageResponse returns the envelope of the Reviews found for the request out of total matching Reviews.
+// Links keep the status and sort of the request
+func NewReviewPageResponse(r *http.Request, request ReviewRequest, reviews Reviews, total int64) ReviewPageResponse {
+	base := []string{}
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		if key := strings.SplitN(param, "=", 2)[0]; key != "" && key != "page" {
+			base = append(base, param)
+		}
+	}
+
+	return ReviewPageResponse{
+		Data:  reviews,
+		Page:  request.Page,
+		Size:  request.Size,
+		Total: total,
+		Links: offsetLinks(r, base, request.Page, request.Size, total),
+	}
+}
+
+// Header returns the RFC 8288 Link header of the pages
+func (links Links) Header() string {
+	header := []string{}
+	for _, link := range []struct{ rel, url string }{
+		{"next", links.Next},
+		{"prev", links.Prev},
+		{"first", links.First},
+		{"last", links.Last},
+	} {
+		if link.url != "" {
+			header = append(header, fmt.Sprintf(`<%s>; rel="%s"`, link.url, link.rel))
+		}
+	}
+
+	return strings.Join(header, ", ")
+}
+
+// offsetLinks returns the links of the numbered pages of size out of total
+func offsetLinks(r *http.Request, params []string, page int64, size int64, total int64) Links {
+	lastPage := (total + size - 1) / size
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := Links{
+		Self:  r.URL.RequestURI(),
+		First: pageURL(r, params, "page=1"),
+		Last:  pageURL(r, params, "page="+strconv.FormatInt(lastPage, 10)),
+	}
+
+	if page < lastPage {
+		links.Next = pageURL(r, params, "page="+strconv.FormatInt(page+1, 10))
+	}
+	if prevPage := page - 1; prevPage > 0 {
+		if prevPage > lastPage {
+			prevPage = lastPage
+		}
+		links.Prev = pageURL(r, params, "page="+strconv.FormatInt(prevPage, 10))
+	}
+
+	return links
+}
+
+// linkParams returns the raw query params of the request that carry over to the other pages.
+// The sort of a cursor is made explicit since the pages are not requested with it
+func linkParams(r *http.Request, request PageRequest) []string {
+	params := []string{}
+	sorted := false
+	for _, param := range strings.Split(r.URL.RawQuery, "&") {
+		key := strings.SplitN(param, "=", 2)[0]
+		switch key {
+		case "", "page", "after", "before":
+			continue
+		case "sort":
+			sorted = true
+		}
+		params = append(params, param)
+	}
+
+	if !sorted && request.After != nil {
+		params = append(params, "sort="+url.QueryEscape(formatSort(request.After.Sort)))
+	}
+	if !sorted && request.Before != nil {
+		params = append(params, "sort="+url.QueryEscape(formatSort(request.Before.Sort)))
+	}
+
+	return params
+}
+
+func pageURL(r *http.Request, params []string, position string) string {
+	return r.URL.Path + "?" + strings.Join(append(append([]string{}, params...), position), "&")
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/temesxgn/redeam/api/utils"
+	"net/http"
+	"strconv"
+)
+
+// A ReviewController - action handler for the Review API of a Book and its moderation
+type ReviewController struct {
+	service ReviewService
+}
+
+// GetAll handles REST API Get '/{id}/reviews' Endpoint, a page of the approved Reviews of the Book, newest first
+func (c *ReviewController) GetAll(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	queryBuilder := QueryBuilder{}
+	request, queryErr := queryBuilder.GetReviewParams(r)
+	if queryErr != nil {
+		writeError(w, r, queryErr)
+		return
+	}
+
+	reviews, total, err := c.service.FindAll(id, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeReviewPage(w, r, request, reviews, total)
+}
+
+// GetByID handles REST API Get '/{id}/reviews/{reviewID}' Endpoint
+func (c *ReviewController) GetByID(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	reviewID := chi.URLParam(r, "reviewID")
+
+	review, err := c.service.FindOne(id, reviewID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(review)
+	responseBuilder.OK(w, data)
+}
+
+// Create handles REST API POST '/{id}/reviews' Endpoint, the Review waits for moderation
+func (c *ReviewController) Create(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+
+	var review Review
+	if err := decodeJSON(r, &review); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reviewID, createError := c.service.Create(id, review)
+	if createError != nil {
+		writeError(w, r, createError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(reviewID))
+}
+
+// Update handles REST API PUT '/{id}/reviews/{reviewID}' Endpoint, only the author edits the Review
+func (c *ReviewController) Update(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	reviewID := chi.URLParam(r, "reviewID")
+
+	var review Review
+	if err := decodeJSON(r, &review); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if updateError := c.service.Update(id, reviewID, review); updateError != nil {
+		writeError(w, r, updateError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(""))
+}
+
+// Delete handles REST API DELETE '/{id}/reviews/{reviewID}?patron_id={patronID}' Endpoint, only the author deletes
+// the Review
+func (c *ReviewController) Delete(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	reviewID := chi.URLParam(r, "reviewID")
+
+	if deleteError := c.service.Delete(id, reviewID, r.URL.Query().Get("patron_id")); deleteError != nil {
+		writeError(w, r, deleteError)
+		return
+	}
+
+	responseBuilder.OK(w, []byte(""))
+}
+
+// Vote handles REST API PUT '/{id}/reviews/{reviewID}/helpful' Endpoint, responding with the Review and its votes
+func (c *ReviewController) Vote(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	reviewID := chi.URLParam(r, "reviewID")
+
+	var request VoteRequest
+	if err := decodeJSON(r, &request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	review, err := c.service.Vote(id, reviewID, request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(review)
+	responseBuilder.OK(w, data)
+}
+
+// Queue handles REST API Get '/reviews' Endpoint, a page of the moderation queue, the pending Reviews oldest first
+func (c *ReviewController) Queue(w http.ResponseWriter, r *http.Request) {
+	queryBuilder := QueryBuilder{}
+	request, queryErr := queryBuilder.GetReviewParams(r)
+	if queryErr != nil {
+		writeError(w, r, queryErr)
+		return
+	}
+
+	reviews, total, err := c.service.Queue(request)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeReviewPage(w, r, request, reviews, total)
+}
+
+// Moderate handles REST API PUT '/reviews/{id}/status/{status}' Endpoint, approving or rejecting the Review
+func (c *ReviewController) Moderate(w http.ResponseWriter, r *http.Request) {
+	responseBuilder := utils.ResponseBuilder{}
+	id := chi.URLParam(r, "id")
+	status := ReviewStatus(chi.URLParam(r, "status"))
+
+	review, err := c.service.Moderate(id, status)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	data, _ := json.Marshal(review)
+	responseBuilder.OK(w, data)
+}
+
+// writeReviewPage responds with the page of Reviews found for the request out of total matching Reviews
+func writeReviewPage(w http.ResponseWriter, r *http.Request, request ReviewRequest, reviews Reviews, total int64) {
+	responseBuilder := utils.ResponseBuilder{}
+	response := NewReviewPageResponse(r, request, reviews, total)
+	w.Header().Set("Link", response.Links.Header())
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+
+	data, _ := json.Marshal(response)
+	responseBuilder.OK(w, data)
+}
+
+// NewReviewController Creates ReviewController instance
+func NewReviewController(service ReviewService) *ReviewController {
+	return &ReviewController{service: service}
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReviewController_GetAll(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	request := ReviewRequest{
+		FindOptions: FindOptions{Sort: []Sort{{Field: "helpful_votes", Order: DESC}, {Field: "_id", Order: ASC}}, Limit: 2, Skip: 2},
+		Page:        2,
+		Size:        2,
+	}
+	reviewService := NewMockReviewService(gomock.NewController(t))
+	reviewService.EXPECT().FindAll(id, request).Return(Reviews{{BookID: id, Title: "Timeless", Status: ReviewApproved}}, int64(5), nil)
+
+	router := chi.NewRouter()
+	router.Get("/books/{id}/reviews", NewReviewController(reviewService).GetAll)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(fmt.Sprintf("%s/books/%s/reviews?sort=-helpful_votes&size=2&page=2", server.URL, id))
+	defer closeBody(res.Body)
+
+	var page ReviewPageResponse
+	_ = json.NewDecoder(res.Body).Decode(&page)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "5", res.Header.Get("X-Total-Count"))
+	assert.Equal(t, int64(5), page.Total)
+	assert.Len(t, page.Data, 1)
+	path := "/books/" + id + "/reviews?sort=-helpful_votes&size=2&page="
+	assert.Equal(t, path+"3", page.Links.Next)
+	assert.Equal(t, path+"1", page.Links.Prev)
+	assert.Equal(t, path+"3", page.Links.Last)
+
+	invalid, _ := http.Get(fmt.Sprintf("%s/books/%s/reviews?sort=title", server.URL, id))
+	defer closeBody(invalid.Body)
+	assert.Equal(t, http.StatusBadRequest, invalid.StatusCode)
+}
+
+func TestReviewController_Update_ByAnotherPatron(t *testing.T) {
+	id, reviewID, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	reviewService := NewMockReviewService(gomock.NewController(t))
+	reviewService.EXPECT().Update(id, reviewID, Review{PatronID: patronID, Title: "Mine", Text: "Now."}).
+		Return(NewNotAuthorError(patronID, reviewID))
+
+	router := chi.NewRouter()
+	router.Put("/books/{id}/reviews/{reviewID}", NewReviewController(reviewService).Update)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/books/%s/reviews/%s", server.URL, id, reviewID),
+		strings.NewReader(`{"patron_id": "`+patronID+`", "title": "Mine", "text": "Now."}`))
+	req.Header.Set("Content-Type", ContentType)
+	res, _ := http.DefaultClient.Do(req)
+	defer closeBody(res.Body)
+
+	var problem Problem
+	_ = json.NewDecoder(res.Body).Decode(&problem)
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Equal(t, NotAuthor, problem.Code)
+}
+
+func TestReviewController_Moderate(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	reviewService := NewMockReviewService(gomock.NewController(t))
+	reviewService.EXPECT().Queue(ReviewRequest{Status: ReviewPending, FindOptions: FindOptions{Limit: defaultSize}, Page: 1, Size: defaultSize}).
+		Return(Reviews{{Title: "Timeless", Status: ReviewPending}}, int64(1), nil)
+	reviewService.EXPECT().Moderate(id, ReviewRejected).Return(Review{Title: "Timeless", Status: ReviewRejected}, nil)
+
+	reviewController := NewReviewController(reviewService)
+	router := chi.NewRouter()
+	router.Get("/reviews", reviewController.Queue)
+	router.Put("/reviews/{id}/status/{status}", reviewController.Moderate)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	res, _ := http.Get(server.URL + "/reviews?status=pending")
+	defer closeBody(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	invalid, _ := http.Get(server.URL + "/reviews?status=hidden")
+	defer closeBody(invalid.Body)
+	assert.Equal(t, http.StatusBadRequest, invalid.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/reviews/%s/status/rejected", server.URL, id), nil)
+	moderated, _ := http.DefaultClient.Do(req)
+	defer closeBody(moderated.Body)
+
+	var review Review
+	_ = json.NewDecoder(moderated.Body).Decode(&review)
+	assert.Equal(t, http.StatusOK, moderated.StatusCode)
+	assert.Equal(t, ReviewRejected, review.Status)
+}
@@ -0,0 +1,132 @@
+package domain
+
+type reviewRepo struct {
+	collection collection
+}
+
+// FindAll Queries the reviews collection with optional filters and find options
+// It returns a list of Reviews or an API Error Response
+func (r *reviewRepo) FindAll(filter Filter, findOptions FindOptions) (Reviews, *BookAPIError) {
+	reviews := Reviews{}
+	if err := r.collection.find(filter, findOptions, &reviews); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// Count Counts the Reviews matching the filter
+// It returns the number of Reviews or an API Error Response
+func (r *reviewRepo) Count(filter Filter) (int64, *BookAPIError) {
+	return r.collection.count(filter)
+}
+
+// FindOne Queries the reviews collection for a specific Review
+// It returns one Review or an API Error Response
+func (r *reviewRepo) FindOne(id string) (Review, *BookAPIError) {
+	reviews, err := r.FindAll(idFilter(id), FindOptions{Limit: 1})
+	if err != nil {
+		return Review{}, err
+	}
+
+	if len(reviews) == 0 {
+		return Review{}, NewReviewNotFoundError(id)
+	}
+
+	return reviews[0], nil
+}
+
+// Update sets the fields of the Review with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *reviewRepo) Update(id string, condition Filter, fields Fields) *BookAPIError {
+	updated, err := r.collection.update(id, condition, fields)
+	if err != nil {
+		return err
+	}
+
+	if !updated {
+		return NewReviewNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Delete removes the Review with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *reviewRepo) Delete(id string, condition Filter) *BookAPIError {
+	deleted, err := r.collection.delete(id, condition)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return NewReviewNotFoundError(id)
+	}
+
+	return nil
+}
+
+// Save Saves the Review Payload, generating an ID when it has none
+// It returns the persisted Review ID or an API Error Response if failed
+func (r *reviewRepo) Save(review Review) (string, *BookAPIError) {
+	return r.collection.insert(review)
+}
+
+// NewReviewRepository Initializes the reviews repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewReviewRepository() (ReviewRepository, *BookAPIError) {
+	collection, err := newCollection("reviews")
+	if err != nil {
+		return nil, err
+	}
+
+	return &reviewRepo{collection: collection}, nil
+}
+
+type reviewVoteRepo struct {
+	collection collection
+}
+
+// FindAll Queries the review votes collection with optional filters and find options
+// It returns a list of ReviewVotes or an API Error Response
+func (r *reviewVoteRepo) FindAll(filter Filter, findOptions FindOptions) (ReviewVotes, *BookAPIError) {
+	votes := ReviewVotes{}
+	if err := r.collection.find(filter, findOptions, &votes); err != nil {
+		return nil, err
+	}
+
+	return votes, nil
+}
+
+// Count Counts the ReviewVotes matching the filter
+// It returns the number of ReviewVotes or an API Error Response
+func (r *reviewVoteRepo) Count(filter Filter) (int64, *BookAPIError) {
+	return r.collection.count(filter)
+}
+
+// Delete removes the ReviewVote with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *reviewVoteRepo) Delete(id string, condition Filter) *BookAPIError {
+	if _, err := r.collection.delete(id, condition); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Save Saves the ReviewVote Payload, generating an ID when it has none
+// It returns the persisted ReviewVote ID or an API Error Response if failed
+func (r *reviewVoteRepo) Save(vote ReviewVote) (string, *BookAPIError) {
+	return r.collection.insert(vote)
+}
+
+// NewReviewVoteRepository Initializes the review votes repository in the storage engine of the Books
+// It returns an API Error Response if failed
+func NewReviewVoteRepository() (ReviewVoteRepository, *BookAPIError) {
+	collection, err := newCollection("review_votes")
+	if err != nil {
+		return nil, err
+	}
+
+	return &reviewVoteRepo{collection: collection}, nil
+}
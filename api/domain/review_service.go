@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type reviewService struct {
+	repository Repository
+	patrons    PatronRepository
+	reviews    ReviewRepository
+	votes      ReviewVoteRepository
+	// now current time of the reviews, in UTC to the millisecond the stores keep
+	now func() time.Time
+}
+
+func (s *reviewService) FindAll(bookID string, request ReviewRequest) (Reviews, int64, *BookAPIError) {
+	if _, err := s.repository.FindOne(bookID); err != nil {
+		return nil, 0, err
+	}
+
+	if len(request.Status) == 0 {
+		request.Status = ReviewApproved
+	}
+
+	filter := AllOf(NewQueryFilter("book_id", Equals, bookID), NewQueryFilter("status", Equals, string(request.Status)))
+	return s.page(filter, request, newestReviewsFirst)
+}
+
+func (s *reviewService) FindOne(bookID string, id string) (Review, *BookAPIError) {
+	review, err := s.reviews.FindOne(id)
+	if err != nil {
+		return Review{}, err
+	}
+
+	if review.BookID != bookID {
+		return Review{}, NewReviewNotFoundError(id)
+	}
+
+	return review, nil
+}
+
+func (s *reviewService) Create(bookID string, review Review) (string, *BookAPIError) {
+	if validationError := review.Validate(); validationError != nil {
+		return "", validationError
+	}
+
+	if _, err := s.repository.FindOne(bookID); err != nil {
+		return "", err
+	}
+
+	if err := s.checkPatron(review.PatronID); err != nil {
+		return "", err
+	}
+
+	patronFilter := AllOf(NewQueryFilter("book_id", Equals, bookID), NewQueryFilter("patron_id", Equals, review.PatronID))
+	written, err := s.reviews.FindAll(patronFilter, FindOptions{Limit: 1})
+	if err != nil {
+		return "", err
+	}
+
+	if len(written) > 0 {
+		return "", NewReviewExistsError(review.PatronID, bookID)
+	}
+
+	// a new Review waits for moderation, its votes are only counted once it is listed
+	now := s.now()
+	id, err := s.reviews.Save(Review{BookID: bookID, PatronID: review.PatronID, Title: review.Title, Text: review.Text,
+		Status: ReviewPending, CreatedAt: now, UpdatedAt: now})
+	if err != nil && err.errorType == ExistingRecord {
+		// written by the Patron since it was checked
+		return "", NewReviewExistsError(review.PatronID, bookID)
+	}
+
+	return id, err
+}
+
+func (s *reviewService) Update(bookID string, id string, review Review) *BookAPIError {
+	if validationError := review.Validate(); validationError != nil {
+		return validationError
+	}
+
+	current, err := s.FindOne(bookID, id)
+	if err != nil {
+		return err
+	}
+
+	if current.PatronID != review.PatronID {
+		return NewNotAuthorError(review.PatronID, id)
+	}
+
+	// the edited text has not been moderated yet
+	fields := Fields{"title": review.Title, "text": review.Text, "status": string(ReviewPending), "updated_at": s.now()}
+	return s.reviews.Update(id, Filter{}, fields)
+}
+
+func (s *reviewService) Delete(bookID string, id string, patronID string) *BookAPIError {
+	if len(patronID) == 0 {
+		return NewInvalidFieldError("patron_id", "required", "patron_id cannot be blank")
+	}
+
+	current, err := s.FindOne(bookID, id)
+	if err != nil {
+		return err
+	}
+
+	if current.PatronID != patronID {
+		return NewNotAuthorError(patronID, id)
+	}
+
+	if err := s.reviews.Delete(id, Filter{}); err != nil {
+		return err
+	}
+
+	votes, err := s.votes.FindAll(NewQueryFilter("review_id", Equals, id), FindOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, vote := range votes {
+		if err := s.votes.Delete(vote.ID.Hex(), Filter{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *reviewService) Vote(bookID string, id string, request VoteRequest) (Review, *BookAPIError) {
+	patronID := request.PatronID
+	if len(patronID) == 0 {
+		return Review{}, NewInvalidFieldError("patron_id", "required", "patron_id cannot be blank")
+	}
+
+	review, err := s.FindOne(bookID, id)
+	if err != nil {
+		return Review{}, err
+	}
+
+	if review.Status != ReviewApproved {
+		return Review{}, NewValidationError(fmt.Sprintf("Review %s is not approved", id))
+	}
+
+	if review.PatronID == patronID {
+		return Review{}, NewValidationError(fmt.Sprintf("Patron %s cannot vote for their own review", patronID))
+	}
+
+	if err := s.checkPatron(patronID); err != nil {
+		return Review{}, err
+	}
+
+	reviewFilter := NewQueryFilter("review_id", Equals, id)
+	voted, err := s.votes.FindAll(AllOf(reviewFilter, NewQueryFilter("patron_id", Equals, patronID)), FindOptions{Limit: 1})
+	if err != nil {
+		return Review{}, err
+	}
+
+	if len(voted) > 0 {
+		return Review{}, NewVoteExistsError(patronID, id)
+	}
+
+	if _, err := s.votes.Save(ReviewVote{ReviewID: id, PatronID: patronID, VotedAt: s.now()}); err != nil {
+		if err.errorType == ExistingRecord {
+			// voted by the Patron since it was checked
+			return Review{}, NewVoteExistsError(patronID, id)
+		}
+		return Review{}, err
+	}
+
+	count, err := s.votes.Count(reviewFilter)
+	if err != nil {
+		return Review{}, err
+	}
+
+	// votes are only added, so the highest count is the latest: the count only replaces a lower one, a concurrent
+	// vote that counted after this one having already stored its higher count
+	fewer := NewQueryFilter("helpful_votes", LessThan, count)
+	updateErr := s.reviews.Update(id, fewer, Fields{"helpful_votes": count})
+	if updateErr == nil {
+		review.HelpfulVotes = int(count)
+		return review, nil
+	}
+
+	if updateErr.errorType != NotFoundError {
+		return Review{}, updateErr
+	}
+
+	return s.reviews.FindOne(id)
+}
+
+func (s *reviewService) Queue(request ReviewRequest) (Reviews, int64, *BookAPIError) {
+	if len(request.Status) == 0 {
+		request.Status = ReviewPending
+	}
+
+	return s.page(NewQueryFilter("status", Equals, string(request.Status)), request, oldestReviewsFirst)
+}
+
+func (s *reviewService) Moderate(id string, status ReviewStatus) (Review, *BookAPIError) {
+	if status != ReviewApproved && status != ReviewRejected {
+		return Review{}, NewInvalidFieldError("status", "in", "status must be approved or rejected")
+	}
+
+	review, err := s.reviews.FindOne(id)
+	if err != nil {
+		return Review{}, err
+	}
+
+	if review.Status != ReviewPending {
+		return Review{}, NewModerationConflictError(id)
+	}
+
+	// the text moderated is the one read, an edit since puts the Review back in the queue with another updated_at
+	read := AllOf(NewQueryFilter("status", Equals, string(ReviewPending)), NewQueryFilter("updated_at", Equals, review.UpdatedAt))
+	moderatedAt := s.now()
+	if err := s.reviews.Update(id, read, Fields{"status": string(status), "moderated_at": moderatedAt}); err != nil {
+		if err.errorType == NotFoundError {
+			return Review{}, NewModerationConflictError(id)
+		}
+		return Review{}, err
+	}
+
+	review.Status, review.ModeratedAt = status, &moderatedAt
+	return review, nil
+}
+
+// page returns the Reviews matching the filter in the page of the request, sorted in the order unless the request
+// sorts them, with the number of matching Reviews
+func (s *reviewService) page(filter Filter, request ReviewRequest, order []Sort) (Reviews, int64, *BookAPIError) {
+	options := request.FindOptions
+	if len(options.Sort) == 0 {
+		options.Sort = order
+	}
+
+	reviews, err := s.reviews.FindAll(filter, options)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.reviews.Count(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, total, nil
+}
+
+// checkPatron returns a Validation Error if the Patron does not exist
+func (s *reviewService) checkPatron(patronID string) *BookAPIError {
+	if _, err := s.patrons.FindOne(patronID); err != nil {
+		if err.errorType == NotFoundError {
+			return NewInvalidFieldError("patron_id", "exists", fmt.Sprintf("Patron %s does not exist", patronID))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// newestReviewsFirst sort of the Reviews of a Book, the latest written first
+var newestReviewsFirst = []Sort{{Field: "created_at", Order: DESC}, {Field: "_id", Order: DESC}}
+
+// oldestReviewsFirst sort of the moderation queue, the Reviews waiting the longest since they were written or
+// edited first
+var oldestReviewsFirst = []Sort{{Field: "updated_at", Order: ASC}, {Field: "_id", Order: ASC}}
+
+// NewReviewService creates instance of the Review service
+func NewReviewService(repository Repository, patrons PatronRepository, reviews ReviewRepository,
+	votes ReviewVoteRepository) ReviewService {
+	return &reviewService{repository: repository, patrons: patrons, reviews: reviews, votes: votes, now: loanTime}
+}
@@ -0,0 +1,226 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// testReviews exercises the reviews and the helpful votes stored in the collections, from writing to moderation
+func testReviews(t *testing.T, reviews collection, votes collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+	grace, _ := patrons.Save(Patron{Name: "Grace Hopper", Email: "grace@example.com"})
+	barbara, _ := patrons.Save(Patron{Name: "Barbara Liskov", Email: "barbara@example.com"})
+
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	reviewService := &reviewService{repository: repo, patrons: patrons, reviews: &reviewRepo{collection: reviews},
+		votes: &reviewVoteRepo{collection: votes}, now: func() time.Time { return now }}
+
+	first, err := reviewService.Create(id, Review{PatronID: ada, Title: "Timeless", Text: "Every chapter holds up.", HelpfulVotes: 9})
+	assert.Nil(t, err)
+	now = now.Add(time.Minute)
+	second, err := reviewService.Create(id, Review{PatronID: alan, Title: "Dated", Text: "The Java examples show their age."})
+	assert.Nil(t, err)
+
+	created, _ := reviewService.FindOne(id, first)
+	assert.Equal(t, ReviewPending, created.Status)
+	assert.Equal(t, 0, created.HelpfulVotes)
+
+	_, err = reviewService.Create(id, Review{PatronID: ada, Title: "Again", Text: "Still timeless."})
+	assert.Equal(t, ExistingRecord, err.errorType)
+	_, err = reviewService.Create(id, Review{PatronID: grace})
+	assert.Equal(t, []FieldError{{Field: "text", Rule: "required", Message: "cannot be blank"},
+		{Field: "title", Rule: "required", Message: "cannot be blank"}}, err.fields)
+	_, err = reviewService.Create(id, Review{PatronID: "5ca7c76f9287bd3832d96f15", Title: "Who", Text: "Unknown patron."})
+	assert.Equal(t, []FieldError{{Field: "patron_id", Rule: "exists", Message: "Patron 5ca7c76f9287bd3832d96f15 does not exist"}}, err.fields)
+
+	// pending Reviews wait in the queue, oldest first, and are not listed with the Book
+	listed, total, err := reviewService.FindAll(id, ReviewRequest{})
+	assert.Nil(t, err)
+	assert.Empty(t, listed)
+	assert.Equal(t, int64(0), total)
+
+	queue, total, err := reviewService.Queue(ReviewRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, queue, 2) {
+		assert.Equal(t, first, queue[0].ID.Hex())
+	}
+
+	_, err = reviewService.Moderate(first, ReviewPending)
+	assert.Equal(t, ValidationError, err.errorType)
+	_, err = reviewService.Moderate("5ca7c76f9287bd3832d96f15", ReviewApproved)
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	_, err = reviewService.Vote(id, first, VoteRequest{PatronID: alan})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	approved, err := reviewService.Moderate(first, ReviewApproved)
+	assert.Nil(t, err)
+	assert.Equal(t, ReviewApproved, approved.Status)
+	_, _ = reviewService.Moderate(second, ReviewApproved)
+
+	// helpful votes, one per Patron and never from the author
+	voted, err := reviewService.Vote(id, first, VoteRequest{PatronID: alan})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, voted.HelpfulVotes)
+	voted, _ = reviewService.Vote(id, first, VoteRequest{PatronID: grace})
+	assert.Equal(t, 2, voted.HelpfulVotes)
+	_, err = reviewService.Vote(id, first, VoteRequest{PatronID: grace})
+	assert.Equal(t, ExistingRecord, err.errorType)
+	_, err = reviewService.Vote(id, first, VoteRequest{PatronID: ada})
+	assert.Equal(t, ValidationError, err.errorType)
+
+	// a vote stored its higher count after this one counted, the lower count does not replace it
+	assert.Nil(t, reviewService.reviews.Update(first, Filter{}, Fields{"helpful_votes": 4}))
+	voted, err = reviewService.Vote(id, first, VoteRequest{PatronID: barbara})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, voted.HelpfulVotes)
+	assert.Nil(t, reviewService.reviews.Update(first, Filter{}, Fields{"helpful_votes": 3}))
+
+	listed, total, _ = reviewService.FindAll(id, ReviewRequest{})
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, listed, 2) {
+		assert.Equal(t, second, listed[0].ID.Hex())
+	}
+
+	mostHelpful := ReviewRequest{FindOptions: FindOptions{Sort: []Sort{{Field: "helpful_votes", Order: DESC}, {Field: "_id", Order: ASC}}, Limit: 1}}
+	listed, total, _ = reviewService.FindAll(id, mostHelpful)
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, first, listed[0].ID.Hex())
+		assert.Equal(t, 3, listed[0].HelpfulVotes)
+	}
+
+	// a moderated Review is not moderated again
+	_, err = reviewService.Moderate(first, ReviewRejected)
+	assert.Equal(t, ModerationConflict, err.errorType)
+
+	// only the author edits or deletes a Review, an edit goes back to moderation
+	assert.Equal(t, NotAuthor, reviewService.Update(id, second, Review{PatronID: ada, Title: "Dated", Text: "Mine now."}).errorType)
+	assert.Nil(t, reviewService.Update(id, second, Review{PatronID: alan, Title: "Dated", Text: "The Java examples show their age, the advice does not."}))
+	edited, _ := reviewService.FindOne(id, second)
+	assert.Equal(t, ReviewPending, edited.Status)
+	assert.Equal(t, "The Java examples show their age, the advice does not.", edited.Text)
+
+	_, total, _ = reviewService.FindAll(id, ReviewRequest{Status: ReviewPending})
+	assert.Equal(t, int64(1), total)
+
+	assert.Equal(t, NotAuthor, reviewService.Delete(id, first, alan).errorType)
+	assert.Equal(t, ValidationError, reviewService.Delete(id, first, "").errorType)
+	assert.Nil(t, reviewService.Delete(id, first, ada))
+	_, err = reviewService.FindOne(id, first)
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	remaining, _ := reviewService.votes.Count(NewQueryFilter("review_id", Equals, first))
+	assert.Equal(t, int64(0), remaining)
+
+	_, err = reviewService.FindOne("5ca7c76f9287bd3832d96f15", second)
+	assert.Equal(t, NotFoundError, err.errorType)
+	_, _, err = reviewService.FindAll("5ca7c76f9287bd3832d96f15", ReviewRequest{})
+	assert.Equal(t, NotFoundError, err.errorType)
+}
+
+func TestReviewService(t *testing.T) {
+	testReviews(t, newMemoryCollection(nil, uniqueKeys["reviews"]...), newMemoryCollection(nil, uniqueKeys["review_votes"]...))
+}
+
+func TestReviewService_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	reviews, votes := newSQLiteCollection(t, path, "reviews"), newSQLiteCollection(t, path, "review_votes")
+	testReviews(t, reviews, votes)
+}
+
+// staleReviews returns the Reviews as they were read before, as when they change while staff read them
+type staleReviews struct {
+	ReviewRepository
+	read map[string]Review
+}
+
+func (r *staleReviews) FindOne(id string) (Review, *BookAPIError) {
+	if review, ok := r.read[id]; ok {
+		return review, nil
+	}
+
+	return r.ReviewRepository.FindOne(id)
+}
+
+// missedReviews finds no Reviews, as when a Review of the Patron is saved right after looking for it
+type missedReviews struct {
+	ReviewRepository
+}
+
+func (r *missedReviews) FindAll(filter Filter, findOptions FindOptions) (Reviews, *BookAPIError) {
+	return Reviews{}, nil
+}
+
+// missedVotes finds no votes, as when a vote of the Patron is saved right after looking for it
+type missedVotes struct {
+	ReviewVoteRepository
+}
+
+func (r *missedVotes) FindAll(filter Filter, findOptions FindOptions) (ReviewVotes, *BookAPIError) {
+	return ReviewVotes{}, nil
+}
+
+// testConcurrentReviews exercises Reviews written, voted for and moderated concurrently, stored in the collections
+func testConcurrentReviews(t *testing.T, reviews collection, votes collection) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	reviewService := &reviewService{repository: repo, patrons: patrons, reviews: &reviewRepo{collection: reviews},
+		votes: &reviewVoteRepo{collection: votes}, now: func() time.Time { return now }}
+
+	first, _ := reviewService.Create(id, Review{PatronID: ada, Title: "Timeless", Text: "Every chapter holds up."})
+	read, _ := reviewService.reviews.FindOne(first)
+
+	// the author edits the text staff are reading, they cannot approve the text they did not read
+	now = now.Add(time.Minute)
+	assert.Nil(t, reviewService.Update(id, first, Review{PatronID: ada, Title: "Timeless", Text: "Buy it, whatever it costs."}))
+	stored := reviewService.reviews
+	reviewService.reviews = &staleReviews{ReviewRepository: stored, read: map[string]Review{first: read}}
+	_, err := reviewService.Moderate(first, ReviewApproved)
+	assert.Equal(t, NewModerationConflictError(first), err)
+
+	reviewService.reviews = stored
+
+	approved, err := reviewService.Moderate(first, ReviewApproved)
+	assert.Nil(t, err)
+	assert.Equal(t, "Buy it, whatever it costs.", approved.Text)
+
+	// the unique indexes keep a single Review and a single vote of a Patron
+	_, err = reviewService.Vote(id, first, VoteRequest{PatronID: alan})
+	assert.Nil(t, err)
+	reviewService.votes = &missedVotes{ReviewVoteRepository: reviewService.votes}
+	_, err = reviewService.Vote(id, first, VoteRequest{PatronID: alan})
+	assert.Equal(t, NewVoteExistsError(alan, first), err)
+
+	reviewService.reviews = &missedReviews{ReviewRepository: reviewService.reviews}
+	_, err = reviewService.Create(id, Review{PatronID: ada, Title: "Again", Text: "Still timeless."})
+	assert.Equal(t, NewReviewExistsError(ada, id), err)
+
+	total, _ := reviews.count(Filter{})
+	assert.Equal(t, int64(1), total)
+}
+
+func TestReviewService_Concurrently(t *testing.T) {
+	testConcurrentReviews(t, newMemoryCollection(nil, uniqueKeys["reviews"]...), newMemoryCollection(nil, uniqueKeys["review_votes"]...))
+}
+
+func TestReviewService_Concurrently_WithSQL(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	reviews, votes := newSQLiteCollection(t, path, "reviews"), newSQLiteCollection(t, path, "review_votes")
+	testConcurrentReviews(t, reviews, votes)
+}
@@ -0,0 +1,278 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rsqlOperators RSQL / FIQL comparison operators, longest first
+var rsqlOperators = []struct {
+	token    string
+	operator QueryOperator
+	negate   bool
+}{
+	{"=out=", In, true},
+	{"=in=", In, false},
+	{"=lt=", LessThan, false},
+	{"=le=", LessThanOrEqual, false},
+	{"=gt=", GreaterThan, false},
+	{"=ge=", GreaterThanOrEqual, false},
+	{"==", Equals, false},
+	{"!=", DoesNotEqual, false},
+	{"<=", LessThanOrEqual, false},
+	{">=", GreaterThanOrEqual, false},
+	{"<", LessThan, false},
+	{">", GreaterThan, false},
+}
+
+// rsqlReserved characters that end an unquoted value
+const rsqlReserved = "\"'();,=!<> "
+
+// ParseRSQL parses a RSQL / FIQL expression such as author=="Robert Martin";rating=ge=2,status==1 into a Filter.
+// ; is AND, , is OR (AND binds tighter) and parentheses group. Fields are validated against the Book fields.
+// A value of == starting and/or ending with * matches a prefix or a substring.
+// It returns a Validation Error with the position of the first syntax error
+func ParseRSQL(expression string) (Filter, *BookAPIError) {
+	parser := &rsqlParser{input: expression}
+	filter, err := parser.parseOr()
+	if err != nil {
+		return Filter{}, err
+	}
+
+	parser.skipSpaces()
+	if !parser.done() {
+		return Filter{}, parser.errorf("unexpected character %q", parser.peek())
+	}
+
+	return filter, nil
+}
+
+type rsqlParser struct {
+	input    string
+	position int
+}
+
+func (p *rsqlParser) parseOr() (Filter, *BookAPIError) {
+	filters := []Filter{}
+	for {
+		filter, err := p.parseAnd()
+		if err != nil {
+			return Filter{}, err
+		}
+		filters = append(filters, filter)
+
+		if !p.consume(",") {
+			return AnyOf(filters...), nil
+		}
+	}
+}
+
+func (p *rsqlParser) parseAnd() (Filter, *BookAPIError) {
+	filters := []Filter{}
+	for {
+		filter, err := p.parseConstraint()
+		if err != nil {
+			return Filter{}, err
+		}
+		filters = append(filters, filter)
+
+		if !p.consume(";") {
+			return AllOf(filters...), nil
+		}
+	}
+}
+
+func (p *rsqlParser) parseConstraint() (Filter, *BookAPIError) {
+	if p.consume("(") {
+		filter, err := p.parseOr()
+		if err != nil {
+			return Filter{}, err
+		}
+
+		if !p.consume(")") {
+			return Filter{}, p.errorf("expected )")
+		}
+
+		return filter, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *rsqlParser) parseComparison() (Filter, *BookAPIError) {
+	p.skipSpaces()
+	start := p.position
+	for !p.done() && isSelectorChar(p.peek()) {
+		p.position++
+	}
+
+	field := p.input[start:p.position]
+	if field == "" {
+		return Filter{}, p.errorf("expected field name")
+	}
+
+	if !IsBookField(field) {
+		p.position = start
+		return Filter{}, p.errorf("unknown field %s", field)
+	}
+
+	p.skipSpaces()
+	var operator QueryOperator
+	var negate, found bool
+	for _, candidate := range rsqlOperators {
+		if strings.HasPrefix(p.input[p.position:], candidate.token) {
+			operator, negate, found = candidate.operator, candidate.negate, true
+			p.position += len(candidate.token)
+			break
+		}
+	}
+
+	if !found {
+		return Filter{}, p.errorf("expected comparison operator")
+	}
+
+	if operator == In {
+		values, err := p.parseValueList(field)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		filter := NewQueryFilter(field, In, values)
+		if negate {
+			return Negate(filter), nil
+		}
+		return filter, nil
+	}
+
+	valuePosition := p.skipSpaces()
+	text, quoted, err := p.parseArgument()
+	if err != nil {
+		return Filter{}, err
+	}
+
+	if operator == Equals && !quoted && IsTextField(field) && strings.Contains(text, "*") && len(strings.Trim(text, "*")) > 0 {
+		trimmed := strings.Trim(text, "*")
+		if strings.HasSuffix(text, "*") && !strings.HasPrefix(text, "*") {
+			return NewQueryFilter(field, StartsWith, trimmed), nil
+		}
+		return NewQueryFilter(field, Contains, trimmed), nil
+	}
+
+	value, valueErr := ParseFieldValue(field, text)
+	if valueErr != nil {
+		p.position = valuePosition
+		return Filter{}, p.errorf("%s", valueErr.Error())
+	}
+
+	return NewQueryFilter(field, operator, value), nil
+}
+
+// parseValueList parses the (a,b,c) arguments of =in= and =out=
+func (p *rsqlParser) parseValueList(field string) ([]interface{}, *BookAPIError) {
+	if !p.consume("(") {
+		return nil, p.errorf("expected (")
+	}
+
+	values := []interface{}{}
+	for {
+		valuePosition := p.skipSpaces()
+		text, _, err := p.parseArgument()
+		if err != nil {
+			return nil, err
+		}
+
+		value, valueErr := ParseFieldValue(field, text)
+		if valueErr != nil {
+			p.position = valuePosition
+			return nil, p.errorf("%s", valueErr.Error())
+		}
+		values = append(values, value)
+
+		if p.consume(")") {
+			return values, nil
+		}
+
+		if !p.consume(",") {
+			return nil, p.errorf("expected , or )")
+		}
+	}
+}
+
+// parseArgument parses a quoted or unquoted value, reporting whether it was quoted
+func (p *rsqlParser) parseArgument() (string, bool, *BookAPIError) {
+	if p.done() {
+		return "", false, p.errorf("expected value")
+	}
+
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		start := p.position
+		for !p.done() && !strings.ContainsRune(rsqlReserved, rune(p.peek())) {
+			p.position++
+		}
+
+		if start == p.position {
+			return "", false, p.errorf("expected value")
+		}
+
+		return p.input[start:p.position], false, nil
+	}
+
+	start := p.position
+	p.position++
+	var value strings.Builder
+	for !p.done() {
+		c := p.peek()
+		p.position++
+
+		switch {
+		case c == '\\' && !p.done():
+			value.WriteByte(p.peek())
+			p.position++
+		case c == quote:
+			return value.String(), true, nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	p.position = start
+	return "", false, p.errorf("unterminated string")
+}
+
+// consume skips spaces and the token when it is next
+func (p *rsqlParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.position:], token) {
+		p.position += len(token)
+		return true
+	}
+
+	return false
+}
+
+// skipSpaces advances past spaces and returns the new position
+func (p *rsqlParser) skipSpaces() int {
+	for !p.done() && p.peek() == ' ' {
+		p.position++
+	}
+
+	return p.position
+}
+
+func (p *rsqlParser) done() bool {
+	return p.position >= len(p.input)
+}
+
+func (p *rsqlParser) peek() byte {
+	return p.input[p.position]
+}
+
+// errorf reports a syntax error at the current, 1-based, position
+func (p *rsqlParser) errorf(format string, args ...interface{}) *BookAPIError {
+	return NewFilterSyntaxError(p.position+1, fmt.Sprintf(format, args...))
+}
+
+func isSelectorChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestParseRSQL(t *testing.T) {
+	filter, err := ParseRSQL(`author=="Robert Martin";rating=ge=2,status==1`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, AnyOf(
+		AllOf(NewQueryFilter("author", Equals, "Robert Martin"), NewQueryFilter("rating", GreaterThanOrEqual, float64(2))),
+		NewQueryFilter("status", Equals, CheckedIn),
+	), filter)
+}
+
+func TestParseRSQL_WithGroupsListsAndWildcards(t *testing.T) {
+	filter, err := ParseRSQL(`(title==Clean*, publisher==*Collins*) ; status=out=(CheckedOut) ; rating<3 ; author!='O\'Reilly'`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, AllOf(
+		AnyOf(NewQueryFilter("title", StartsWith, "Clean"), NewQueryFilter("publisher", Contains, "Collins")),
+		Negate(NewQueryFilter("status", In, []interface{}{CheckedOut})),
+		NewQueryFilter("rating", LessThan, float64(3)),
+		NewQueryFilter("author", DoesNotEqual, "O'Reilly"),
+	), filter)
+}
+
+func TestParseRSQL_WithSyntaxErrors(t *testing.T) {
+	cases := map[string]string{
+		`author=="Robert`:          "position 9",
+		`rating=ge=abc`:            "position 11",
+		`name==x`:                  "position 1",
+		`rating=ge=2;`:             "position 13",
+		`(rating==1`:               "position 11",
+		`rating~1`:                 "position 7",
+		`status=in=(1,2`:           "position 15",
+		`author=="Robert Martin")`: "position 24",
+	}
+
+	for expression, position := range cases {
+		_, err := ParseRSQL(expression)
+		assert.NotNil(t, err, expression)
+		assert.Equal(t, ValidationError, err.errorType, expression)
+		assert.True(t, strings.Contains(err.Error(), position), "%s: %s", expression, err.Error())
+	}
+}
+
+func TestQueryBuilder_GetQueryParams_WithFilter(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetQueryParams(newQueryRequest(`filter=rating%3Dge%3D2%3Bauthor%3D%3D%22Robert%20Martin%22&status=1`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, AllOf(
+		NewQueryFilter("rating", GreaterThanOrEqual, float64(2)),
+		NewQueryFilter("author", Equals, "Robert Martin"),
+		NewQueryFilter("status", Equals, CheckedIn),
+	), request.Filter)
+}
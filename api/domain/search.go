@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// searchFieldWeights relevance of a term found in each searchable Book field, by bson name
+var searchFieldWeights = map[string]float64{
+	"title":     3,
+	"author":    2,
+	"publisher": 1,
+}
+
+// Tokenize splits the text into lower case terms without diacritics, i.e. "Gabriel García Márquez" into gabriel, garcia, marquez
+func Tokenize(text string) []string {
+	var folded strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		folded.WriteRune(unicode.ToLower(r))
+	}
+
+	return strings.FieldsFunc(folded.String(), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchFields returns the searchable fields of the Book keyed by their bson name
+func searchFields(book Book) map[string]string {
+	return map[string]string{
+		"title":     book.Title,
+		"author":    book.Author,
+		"publisher": book.Publisher,
+	}
+}
+
+// searchIndex in-process inverted index of the searchable Book fields,
+// for the storage engines without full-text search
+type searchIndex struct {
+	mu sync.RWMutex
+	// postings weighted term frequency of a term in each Book, keyed by term then Book ID
+	postings map[string]map[string]float64
+	// terms indexed terms of each Book, keyed by Book ID
+	terms map[string][]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{postings: map[string]map[string]float64{}, terms: map[string][]string{}}
+}
+
+// add indexes the Book, replacing its previous entry
+func (idx *searchIndex) add(book Book) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	id := book.ID.Hex()
+	idx.removeLocked(id)
+
+	for field, text := range searchFields(book) {
+		for _, term := range Tokenize(text) {
+			postings, ok := idx.postings[term]
+			if !ok {
+				postings = map[string]float64{}
+				idx.postings[term] = postings
+			}
+
+			if _, seen := postings[id]; !seen {
+				idx.terms[id] = append(idx.terms[id], term)
+			}
+			postings[id] += searchFieldWeights[field]
+		}
+	}
+}
+
+// remove drops the Book with the specified ID from the index
+func (idx *searchIndex) remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(id)
+}
+
+// reset replaces the indexed Books
+func (idx *searchIndex) reset(books Books) {
+	idx.mu.Lock()
+	idx.postings = map[string]map[string]float64{}
+	idx.terms = map[string][]string{}
+	idx.mu.Unlock()
+
+	for _, book := range books {
+		idx.add(book)
+	}
+}
+
+func (idx *searchIndex) removeLocked(id string) {
+	for _, term := range idx.terms[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	delete(idx.terms, id)
+}
+
+// searchResult ID of a Book matching a search with its relevance
+type searchResult struct {
+	id    string
+	score float64
+}
+
+// search returns the Books matching any term of the text, most relevant first.
+// A Book scores the weighted frequency of each term times its inverse document frequency
+func (idx *searchIndex) search(text string) []searchResult {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.terms))
+	scores := map[string]float64{}
+	searched := map[string]bool{}
+	for _, term := range Tokenize(text) {
+		if searched[term] {
+			continue
+		}
+		searched[term] = true
+
+		postings := idx.postings[term]
+		idf := math.Log(1 + total/float64(len(postings)+1))
+		for id, frequency := range postings {
+			scores[id] += frequency * idf
+		}
+	}
+
+	results := make([]searchResult, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, searchResult{id: id, score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].id < results[j].id
+	})
+
+	return results
+}
+
+// searchPage returns the results of the page selected by the find options
+func searchPage(results []searchResult, findOptions FindOptions) []searchResult {
+	start := findOptions.Skip
+	if start > int64(len(results)) {
+		start = int64(len(results))
+	}
+
+	end := int64(len(results))
+	if findOptions.Limit > 0 && start+findOptions.Limit < end {
+		end = start + findOptions.Limit
+	}
+
+	return results[start:end]
+}
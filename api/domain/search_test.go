@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"gabriel", "garcia", "marquez"}, Tokenize("Gabriel García Márquez"))
+	assert.Equal(t, []string{"c", "programming", "2nd", "ed"}, Tokenize("  C++ Programming, 2nd ed. "))
+	assert.Empty(t, Tokenize(" -- "))
+}
+
+func TestMemoryRepo_Search(t *testing.T) {
+	repo := seedMemoryRepository(t)
+
+	hits, total, err := repo.Search("CLEAN architecture", FindOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "Clean Architecture", hits[0].Title)
+	assert.Equal(t, "Clean Code", hits[1].Title)
+	assert.True(t, hits[0].Score > hits[1].Score)
+
+	hits, total, err = repo.Search("martin alchemist", FindOptions{Limit: 1, Skip: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, hits, 1)
+
+	hits, total, _ = repo.Search("unknown", FindOptions{})
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, hits)
+}
+
+func TestMemoryRepo_Search_FollowsWrites(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Gabriel García Márquez", Title: "Cien años de soledad", Publisher: "Sudamericana", PublishDate: "1967"})
+
+	hits, _, _ := repo.Search("garcia anos", FindOptions{})
+	assert.Len(t, hits, 1)
+
+	assert.Nil(t, repo.Update(id, Filter{}, Fields{"title": "One Hundred Years of Solitude"}))
+	hits, _, _ = repo.Search("solitude", FindOptions{})
+	assert.Len(t, hits, 1)
+	hits, _, _ = repo.Search("soledad", FindOptions{})
+	assert.Empty(t, hits)
+
+	assert.Nil(t, repo.Delete(id, Filter{}))
+	hits, _, _ = repo.Search("garcia", FindOptions{})
+	assert.Empty(t, hits)
+}
+
+func TestSQLRepo_Search(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+	seedRepository(t, repo)
+
+	hits, total, err := repo.Search("clean prentice", FindOptions{Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "Robert Martin", hits[0].Author)
+}
+
+func TestQueryBuilder_GetSearchParams(t *testing.T) {
+	builder := QueryBuilder{}
+	request, err := builder.GetSearchParams(newQueryRequest("q=clean+c%C3%B3de&page=2&size=5"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "clean códe", request.Text)
+	assert.Equal(t, FindOptions{Limit: 5, Skip: 5}, request.FindOptions)
+
+	_, err = builder.GetSearchParams(newQueryRequest("q=clean&author=Robert"))
+	assert.NotNil(t, err)
+	assert.Equal(t, ValidationError, err.errorType)
+}
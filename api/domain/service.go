@@ -1,18 +1,29 @@
 package domain
 
 import (
+	"fmt"
 	"github.com/fatih/structs"
 	"github.com/temesxgn/redeam/api/utils"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"reflect"
+	"time"
 )
 
 type service struct {
 	repository Repository
+	patrons    PatronRepository
+	loans      LoanRepository
+	holds      HoldRepository
+	copies     CopyRepository
+	ratings    RatingRepository
+	policy     LoanPolicy
+	// now current time of the loans, in UTC to the millisecond the stores keep
+	now func() time.Time
 }
 
-func (s *service) FindAll(filters bson.M, options *options.FindOptions) (Books, *BookAPIError) {
-	blogs, err := s.repository.FindAll(filters, options)
+func (s *service) FindAll(filter Filter, options FindOptions) (Books, *BookAPIError) {
+	blogs, err := s.repository.FindAll(filter, options)
 	if err != nil {
 		return nil, err
 	}
@@ -20,6 +31,39 @@ func (s *service) FindAll(filters bson.M, options *options.FindOptions) (Books,
 	return blogs, nil
 }
 
+func (s *service) Count(filter Filter) (int64, *BookAPIError) {
+	return s.repository.Count(filter)
+}
+
+func (s *service) Search(text string, options FindOptions) (SearchHits, int64, *BookAPIError) {
+	if len(Tokenize(text)) == 0 {
+		return nil, 0, NewValidationError("search text must contain at least one word")
+	}
+
+	return s.repository.Search(text, options)
+}
+
+func (s *service) FindSimilar(filter Filter, conditions []Query, options FindOptions) (FuzzyResult, *BookAPIError) {
+	candidates, err := s.repository.FindAll(filter, FindOptions{Sort: options.Sort})
+	if err != nil {
+		return FuzzyResult{}, err
+	}
+
+	hits, suggestions := fuzzyMatch(candidates, conditions)
+	result := FuzzyResult{Total: int64(len(hits)), DidYouMean: suggestions}
+
+	start, end := options.Skip, int64(len(hits))
+	if start > end {
+		start = end
+	}
+	if options.Limit > 0 && start+options.Limit < end {
+		end = start + options.Limit
+	}
+	result.Hits = hits[start:end]
+
+	return result, nil
+}
+
 func (s *service) FindOne(id string) (Book, *BookAPIError) {
 	return s.repository.FindOne(id)
 }
@@ -32,9 +76,11 @@ func (s *service) Create(book Book) (string, *BookAPIError) {
 	}
 
 	if validationError := book.Validate(); validationError != nil {
-		return "", NewValidationError(validationError.Error())
+		return "", validationError
 	}
 
+	// a new Book is rated by the Patrons only
+	book.RatingAverage, book.RatingCount, book.RatingDistribution = 0, 0, nil
 	id, persistError := s.repository.Save(book)
 	if persistError != nil {
 		return "", NewPersistError(persistError.Error())
@@ -43,93 +89,978 @@ func (s *service) Create(book Book) (string, *BookAPIError) {
 	return id, nil
 }
 
-func (s *service) Update(id string, book Book) *BookAPIError {
+func (s *service) Update(id string, versions Versions, book Book) *BookAPIError {
 
 	if err := book.Validate(); err != nil {
-		return NewValidationError(err.Error())
+		return err
 	}
 
-	if _, findError := s.repository.FindOne(id); findError != nil {
+	current, findError := s.repository.FindOne(id)
+	if findError != nil {
 		return NewNotFoundError(id)
 	}
 
+	if versionError := checkVersion(current, versions); versionError != nil {
+		return versionError
+	}
+
+	if book.Status != current.Status {
+		return newStatusChangeError()
+	}
+
 	mapper := utils.ModelMapper{}
-	fields := mapper.ToMongoDocument(structs.Fields(book))
-	return s.repository.Update(id, fields)
+	fields := mapper.ToFields(structs.Fields(book))
+	for _, field := range ratingAggregates {
+		delete(fields, field)
+	}
+	// the status is left as it is now, a checkout since the Book was read included, and the scale of the rating is
+	// only recorded by rescaling the ratings
+	delete(fields, "status")
+	delete(fields, "rating_scale")
+
+	return s.repository.Update(id, versionCondition(versions), fields)
 }
 
-func (s *service) Delete(id string) *BookAPIError {
+func (s *service) Patch(id string, versions Versions, patch BookPatch) (Book, *BookAPIError) {
+	book, err := s.repository.FindOne(id)
+	if err != nil {
+		return Book{}, NewNotFoundError(id)
+	}
+
+	if versionError := checkVersion(book, versions); versionError != nil {
+		return Book{}, versionError
+	}
 
-	if _, err := s.repository.FindOne(id); err != nil {
-		return NewNotFoundError(id)
+	patched, patchError := patch.Apply(book)
+	if patchError != nil {
+		return Book{}, patchError
+	}
+
+	if patched.ID != book.ID {
+		return Book{}, NewInvalidFieldError("_id", "immutable", "_id cannot be changed")
+	}
+
+	if patched.Version != book.Version {
+		return Book{}, NewInvalidFieldError("version", "immutable", "version cannot be changed")
 	}
 
-	return s.repository.Delete(id)
+	if validationError := patched.Validate(); validationError != nil {
+		return Book{}, validationError
+	}
+
+	if patched.Status != book.Status {
+		return Book{}, newStatusChangeError()
+	}
+
+	mapper := utils.ModelMapper{}
+	original := mapper.ToFields(structs.Fields(book))
+	fields := Fields{}
+	for field, value := range mapper.ToFields(structs.Fields(patched)) {
+		if !reflect.DeepEqual(original[field], value) {
+			fields[field] = value
+		}
+	}
+	// the scale of the rating is not part of the patched document, only rescaling the ratings records it
+	delete(fields, "rating_scale")
+
+	for _, field := range ratingAggregates {
+		if _, changed := fields[field]; changed {
+			return Book{}, NewInvalidFieldError(field, "immutable", field+" cannot be changed")
+		}
+	}
+
+	if len(fields) == 0 {
+		return patched, nil
+	}
+
+	// the Book read is the one patched, it must not have changed since
+	if updateError := s.repository.Update(id, versionCondition(Versions{book.Version}), fields); updateError != nil {
+		return Book{}, toUpdateError(updateError)
+	}
+
+	patched.Version++
+	return patched, nil
 }
 
-func (s *service) CheckOut(id string) *BookAPIError {
+func (s *service) Delete(id string, versions Versions) *BookAPIError {
+
 	book, err := s.repository.FindOne(id)
 	if err != nil {
 		return NewNotFoundError(id)
 	}
 
-	if book.Status == CheckedOut {
-		return NewAlreadyCheckedOutError(id)
+	if versionError := checkVersion(book, versions); versionError != nil {
+		return versionError
 	}
 
-	fields := bson.D{
-		{"$set", bson.D{{"status", CheckedOut}}},
+	return s.repository.Delete(id, versionCondition(versions))
+}
+
+func (s *service) CheckOut(id string, versions Versions, request LoanRequest) (Loan, *BookAPIError) {
+	if err := s.checkWithoutCopies(id); err != nil {
+		return Loan{}, err
+	}
+
+	return s.checkOut(shelfItem{bookID: id}, versions, request)
+}
+
+func (s *service) CheckOutCopy(id string, copyID string, versions Versions, request LoanRequest) (Loan, *BookAPIError) {
+	return s.checkOut(shelfItem{bookID: id, copyID: copyID}, versions, request)
+}
+
+func (s *service) checkOut(item shelfItem, versions Versions, request LoanRequest) (Loan, *BookAPIError) {
+	patronID := request.PatronID
+	if len(patronID) == 0 {
+		return Loan{}, NewInvalidFieldError("patron_id", "required", "patron_id cannot be blank")
+	}
+
+	checkedOutAt := s.now()
+	dueAt, dueError := s.policy.dueAt(checkedOutAt, request.Days)
+	if dueError != nil {
+		return Loan{}, dueError
+	}
+
+	if err := s.checkPatron(patronID); err != nil {
+		return Loan{}, err
+	}
+
+	// an item on the hold shelf is kept for the Patron it is reserved for
+	ready, holdError := s.readyHold(item)
+	if holdError != nil {
+		return Loan{}, holdError
+	}
+
+	from := CheckedIn
+	if ready != nil {
+		if ready.PatronID != patronID {
+			return Loan{}, NewOnHoldError(item.id())
+		}
+		from = OnHoldShelf
+	}
+
+	if err := s.changeStatus(item, versions, from, CheckedOut); err != nil {
+		return Loan{}, err
+	}
+
+	loan := Loan{BookID: item.bookID, CopyID: item.copyID, PatronID: patronID, CheckedOutAt: checkedOutAt, DueAt: dueAt}
+	loanID, saveError := s.loans.Save(loan)
+	if saveError != nil {
+		// an item is never left checked out without a loan
+		_ = s.setStatus(item, Filter{}, CheckedOut, from)
+		return Loan{}, saveError
+	}
+
+	if ready != nil {
+		// the Patron picked the item up, leaving the line
+		if err := s.holds.Delete(ready.ID.Hex(), Filter{}); err != nil && err.errorType != NotFoundError {
+			return Loan{}, err
+		}
+	}
+
+	loan.ID, _ = primitive.ObjectIDFromHex(loanID)
+	return loan, nil
+}
+
+func (s *service) CheckIn(id string, versions Versions) *BookAPIError {
+	if err := s.checkWithoutCopies(id); err != nil {
+		return err
+	}
+
+	return s.checkIn(shelfItem{bookID: id}, versions)
+}
+
+func (s *service) CheckInCopy(id string, copyID string, versions Versions) *BookAPIError {
+	return s.checkIn(shelfItem{bookID: id, copyID: copyID}, versions)
+}
+
+func (s *service) checkIn(item shelfItem, versions Versions) *BookAPIError {
+	// the item goes to the hold shelf when a Patron waits for the Book
+	next, holdError := s.nextHold(item.bookID)
+	if holdError != nil {
+		return holdError
 	}
 
-	if updateError := s.repository.Update(id, fields); updateError != nil {
-		return NewUpdateError(updateError.Error())
+	status := CheckedIn
+	if next != nil {
+		status = OnHoldShelf
+	}
+
+	if err := s.changeStatus(item, versions, CheckedOut, status); err != nil {
+		return err
+	}
+
+	if err := s.closeLoans(item); err != nil {
+		return err
+	}
+
+	if next != nil {
+		return s.passOn(item)
 	}
 
 	return nil
 }
 
-func (s *service) CheckIn(id string) *BookAPIError {
-	book, err := s.repository.FindOne(id)
+// newStatusChangeError returns the error of a PUT or PATCH changing the status of a Book. Only checkouts, checkins and
+// transitions change it, so that the Loans and Holds of the Book follow
+func newStatusChangeError() *BookAPIError {
+	return NewInvalidFieldError("status", "transition", "status is changed by checking out, checking in or PUT /books/{id}/status/{status}")
+}
+
+func (s *service) Transition(id string, versions Versions, status Status) *BookAPIError {
+	if err := s.checkWithoutCopies(id); err != nil {
+		return err
+	}
+
+	return s.transition(shelfItem{bookID: id}, versions, status)
+}
+
+func (s *service) TransitionCopy(id string, copyID string, versions Versions, status Status) *BookAPIError {
+	return s.transition(shelfItem{bookID: id, copyID: copyID}, versions, status)
+}
+
+// transition moves the item to a status the branches set. An item back on the shelf goes to the hold shelf when a
+// Patron waits for the Book, an item that was checked out has its loan closed and one that was on the hold shelf
+// puts its Patron back at the head of the line
+func (s *service) transition(item shelfItem, versions Versions, to Status) *BookAPIError {
+	if to == CheckedOut || to == OnHoldShelf {
+		return NewInvalidFieldError("status", "transition", fmt.Sprintf("%s is set by checking out and holds", to))
+	}
+
+	from, err := s.itemStatus(item, versions)
 	if err != nil {
-		return NewNotFoundError(id)
+		return err
+	}
+
+	if to == CheckedIn && (from == CheckedOut || from == OnHoldShelf) {
+		return NewInvalidFieldError("status", "transition", fmt.Sprintf("%s goes back on the shelf by checking it in", item.id()))
 	}
 
-	if book.Status == CheckedIn {
-		return NewAlreadyCheckedInError(id)
+	if !from.CanBecome(to) {
+		return NewIllegalTransitionError(item.kind(), item.id(), from, to)
 	}
 
-	fields := bson.D{
-		{"$set", bson.D{{"status", CheckedIn}}},
+	var next *Hold
+	if to == CheckedIn {
+		if next, err = s.nextHold(item.bookID); err != nil {
+			return err
+		}
+		if next != nil {
+			to = OnHoldShelf
+		}
 	}
 
-	if updateError := s.repository.Update(id, fields); updateError != nil {
-		return NewUpdateError(updateError.Error())
+	if err := s.changeStatus(item, versions, from, to); err != nil {
+		return err
+	}
+
+	switch from {
+	case CheckedOut:
+		if err := s.closeLoans(item); err != nil {
+			return err
+		}
+	case OnHoldShelf:
+		if err := s.unreserve(item); err != nil {
+			return err
+		}
+	}
+
+	if next != nil {
+		return s.passOn(item)
 	}
 
 	return nil
 }
 
-func (s *service) Rate(id string, rate int) *BookAPIError {
+// closeLoans sets the returned_at of the open loans of the item
+func (s *service) closeLoans(item shelfItem) *BookAPIError {
+	// Books checked out before loans were recorded have none to close
+	open := AllOf(NewQueryFilter("book_id", Equals, item.bookID), NewQueryFilter("returned_at", Equals, nil))
+	if item.copyID != "" {
+		open = AllOf(open, NewQueryFilter("copy_id", Equals, item.copyID))
+	}
+
+	loans, err := s.loans.FindAll(open, FindOptions{})
+	if err != nil {
+		return NewUpdateError(err.Error())
+	}
+
+	returnedAt := s.now()
+	for _, loan := range loans {
+		closeError := s.loans.Update(loan.ID.Hex(), NewQueryFilter("returned_at", Equals, nil), Fields{"returned_at": returnedAt})
+		if closeError != nil && closeError.errorType != NotFoundError {
+			return NewUpdateError(closeError.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Loans(id string) (Loans, *BookAPIError) {
+	if _, err := s.repository.FindOne(id); err != nil {
+		return nil, err
+	}
+
+	return s.loans.FindAll(NewQueryFilter("book_id", Equals, id), newestLoansFirst)
+}
+
+// shelfItem physical item of a Book: one of its copies, or the Book itself when it is catalogued without copies
+type shelfItem struct {
+	bookID string
+	copyID string
+	status Status
+}
+
+// kind returns the kind of the item, Copy or Book
+func (i shelfItem) kind() string {
+	if i.copyID != "" {
+		return copyKind
+	}
+
+	return bookKind
+}
+
+// id returns the ID of the item, the one of the Copy or of the Book
+func (i shelfItem) id() string {
+	if i.copyID != "" {
+		return i.copyID
+	}
+
+	return i.bookID
+}
+
+// shelfItems returns the items of the Book with their status, the Book itself when it has no copies
+func shelfItems(book Book, copies Copies) []shelfItem {
+	if len(copies) == 0 {
+		return []shelfItem{{bookID: book.ID.Hex(), status: book.Status}}
+	}
+
+	items := make([]shelfItem, 0, len(copies))
+	for _, bookCopy := range copies {
+		items = append(items, shelfItem{bookID: bookCopy.BookID, copyID: bookCopy.ID.Hex(), status: bookCopy.Status})
+	}
+
+	return items
+}
+
+// checkWithoutCopies returns a Validation Error if the Book has copies, which are checked out and in one by one
+func (s *service) checkWithoutCopies(id string) *BookAPIError {
+	copies, err := s.copies.FindAll(NewQueryFilter("book_id", Equals, id), FindOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+
+	if len(copies) > 0 {
+		return NewValidationError(fmt.Sprintf("Book %s has copies, check out and in one of them", id))
+	}
+
+	return nil
+}
+
+// changeStatus moves the item from a status to another with a single conditional update, so that of concurrent
+// requests changing the status of an item only one succeeds. It returns the error telling why the item is not in
+// the status it was expected in
+func (s *service) changeStatus(item shelfItem, versions Versions, from Status, to Status) *BookAPIError {
+	updateError := s.setStatus(item, versionCondition(versions), from, to)
+	if updateError == nil || updateError.errorType != PreconditionFailed {
+		return toUpdateError(updateError)
+	}
+
+	// the update changed nothing, the item now tells whether its version or its status did not match
+	status, err := s.itemStatus(item, versions)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status == from:
+		// changed and changed back since, the request may be retried
+		return updateError
+	case status == CheckedOut:
+		return NewAlreadyCheckedOutError(item.id())
+	case from == CheckedOut && (status == CheckedIn || status == OnHoldShelf):
+		return NewAlreadyCheckedInError(item.id())
+	case status == OnHoldShelf:
+		return NewOnHoldError(item.id())
+	}
+
+	return NewIllegalTransitionError(item.kind(), item.id(), status, to)
+}
+
+// setStatus sets the status of the item where it matches the condition and is in the from status.
+// It returns a Precondition Failed error if it does not
+func (s *service) setStatus(item shelfItem, condition Filter, from Status, to Status) *BookAPIError {
+	condition = AllOf(condition, NewQueryFilter("status", Equals, from))
+	if item.copyID == "" {
+		return s.repository.Update(item.bookID, condition, Fields{"status": to})
+	}
+
+	updateError := s.copies.Update(item.copyID, AllOf(NewQueryFilter("book_id", Equals, item.bookID), condition), Fields{"status": to})
+	if updateError != nil && updateError.errorType == NotFoundError {
+		// the copies do not tell a missing Copy from one in another status
+		return NewPreconditionFailedError(copyKind, item.copyID)
+	}
+
+	return updateError
+}
+
+// itemStatus returns the status of the item. It returns a Precondition Failed error if the item is at none of the versions
+func (s *service) itemStatus(item shelfItem, versions Versions) (Status, *BookAPIError) {
+	if item.copyID == "" {
+		book, err := s.repository.FindOne(item.bookID)
+		if err != nil {
+			return Unknown, err
+		}
+
+		return book.Status, checkVersion(book, versions)
+	}
+
+	bookCopy, err := s.copies.FindOne(item.copyID)
+	if err != nil {
+		return Unknown, err
+	}
+
+	if bookCopy.BookID != item.bookID {
+		return Unknown, NewCopyNotFoundError(item.copyID)
+	}
+
+	if !versions.Match(bookCopy.Version) {
+		return Unknown, NewPreconditionFailedError(copyKind, item.copyID)
+	}
+
+	return bookCopy.Status, nil
+}
+
+// checkPatron returns a Validation Error if the Patron does not exist
+func (s *service) checkPatron(patronID string) *BookAPIError {
+	if _, err := s.patrons.FindOne(patronID); err != nil {
+		if err.errorType == NotFoundError {
+			return NewInvalidFieldError("patron_id", "exists", fmt.Sprintf("Patron %s does not exist", patronID))
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) PlaceHold(id string, request HoldRequest) (Hold, *BookAPIError) {
+	patronID := request.PatronID
+	if len(patronID) == 0 {
+		return Hold{}, NewInvalidFieldError("patron_id", "required", "patron_id cannot be blank")
+	}
+
+	if err := s.checkPatron(patronID); err != nil {
+		return Hold{}, err
+	}
+
+	items, err := s.itemsOf(id)
+	if err != nil {
+		return Hold{}, err
+	}
+
+	withdrawn := 0
+	for _, item := range items {
+		switch item.status {
+		case CheckedIn:
+			return Hold{}, NewValidationError(fmt.Sprintf("Book %s is available, check it out instead", id))
+		case Withdrawn:
+			withdrawn++
+		}
+	}
+
+	if withdrawn == len(items) {
+		return Hold{}, NewValidationError(fmt.Sprintf("Book %s is withdrawn", id))
+	}
+
+	patronFilter := AllOf(NewQueryFilter("book_id", Equals, id), NewQueryFilter("patron_id", Equals, patronID))
+	holds, err := s.holds.FindAll(patronFilter, FindOptions{Limit: 1})
+	if err != nil {
+		return Hold{}, err
+	}
+
+	if len(holds) > 0 {
+		return Hold{}, NewHoldExistsError(patronID, id)
+	}
+
+	loans, err := s.loans.FindAll(AllOf(patronFilter, NewQueryFilter("returned_at", Equals, nil)), FindOptions{Limit: 1})
+	if err != nil {
+		return Hold{}, err
+	}
+
+	if len(loans) > 0 {
+		return Hold{}, NewValidationError(fmt.Sprintf("Patron %s has Book %s checked out", patronID, id))
+	}
+
+	holdID, err := s.holds.Save(Hold{BookID: id, PatronID: patronID, PlacedAt: s.now()})
+	if err != nil {
+		return Hold{}, err
+	}
+
+	// an item may have been checked in while the Patron got in line, it then goes to the hold shelf
+	if err := s.reserveAvailable(id); err != nil {
+		return Hold{}, err
+	}
+
+	return s.Hold(id, holdID)
+}
+
+func (s *service) CancelHold(id string, holdID string) *BookAPIError {
+	hold, err := s.Hold(id, holdID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.holds.Delete(holdID, Filter{}); err != nil {
+		return err
+	}
+
+	// the item reserved for the Patron goes to the next one in line
+	return s.release(heldItem(hold))
+}
+
+func (s *service) Hold(id string, holdID string) (Hold, *BookAPIError) {
+	line, err := s.holds.FindAll(NewQueryFilter("book_id", Equals, id), holdLine)
+	if err != nil {
+		return Hold{}, err
+	}
+
+	for i, hold := range line {
+		if hold.ID.Hex() == holdID {
+			hold.Position = i + 1
+			return hold, nil
+		}
+	}
+
+	return Hold{}, NewHoldNotFoundError(holdID)
+}
+
+func (s *service) Holds(id string) (Holds, *BookAPIError) {
+	if _, err := s.repository.FindOne(id); err != nil {
+		return nil, err
+	}
+
+	line, err := s.holds.FindAll(NewQueryFilter("book_id", Equals, id), holdLine)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range line {
+		line[i].Position = i + 1
+	}
+
+	return line, nil
+}
+
+func (s *service) ExpireHolds() (int, *BookAPIError) {
+	expired := NewQueryFilter("expires_at", LessThan, s.now())
+	holds, err := s.holds.FindAll(expired, FindOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, hold := range holds {
+		if deleteError := s.holds.Delete(hold.ID.Hex(), expired); deleteError != nil {
+			// picked up or cancelled since it was found
+			if deleteError.errorType == NotFoundError {
+				continue
+			}
+			return count, deleteError
+		}
+
+		count++
+		if err := s.release(heldItem(hold)); err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// itemsOf returns the items of the Book with their status
+func (s *service) itemsOf(id string) ([]shelfItem, *BookAPIError) {
 	book, err := s.repository.FindOne(id)
 	if err != nil {
-		return NewNotFoundError(id)
+		return nil, err
 	}
 
-	book.Rating = rate
-	if validationError := book.Validate(); validationError != nil {
-		return NewValidationError(validationError.Error())
+	copies, err := s.copies.FindAll(NewQueryFilter("book_id", Equals, id), FindOptions{})
+	if err != nil {
+		return nil, err
 	}
 
-	mapper := utils.ModelMapper{}
-	fields := mapper.ToMongoDocument(structs.Fields(book))
-	if updateError := s.repository.Update(id, fields); updateError != nil {
-		return NewUpdateError(updateError.Error())
+	return shelfItems(book, copies), nil
+}
+
+// heldItem returns the item reserved for the Hold, the Book itself when the Hold names no Copy
+func heldItem(hold Hold) shelfItem {
+	return shelfItem{bookID: hold.BookID, copyID: hold.CopyID}
+}
+
+// readyHold returns the Hold the item on the hold shelf is reserved for, nil when there is none
+func (s *service) readyHold(item shelfItem) (*Hold, *BookAPIError) {
+	ready := AllOf(NewQueryFilter("book_id", Equals, item.bookID), NewQueryFilter("ready_at", DoesNotEqual, nil))
+	if item.copyID != "" {
+		ready = AllOf(ready, NewQueryFilter("copy_id", Equals, item.copyID))
+	}
+
+	return s.firstHold(ready)
+}
+
+// nextHold returns the Hold of the next Patron in line for the Book, nil when nobody waits
+func (s *service) nextHold(id string) (*Hold, *BookAPIError) {
+	waiting := AllOf(NewQueryFilter("book_id", Equals, id), NewQueryFilter("ready_at", Equals, nil))
+	return s.firstHold(waiting)
+}
+
+func (s *service) firstHold(filter Filter) (*Hold, *BookAPIError) {
+	holds, err := s.holds.FindAll(filter, FindOptions{Limit: 1, Sort: holdLine.Sort})
+	if err != nil || len(holds) == 0 {
+		return nil, err
+	}
+
+	return &holds[0], nil
+}
+
+// reserveAvailable puts the first item of the Book still on the shelf on the hold shelf for the next Patron in line
+func (s *service) reserveAvailable(id string) *BookAPIError {
+	items, err := s.itemsOf(id)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.status != CheckedIn {
+			continue
+		}
+
+		reserveError := s.setStatus(item, Filter{}, CheckedIn, OnHoldShelf)
+		if reserveError == nil {
+			return s.passOn(item)
+		}
+
+		if reserveError.errorType != PreconditionFailed {
+			return toUpdateError(reserveError)
+		}
+	}
+
+	return nil
+}
+
+// unreserve puts the Patron the item was reserved for back at the head of the line, to be served by another item
+func (s *service) unreserve(item shelfItem) *BookAPIError {
+	ready, err := s.readyHold(item)
+	if err != nil || ready == nil {
+		return err
+	}
+
+	fields := Fields{"ready_at": nil, "expires_at": nil, "copy_id": ""}
+	if err := s.holds.Update(ready.ID.Hex(), Filter{}, fields); err != nil && err.errorType != NotFoundError {
+		return err
+	}
+
+	return s.reserveAvailable(item.bookID)
+}
+
+// release passes the item on the hold shelf to the next Patron in line once nobody has it reserved anymore
+func (s *service) release(item shelfItem) *BookAPIError {
+	ready, err := s.readyHold(item)
+	if err != nil || ready != nil {
+		return err
+	}
+
+	status, err := s.itemStatus(item, AnyVersion)
+	if err != nil {
+		// holds outlive the Books and copies deleted
+		if err.errorType == NotFoundError {
+			return nil
+		}
+		return err
+	}
+
+	if status != OnHoldShelf {
+		return nil
+	}
+
+	return s.passOn(item)
+}
+
+// passOn reserves the item on the hold shelf for the next Patron in line for the pickup window, putting it back
+// on the shelf when nobody waits
+func (s *service) passOn(item shelfItem) *BookAPIError {
+	for {
+		next, err := s.nextHold(item.bookID)
+		if err != nil {
+			return err
+		}
+
+		if next == nil {
+			updateError := s.setStatus(item, Filter{}, OnHoldShelf, CheckedIn)
+			if updateError != nil && updateError.errorType != PreconditionFailed && updateError.errorType != NotFoundError {
+				return toUpdateError(updateError)
+			}
+			return nil
+		}
+
+		readyAt := s.now()
+		fields := Fields{"ready_at": readyAt, "expires_at": readyAt.Add(s.policy.PickupWindow)}
+		if item.copyID != "" {
+			fields["copy_id"] = item.copyID
+		}
+
+		reserveError := s.holds.Update(next.ID.Hex(), NewQueryFilter("ready_at", Equals, nil), fields)
+		if reserveError == nil || reserveError.errorType != NotFoundError {
+			return reserveError
+		}
+		// cancelled since it was found, the Patron after it gets the item
+	}
+}
+
+func (s *service) Rate(id string, rate float64, request RatingRequest) (Rating, *BookAPIError) {
+	patronID := request.PatronID
+	if len(patronID) == 0 {
+		return Rating{}, NewInvalidFieldError("patron_id", "required", "patron_id cannot be blank")
+	}
+
+	rating := Rating{BookID: id, PatronID: patronID, Value: rate, RatedAt: s.now()}
+	if validationError := rating.Validate(); validationError != nil {
+		return Rating{}, validationError
+	}
+
+	if _, err := s.repository.FindOne(id); err != nil {
+		return Rating{}, NewNotFoundError(id)
+	}
+
+	if err := s.checkPatron(patronID); err != nil {
+		return Rating{}, err
+	}
+
+	patronFilter := AllOf(NewQueryFilter("book_id", Equals, id), NewQueryFilter("patron_id", Equals, patronID))
+	given, err := s.ratings.FindAll(patronFilter, FindOptions{Limit: 1})
+	if err != nil {
+		return Rating{}, err
+	}
+
+	if len(given) == 0 {
+		ratingID, saveErr := s.ratings.Save(rating)
+		if saveErr == nil {
+			rating.ID, _ = primitive.ObjectIDFromHex(ratingID)
+			return rating, s.aggregateRatings(id)
+		}
+
+		if saveErr.errorType != ExistingRecord {
+			return Rating{}, saveErr
+		}
+
+		// the Patron rated the Book since it was checked, the unique index kept a single Rating, which is updated
+		if given, err = s.ratings.FindAll(patronFilter, FindOptions{Limit: 1}); err != nil {
+			return Rating{}, err
+		}
+
+		if len(given) == 0 {
+			return Rating{}, saveErr
+		}
+	}
+
+	rating.ID = given[0].ID
+	if err := s.ratings.Update(rating.ID.Hex(), Filter{}, Fields{"rating": rate, "rated_at": rating.RatedAt}); err != nil {
+		return Rating{}, err
+	}
+
+	return rating, s.aggregateRatings(id)
+}
+
+func (s *service) Ratings(id string) (RatingSummary, *BookAPIError) {
+	if _, err := s.repository.FindOne(id); err != nil {
+		return RatingSummary{}, NewNotFoundError(id)
+	}
+
+	ratings, err := s.ratings.FindAll(NewQueryFilter("book_id", Equals, id), newestRatingsFirst)
+	if err != nil {
+		return RatingSummary{}, err
+	}
+
+	summary := summarizeRatings(ratings)
+	summary.Ratings = ratings
+	return summary, nil
+}
+
+// RescaleRatings moves the stored Ratings, and the ratings of the Books nobody rated, from the scale they are stored on
+// to the rating scale and aggregates the Ratings of the Books again. The scale is recorded once they are all moved
+// Each one records the scale it is moved to with its value, so that a rescale interrupted and run again, or run by
+// several instances at once, moves it once
+// It returns the number of Ratings moved
+func (s *service) RescaleRatings() (int, *BookAPIError) {
+	stored, err := s.ratings.Scale()
+	if err != nil || stored == ratingScale {
+		return 0, err
+	}
+
+	target := ratingScale.key()
+	notRescaled := NewQueryFilter("rating_scale", DoesNotEqual, target)
+	ratings, err := s.ratings.FindAll(Filter{}, FindOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	rated, rescaled := map[string]bool{}, 0
+	for _, rating := range ratings {
+		rated[rating.BookID] = true
+		if rating.Scale == target {
+			continue
+		}
+
+		value := ratingScale.Rescale(rating.Value, scaleOf(rating.Scale, stored))
+		err := s.ratings.Update(rating.ID.Hex(), notRescaled, Fields{"rating": value, "rating_scale": target})
+		switch {
+		case err == nil:
+			rescaled++
+		case err.errorType != NotFoundError:
+			return rescaled, err
+		}
+	}
+
+	books, err := s.repository.FindAll(Filter{}, FindOptions{})
+	if err != nil {
+		return rescaled, err
+	}
+
+	for _, book := range books {
+		id := book.ID.Hex()
+		// a Book is rated when Patrons rated it or its count says so, 0 being a rating of some scales.
+		// Nobody rated the others, whose rating means nothing to rescale
+		switch {
+		case rated[id]:
+			err = s.aggregateRatings(id)
+		case book.RatingCount > 0 && book.RatingScale != target:
+			// a rating written with the Book rather than aggregated
+			value := ratingScale.Rescale(book.Rating, scaleOf(book.RatingScale, stored))
+			err = s.repository.Update(id, notRescaled, Fields{"rating": value, "rating_scale": target})
+			if err != nil && err.errorType == PreconditionFailed {
+				err = nil
+			}
+		}
+
+		if err != nil {
+			return rescaled, toUpdateError(err)
+		}
+	}
+
+	return rescaled, s.ratings.SaveScale(ratingScale)
+}
+
+// scaleOf returns the scale named by the key a Rating or a Book recorded, the stored scale if it recorded none
+func scaleOf(key string, stored RatingScale) RatingScale {
+	if scale, ok := parseRatingScale(key); ok {
+		return scale
+	}
+
+	return stored
+}
+
+// aggregateRatings stores the average, count and distribution of the Ratings of the Book in it. They are computed
+// again when the Book changed since it was read, so that the Ratings given concurrently are all counted
+func (s *service) aggregateRatings(id string) *BookAPIError {
+	for {
+		book, err := s.repository.FindOne(id)
+		if err != nil {
+			return err
+		}
+
+		ratings, err := s.ratings.FindAll(NewQueryFilter("book_id", Equals, id), FindOptions{})
+		if err != nil {
+			return err
+		}
+
+		summary := summarizeRatings(ratings)
+		rating := 0.0
+		if summary.Count > 0 {
+			rating = ratingScale.Round(summary.Average)
+		}
+
+		fields := Fields{
+			"rating":              rating,
+			"rating_average":      summary.Average,
+			"rating_count":        summary.Count,
+			"rating_distribution": summary.Distribution,
+		}
+
+		updateError := s.repository.Update(id, versionCondition(Versions{book.Version}), fields)
+		if updateError == nil || updateError.errorType != PreconditionFailed {
+			return toUpdateError(updateError)
+		}
+	}
+}
+
+// summarizeRatings returns the average, rounded to 2 decimals, count and distribution over the scale of the Ratings
+func summarizeRatings(ratings Ratings) RatingSummary {
+	summary := RatingSummary{Count: len(ratings), Distribution: RatingDistribution{}}
+	for _, value := range ratingScale.Values() {
+		summary.Distribution[ratingKey(value)] = 0
+	}
+
+	total := 0.0
+	for _, rating := range ratings {
+		summary.Distribution[ratingKey(rating.Value)]++
+		total += rating.Value
+	}
+
+	if summary.Count > 0 {
+		summary.Average = math.Round(total/float64(summary.Count)*100) / 100
+	}
+
+	return summary
+}
+
+// checkVersion returns a Precondition Failed error if the Book is at none of the versions, AnyVersion matching any
+func checkVersion(book Book, versions Versions) *BookAPIError {
+	if !versions.Match(book.Version) {
+		return NewPreconditionFailedError(bookKind, book.ID.Hex())
 	}
 
 	return nil
 }
 
+// versionCondition returns the condition of a write of a Book or a Copy at one of the versions, none for AnyVersion.
+// Copies saved before versioning store no version and are at version 0
+func versionCondition(versions Versions) Filter {
+	conditions := make([]Filter, 0, len(versions))
+	for _, version := range versions {
+		condition := NewQueryFilter("version", Equals, version)
+		if version == 0 {
+			condition = AnyOf(condition, NewQueryFilter("version", Equals, nil))
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return AnyOf(conditions...)
+}
+
+// toUpdateError keeps the errors of a conditional update the client can act on, the others become Update errors
+func toUpdateError(err *BookAPIError) *BookAPIError {
+	if err == nil || err.errorType == NotFoundError || err.errorType == PreconditionFailed {
+		return err
+	}
+
+	return NewUpdateError(err.Error())
+}
+
+// newestLoansFirst find options listing the loans of a Book or a Patron
+var newestLoansFirst = FindOptions{Sort: []Sort{{Field: "checked_out_at", Order: DESC}}}
+
+// newestRatingsFirst find options listing the ratings of a Book
+var newestRatingsFirst = FindOptions{Sort: []Sort{{Field: "rated_at", Order: DESC}, {Field: "_id", Order: DESC}}}
+
+// holdLine find options listing the holds of a Book in line, first come first served
+var holdLine = FindOptions{Sort: []Sort{{Field: "placed_at", Order: ASC}, {Field: "_id", Order: ASC}}}
+
+// loanTime returns the current time in UTC to the millisecond, the precision every store keeps
+func loanTime() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
 // NewService creates instance of service
-func NewService(repository Repository) Service {
-	return &service{repository: repository}
+func NewService(repository Repository, patrons PatronRepository, loans LoanRepository, holds HoldRepository,
+	copies CopyRepository, ratings RatingRepository, policy LoanPolicy) Service {
+	return &service{repository: repository, patrons: patrons, loans: loans, holds: holds, copies: copies,
+		ratings: ratings, policy: policy, now: loanTime}
 }
@@ -1,12 +1,13 @@
 package domain
 
 import (
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestService_FindAll(t *testing.T) {
@@ -16,10 +17,10 @@ func TestService_FindAll(t *testing.T) {
 		},
 	}
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 
-	bookRepo.EXPECT().FindAll(nil, nil).Return(books, nil)
-	books, err := bookService.FindAll(nil, nil)
+	bookRepo.EXPECT().FindAll(Filter{}, FindOptions{}).Return(books, nil)
+	books, err := bookService.FindAll(Filter{}, FindOptions{})
 
 	if err != nil {
 		t.Fatalf(`Invalid response.. Expected to get response but got error %s`, err.Error())
@@ -31,20 +32,47 @@ func TestService_FindAll(t *testing.T) {
 }
 
 func TestService_FindAll_WithError(t *testing.T) {
-	findOptions := &options.FindOptions{}
-	findOptions = findOptions.SetSkip(0).SetLimit(10)
+	findOptions := FindOptions{Skip: 0, Limit: 10}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 
-	bookRepo.EXPECT().FindAll(bson.M{}, findOptions).Return(nil, NewDatabaseOperationError("connection error"))
-	_, err := bookService.FindAll(bson.M{}, findOptions)
+	bookRepo.EXPECT().FindAll(Filter{}, findOptions).Return(nil, NewDatabaseOperationError("connection error"))
+	_, err := bookService.FindAll(Filter{}, findOptions)
 
 	if err == nil {
 		t.Fatalf(`Invalid response.. Expected to get an error but was nil`)
 	}
 }
 
+func TestService_Count(t *testing.T) {
+	filter := NewQueryFilter("rating", GreaterThan, float64(1))
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+
+	bookRepo.EXPECT().Count(filter).Return(int64(2), nil)
+	count, err := bookService.Count(filter)
+
+	if err != nil {
+		t.Fatalf(`Invalid response.. Expected to get response but got error %s`, err.Error())
+	}
+
+	if count != 2 {
+		t.Fatalf(`Invalid response.. Expected 2 books but got %d`, count)
+	}
+}
+
+func TestService_Search_WithoutWords(t *testing.T) {
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+
+	_, _, err := bookService.Search(" -- ", FindOptions{})
+
+	if err == nil || err.errorType != ValidationError {
+		t.Fatalf(`Invalid response.. Expected to get a validation error`)
+	}
+}
+
 func TestService_FindOne(t *testing.T) {
 	testBook := Book{
 		ID:     primitive.NewObjectID(),
@@ -52,7 +80,7 @@ func TestService_FindOne(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
 	resBook, _ := bookService.FindOne(testBook.ID.Hex())
@@ -68,9 +96,9 @@ func TestService_Update_WithValidationError(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), testBook).Return(nil)
-	err := bookService.Update(testBook.ID.Hex(), testBook)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), testBook).Return(nil)
+	err := bookService.Update(testBook.ID.Hex(), AnyVersion, testBook)
 
 	assert.NotNil(t, err, `Invalid response.. Expected error but Got %s\n`, err.Error())
 }
@@ -87,7 +115,7 @@ func TestService_Create(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 	bookRepo.EXPECT().IsExistingEntry(testBook).Return(false)
 	bookRepo.EXPECT().Save(testBook).Return(testBook.ID.Hex(), nil)
 	bookId, err := bookService.Create(testBook)
@@ -104,7 +132,7 @@ func TestService_Create_WithExisting(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 	bookRepo.EXPECT().IsExistingEntry(testBook).Return(true)
 	_, err := bookService.Create(testBook)
 
@@ -119,12 +147,13 @@ func TestService_Create_WithValidationError(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 	bookRepo.EXPECT().IsExistingEntry(testBook).Return(false)
 	_, err := bookService.Create(testBook)
 
 	assert.Equal(t, err.errorType, ValidationError,
 		`Invalid response.. Expected validation error but Got %s\n`, err.errorType.Name())
+	assert.Contains(t, err.fields, FieldError{Field: "title", Rule: "required", Message: "cannot be blank"})
 }
 
 func TestService_Create_WithPersistenceError(t *testing.T) {
@@ -139,7 +168,7 @@ func TestService_Create_WithPersistenceError(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 	bookRepo.EXPECT().IsExistingEntry(testBook).Return(false)
 	bookRepo.EXPECT().Save(testBook).Return("", NewPersistError("Error"))
 	_, err := bookService.Create(testBook)
@@ -155,11 +184,11 @@ func TestService_Delete(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(Book{}, nil)
-	bookRepo.EXPECT().Delete(testBook.ID.Hex()).Return(nil)
-	err := bookService.Delete(testBook.ID.Hex())
+	bookRepo.EXPECT().Delete(testBook.ID.Hex(), Filter{}).Return(nil)
+	err := bookService.Delete(testBook.ID.Hex(), AnyVersion)
 
 	assert.Nil(t, err, `Invalid response.. Expected no error but Got %s\n`, err)
 }
@@ -176,14 +205,118 @@ func TestService_Update(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(Book{}, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(nil)
-	err := bookService.Update(testBook.ID.Hex(), testBook)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), versionCondition(AnyVersion), gomock.Any()).Return(nil)
+	err := bookService.Update(testBook.ID.Hex(), AnyVersion, testBook)
 
 	assert.Nil(t, err, `Invalid response.. Expected error to be nul but Got %s\n`, err)
 }
 
+func TestService_Update_KeepsRating(t *testing.T) {
+	testBook := newPatchBook()
+	updated := testBook
+	updated.Rating, updated.Publisher = 3, "Pearson"
+
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), versionCondition(AnyVersion), gomock.Any()).DoAndReturn(
+		func(id string, condition Filter, fields Fields) *BookAPIError {
+			// the rating is the average of the Ratings of the Patrons, only rating the Book changes it
+			assert.NotContains(t, fields, "rating")
+			assert.Equal(t, "Pearson", fields["publisher"])
+			return nil
+		})
+
+	assert.Nil(t, bookService.Update(testBook.ID.Hex(), AnyVersion, updated))
+}
+
+func TestService_Patch(t *testing.T) {
+	testBook := newPatchBook()
+
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), versionCondition(Versions{0}), Fields{"publisher": "Pearson"}).Return(nil)
+	patched, err := bookService.Patch(testBook.ID.Hex(), AnyVersion, MergePatch(`{"publisher": "Pearson", "title": "Clean Code"}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Pearson", patched.Publisher)
+}
+
+func TestService_Patch_WithoutChanges(t *testing.T) {
+	testBook := newPatchBook()
+
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	patched, err := bookService.Patch(testBook.ID.Hex(), AnyVersion, JSONPatch(`[{"op": "test", "path": "/rating", "value": 2}]`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, testBook, patched)
+}
+
+func TestService_Patch_WithInvalidResult(t *testing.T) {
+	testBook := newPatchBook()
+	cases := []struct {
+		patch  BookPatch
+		fields []FieldError
+	}{
+		{MergePatch(`{"_id": "5ca7c76f9287bd3832d96f16"}`), []FieldError{{Field: "_id", Rule: "immutable", Message: "_id cannot be changed"}}},
+		{JSONPatch(`[{"op": "remove", "path": "/author"}]`), []FieldError{{Field: "author", Rule: "required", Message: "cannot be blank"}}},
+		{MergePatch(`{"rating": 3}`), []FieldError{{Field: "rating", Rule: "immutable", Message: "rating cannot be changed"}}},
+	}
+
+	for _, c := range cases {
+		bookRepo := NewMockRepository(gomock.NewController(t))
+		bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+		bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+		_, err := bookService.Patch(testBook.ID.Hex(), AnyVersion, c.patch)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, ValidationError, err.errorType)
+		assert.Equal(t, c.fields, err.fields)
+	}
+}
+
+func TestService_Patch_WithStaleVersion(t *testing.T) {
+	testBook := newPatchBook()
+	testBook.Version = 3
+
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	_, err := bookService.Patch(testBook.ID.Hex(), Versions{2}, MergePatch(`{"publisher": "Pearson"}`))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+}
+
+func TestService_Patch_WithConcurrentWrite(t *testing.T) {
+	testBook := newPatchBook()
+	testBook.Version = 3
+
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), versionCondition(Versions{3}), Fields{"publisher": "Pearson"}).Return(NewPreconditionFailedError(bookKind, testBook.ID.Hex()))
+	_, err := bookService.Patch(testBook.ID.Hex(), Versions{3}, MergePatch(`{"publisher": "Pearson"}`))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+}
+
+func TestService_Patch_WithNotFound(t *testing.T) {
+	bookRepo := NewMockRepository(gomock.NewController(t))
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
+	bookRepo.EXPECT().FindOne("5ca7c76f9287bd3832d96f15").Return(Book{}, NewNotFoundError("5ca7c76f9287bd3832d96f15"))
+	_, err := bookService.Patch("5ca7c76f9287bd3832d96f15", AnyVersion, MergePatch(`{"publisher": "Pearson"}`))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, NotFoundError, err.errorType)
+}
+
 func TestService_UpdateWithNotFoundError(t *testing.T) {
 	testBook := Book{
 		ID:          primitive.NewObjectID(),
@@ -196,9 +329,9 @@ func TestService_UpdateWithNotFoundError(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(Book{}, NewNotFoundError(testBook.ID.Hex()))
-	err := bookService.Update(testBook.ID.Hex(), testBook)
+	err := bookService.Update(testBook.ID.Hex(), AnyVersion, testBook)
 
 	assert.NotNil(t, err, `Invalid response.. Expected error to be nul but Got %s\n`, err)
 }
@@ -210,10 +343,10 @@ func TestService_DeleteWithNotFoundError(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(Book{}, NewNotFoundError(testBook.ID.Hex()))
-	err := bookService.Delete(testBook.ID.Hex())
+	err := bookService.Delete(testBook.ID.Hex(), AnyVersion)
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err.errorType.Name())
 }
@@ -225,30 +358,86 @@ func TestService_DeleteWithError(t *testing.T) {
 	}
 
 	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService := NewService(bookRepo, nil, nil, nil, nil, nil, DefaultLoanPolicy)
 
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(Book{}, nil)
-	bookRepo.EXPECT().Delete(testBook.ID.Hex()).Return(NewDatabaseOperationError("error deleting"))
-	err := bookService.Delete(testBook.ID.Hex())
+	bookRepo.EXPECT().Delete(testBook.ID.Hex(), Filter{}).Return(NewDatabaseOperationError("error deleting"))
+	err := bookService.Delete(testBook.ID.Hex(), AnyVersion)
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err.errorType.Name())
 }
 
+// checkedOutAt time of the loans of the checkout tests
+var checkedOutAt = time.Date(2019, 4, 1, 10, 0, 0, 0, time.UTC)
+
+func newLoanService(t *testing.T) (Service, *MockRepository, *MockPatronRepository, *MockLoanRepository) {
+	ctrl := gomock.NewController(t)
+	bookRepo, patronRepo, loanRepo := NewMockRepository(ctrl), NewMockPatronRepository(ctrl), NewMockLoanRepository(ctrl)
+	// nobody is in line for the Books of these tests, which have no copies
+	holds, copies := &holdRepo{collection: newMemoryCollection(nil)}, &copyRepo{collection: newMemoryCollection(nil)}
+	bookService := &service{repository: bookRepo, patrons: patronRepo, loans: loanRepo, holds: holds, copies: copies, policy: DefaultLoanPolicy, now: func() time.Time { return checkedOutAt }}
+	return bookService, bookRepo, patronRepo, loanRepo
+}
+
 func TestService_CheckOut(t *testing.T) {
 	testBook := Book{
 		ID:     primitive.NewObjectID(),
 		Author: "thg090020",
 		Status: CheckedIn,
 	}
+	patronID, loanID := primitive.NewObjectID().Hex(), primitive.NewObjectID()
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, patronRepo, loanRepo := newLoanService(t)
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(nil)
-	err := bookService.CheckOut(testBook.ID.Hex())
+	patronRepo.EXPECT().FindOne(patronID).Return(Patron{}, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), NewQueryFilter("status", Equals, CheckedIn), Fields{"status": CheckedOut}).Return(nil)
+	expected := Loan{BookID: testBook.ID.Hex(), PatronID: patronID, CheckedOutAt: checkedOutAt, DueAt: checkedOutAt.Add(21 * 24 * time.Hour)}
+	loanRepo.EXPECT().Save(expected).Return(loanID.Hex(), nil)
+	loan, err := bookService.CheckOut(testBook.ID.Hex(), AnyVersion, LoanRequest{PatronID: patronID})
 
 	assert.Nil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	expected.ID = loanID
+	assert.Equal(t, expected, loan)
+}
+
+func TestService_CheckOut_WithVersion(t *testing.T) {
+	testBook := Book{
+		ID:      primitive.NewObjectID(),
+		Author:  "thg090020",
+		Status:  CheckedIn,
+		Version: 4,
+	}
+	patronID := primitive.NewObjectID().Hex()
+
+	bookService, bookRepo, patronRepo, loanRepo := newLoanService(t)
+	patronRepo.EXPECT().FindOne(patronID).Return(Patron{}, nil).Times(2)
+
+	condition := AllOf(versionCondition(Versions{4}), NewQueryFilter("status", Equals, CheckedIn))
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), condition, Fields{"status": CheckedOut}).Return(nil)
+	loanRepo.EXPECT().Save(gomock.Any()).Return(primitive.NewObjectID().Hex(), nil)
+	_, err := bookService.CheckOut(testBook.ID.Hex(), Versions{4}, LoanRequest{PatronID: patronID})
+	assert.Nil(t, err)
+
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), Fields{"status": CheckedOut}).Return(NewPreconditionFailedError(bookKind, testBook.ID.Hex()))
+	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
+	_, err = bookService.CheckOut(testBook.ID.Hex(), Versions{3}, LoanRequest{PatronID: patronID})
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+}
+
+func TestService_CheckOut_WithUnknownPatron(t *testing.T) {
+	bookService, _, patronRepo, _ := newLoanService(t)
+	patronID := primitive.NewObjectID().Hex()
+
+	_, err := bookService.CheckOut(primitive.NewObjectID().Hex(), AnyVersion, LoanRequest{PatronID: ""})
+	assert.NotNil(t, err)
+	assert.Equal(t, []FieldError{{Field: "patron_id", Rule: "required", Message: "patron_id cannot be blank"}}, err.fields)
+
+	patronRepo.EXPECT().FindOne(patronID).Return(Patron{}, NewPatronNotFoundError(patronID))
+	_, err = bookService.CheckOut(primitive.NewObjectID().Hex(), AnyVersion, LoanRequest{PatronID: patronID})
+	assert.NotNil(t, err)
+	assert.Equal(t, ValidationError, err.errorType)
+	assert.Equal(t, "exists", err.fields[0].Rule)
 }
 
 func TestService_CheckOut_WithNotFound(t *testing.T) {
@@ -258,14 +447,14 @@ func TestService_CheckOut_WithNotFound(t *testing.T) {
 		Status: CheckedIn,
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, patronRepo, _ := newLoanService(t)
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, NewNotFoundError(testBook.ID.Hex()))
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(nil)
-	err := bookService.CheckOut(testBook.ID.Hex())
+	patronRepo.EXPECT().FindOne(gomock.Any()).Return(Patron{}, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), gomock.Any()).Return(NewNotFoundError(testBook.ID.Hex()))
+	_, err := bookService.CheckOut(testBook.ID.Hex(), AnyVersion, LoanRequest{PatronID: primitive.NewObjectID().Hex()})
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	assert.Equal(t, NotFoundError, err.errorType)
 }
 
 func TestService_CheckOut_WithCheckedOutError(t *testing.T) {
@@ -275,13 +464,15 @@ func TestService_CheckOut_WithCheckedOutError(t *testing.T) {
 		Status: CheckedOut,
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, patronRepo, _ := newLoanService(t)
 
+	patronRepo.EXPECT().FindOne(gomock.Any()).Return(Patron{}, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), gomock.Any()).Return(NewPreconditionFailedError(bookKind, testBook.ID.Hex()))
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	err := bookService.CheckOut(testBook.ID.Hex())
+	_, err := bookService.CheckOut(testBook.ID.Hex(), AnyVersion, LoanRequest{PatronID: primitive.NewObjectID().Hex()})
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	assert.Equal(t, AlreadyCheckedOut, err.errorType)
 }
 
 func TestService_CheckOut_WithPersistenceError(t *testing.T) {
@@ -291,14 +482,77 @@ func TestService_CheckOut_WithPersistenceError(t *testing.T) {
 		Status: CheckedIn,
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, patronRepo, _ := newLoanService(t)
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(NewPersistError("error"))
-	err := bookService.CheckOut(testBook.ID.Hex())
+	patronRepo.EXPECT().FindOne(gomock.Any()).Return(Patron{}, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), gomock.Any()).Return(NewPersistError("error"))
+	_, err := bookService.CheckOut(testBook.ID.Hex(), AnyVersion, LoanRequest{PatronID: primitive.NewObjectID().Hex()})
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	assert.Equal(t, UpdateError, err.errorType)
+}
+
+func TestService_CheckOut_WithLoanPersistenceError(t *testing.T) {
+	testBook := Book{
+		ID:     primitive.NewObjectID(),
+		Author: "thg090020",
+		Status: CheckedIn,
+	}
+
+	bookService, bookRepo, patronRepo, loanRepo := newLoanService(t)
+
+	patronRepo.EXPECT().FindOne(gomock.Any()).Return(Patron{}, nil)
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), Fields{"status": CheckedOut}).Return(nil)
+	loanRepo.EXPECT().Save(gomock.Any()).Return("", NewPersistError("error"))
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), NewQueryFilter("status", Equals, CheckedOut), Fields{"status": CheckedIn}).Return(nil)
+	_, err := bookService.CheckOut(testBook.ID.Hex(), AnyVersion, LoanRequest{PatronID: primitive.NewObjectID().Hex()})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, PersistError, err.errorType)
+}
+
+func TestService_CheckOut_Concurrently(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	patronID, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	loans := &loanRepo{collection: newMemoryCollection(nil)}
+	bookService := NewService(repo, patrons, loans, &holdRepo{collection: newMemoryCollection(nil)},
+		&copyRepo{collection: newMemoryCollection(nil)}, nil, DefaultLoanPolicy)
+
+	const checkouts = 50
+	errs := make(chan *BookAPIError, checkouts)
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := 0; i < checkouts; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			_, err := bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: patronID})
+			errs <- err
+		}()
+	}
+	start.Done()
+	done.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, AlreadyCheckedOut, err.errorType)
+	}
+
+	assert.Equal(t, 1, succeeded)
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, CheckedOut, book.Status)
+	assert.Equal(t, int64(2), book.Version)
+
+	history, _ := bookService.Loans(id)
+	assert.Len(t, history, 1)
 }
 
 func TestService_CheckIn(t *testing.T) {
@@ -307,17 +561,28 @@ func TestService_CheckIn(t *testing.T) {
 		Author: "thg090020",
 		Status: CheckedOut,
 	}
+	loan := Loan{ID: primitive.NewObjectID(), BookID: testBook.ID.Hex()}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, _, loanRepo := newLoanService(t)
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(nil)
-	err := bookService.CheckIn(testBook.ID.Hex())
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), NewQueryFilter("status", Equals, CheckedOut), Fields{"status": CheckedIn}).Return(nil)
+	open := AllOf(NewQueryFilter("book_id", Equals, testBook.ID.Hex()), NewQueryFilter("returned_at", Equals, nil))
+	loanRepo.EXPECT().FindAll(open, FindOptions{}).Return(Loans{loan}, nil)
+	loanRepo.EXPECT().Update(loan.ID.Hex(), NewQueryFilter("returned_at", Equals, nil), Fields{"returned_at": checkedOutAt}).Return(nil)
+	err := bookService.CheckIn(testBook.ID.Hex(), AnyVersion)
 
 	assert.Nil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
 }
 
+func TestService_CheckIn_WithoutLoan(t *testing.T) {
+	bookService, bookRepo, _, loanRepo := newLoanService(t)
+
+	bookRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	loanRepo.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(Loans{}, nil)
+
+	assert.Nil(t, bookService.CheckIn(primitive.NewObjectID().Hex(), AnyVersion))
+}
+
 func TestService_CheckIn_WithNotFound(t *testing.T) {
 	testBook := Book{
 		ID:     primitive.NewObjectID(),
@@ -325,14 +590,13 @@ func TestService_CheckIn_WithNotFound(t *testing.T) {
 		Status: CheckedOut,
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, _, _ := newLoanService(t)
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, NewNotFoundError(testBook.ID.Hex()))
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(nil)
-	err := bookService.CheckIn(testBook.ID.Hex())
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), gomock.Any()).Return(NewNotFoundError(testBook.ID.Hex()))
+	err := bookService.CheckIn(testBook.ID.Hex(), AnyVersion)
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	assert.Equal(t, NotFoundError, err.errorType)
 }
 
 func TestService_CheckIn_WithCheckedOutError(t *testing.T) {
@@ -342,13 +606,14 @@ func TestService_CheckIn_WithCheckedOutError(t *testing.T) {
 		Status: CheckedIn,
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, _, _ := newLoanService(t)
 
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), gomock.Any()).Return(NewPreconditionFailedError(bookKind, testBook.ID.Hex()))
 	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	err := bookService.CheckIn(testBook.ID.Hex())
+	err := bookService.CheckIn(testBook.ID.Hex(), AnyVersion)
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	assert.Equal(t, AlreadyCheckedIn, err.errorType)
 }
 
 func TestService_CheckIn_WithPersistenceError(t *testing.T) {
@@ -358,93 +623,150 @@ func TestService_CheckIn_WithPersistenceError(t *testing.T) {
 		Status: CheckedOut,
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	bookService, bookRepo, _, _ := newLoanService(t)
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(NewPersistError("error"))
-	err := bookService.CheckIn(testBook.ID.Hex())
+	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any(), gomock.Any()).Return(NewPersistError("error"))
+	err := bookService.CheckIn(testBook.ID.Hex(), AnyVersion)
 
 	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
 }
 
-func TestService_Rate(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      2,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
-	}
-
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+func TestService_Loans(t *testing.T) {
+	bookService, bookRepo, _, loanRepo := newLoanService(t)
+	id := primitive.NewObjectID().Hex()
+	loans := Loans{{ID: primitive.NewObjectID(), BookID: id}}
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(nil)
-	err := bookService.Rate(testBook.ID.Hex(), 2)
+	bookRepo.EXPECT().FindOne(id).Return(Book{}, nil)
+	loanRepo.EXPECT().FindAll(NewQueryFilter("book_id", Equals, id), newestLoansFirst).Return(loans, nil)
+	history, err := bookService.Loans(id)
+	assert.Nil(t, err)
+	assert.Equal(t, loans, history)
 
-	assert.Nil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	bookRepo.EXPECT().FindOne(id).Return(Book{}, NewNotFoundError(id))
+	_, err = bookService.Loans(id)
+	assert.Equal(t, NotFoundError, err.errorType)
 }
 
-func TestService_Rate_WithNotFound(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      2,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
-	}
+func TestService_CheckOut_WithLoanDays(t *testing.T) {
+	bookService, bookRepo, patronRepo, loanRepo := newLoanService(t)
+	id, patronID := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
-
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, NewNotFoundError(testBook.ID.Hex()))
-	err := bookService.Rate(testBook.ID.Hex(), 2)
+	patronRepo.EXPECT().FindOne(patronID).Return(Patron{}, nil)
+	bookRepo.EXPECT().Update(id, gomock.Any(), Fields{"status": CheckedOut}).Return(nil)
+	loanRepo.EXPECT().Save(gomock.Any()).Return(primitive.NewObjectID().Hex(), nil)
+	loan, err := bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: patronID, Days: 7})
+	assert.Nil(t, err)
+	assert.Equal(t, checkedOutAt.AddDate(0, 0, 7), loan.DueAt)
 
-	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	_, err = bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: patronID, Days: 365})
+	assert.NotNil(t, err)
+	assert.Equal(t, "loan_days", err.fields[0].Field)
 }
 
-func TestService_Rate_WithValidationError(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
+func TestService_Transition(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	alan, _ := patrons.Save(Patron{Name: "Alan Turing", Email: "alan@example.com"})
+	loans := &loanRepo{collection: newMemoryCollection(nil)}
+	bookService := NewService(repo, patrons, loans, &holdRepo{collection: newMemoryCollection(nil)},
+		&copyRepo{collection: newMemoryCollection(nil)}, nil, DefaultLoanPolicy)
+
+	status := func() Status {
+		book, _ := repo.FindOne(id)
+		return book.Status
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	assert.Nil(t, bookService.Transition(id, AnyVersion, InTransit))
+	_, err := bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: ada})
+	assert.Equal(t, IllegalTransition, err.errorType)
+	assert.Equal(t, fmt.Sprintf("Book %s cannot go from InTransit to CheckedOut", id), err.Error())
+
+	err = bookService.Transition(id, AnyVersion, Withdrawn)
+	assert.Equal(t, IllegalTransition, err.errorType)
+	assert.Equal(t, ValidationError, bookService.Transition(id, AnyVersion, CheckedOut).errorType)
+
+	// the loan of a Book lost is closed, and found it goes to the Patron in line
+	assert.Nil(t, bookService.Transition(id, AnyVersion, CheckedIn))
+	_, err = bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: ada})
+	assert.Nil(t, err)
+	assert.Equal(t, ValidationError, bookService.Transition(id, AnyVersion, CheckedIn).errorType)
+	_, err = bookService.PlaceHold(id, HoldRequest{PatronID: alan})
+	assert.Nil(t, err)
+
+	assert.Nil(t, bookService.Transition(id, AnyVersion, Lost))
+	open, _ := loans.FindAll(NewQueryFilter("returned_at", Equals, nil), FindOptions{})
+	assert.Empty(t, open)
+
+	assert.Nil(t, bookService.Transition(id, AnyVersion, CheckedIn))
+	assert.Equal(t, OnHoldShelf, status())
+
+	// damaged on the hold shelf, the Patron it was kept for waits at the head of the line again
+	assert.Nil(t, bookService.Transition(id, AnyVersion, Damaged))
+	line, _ := bookService.Holds(id)
+	if assert.Len(t, line, 1) {
+		assert.Nil(t, line[0].ReadyAt)
+	}
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	err := bookService.Rate(testBook.ID.Hex(), 2)
+	assert.Nil(t, bookService.Transition(id, AnyVersion, InRepair))
+	assert.Nil(t, bookService.Transition(id, AnyVersion, Withdrawn))
+	assert.Equal(t, Withdrawn, status())
 
-	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	book, _ := repo.FindOne(id)
+	book.Status = CheckedIn
+	err = bookService.Update(id, AnyVersion, book)
+	assert.Equal(t, ValidationError, err.errorType)
+	_, err = bookService.Patch(id, AnyVersion, MergePatch(`{"status": 1}`))
+	assert.Equal(t, ValidationError, err.errorType)
 }
 
-func TestService_Rate_WithPersistenceError(t *testing.T) {
-	testBook := Book{
-		ID:          primitive.NewObjectID(),
-		Author:      "thg090020",
-		Title:       "Test Title",
-		Status:      CheckedIn,
-		Rating:      1,
-		Publisher:   "Pub",
-		PublishDate: "2019",
+func TestService_UpdateAndPatch_DoNotChangeStatus(t *testing.T) {
+	repo := NewMemoryRepository()
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	patrons := &patronRepo{collection: newMemoryCollection(nil)}
+	ada, _ := patrons.Save(Patron{Name: "Ada Lovelace", Email: "ada@example.com"})
+	loans := &loanRepo{collection: newMemoryCollection(nil)}
+	bookService := NewService(repo, patrons, loans, &holdRepo{collection: newMemoryCollection(nil)},
+		&copyRepo{collection: newMemoryCollection(nil)}, nil, DefaultLoanPolicy)
+
+	statusChange := []FieldError{{Field: "status", Rule: "transition",
+		Message: "status is changed by checking out, checking in or PUT /books/{id}/status/{status}"}}
+	put := func(status Status) *BookAPIError {
+		book, _ := repo.FindOne(id)
+		book.Status = status
+		return bookService.Update(id, AnyVersion, book)
+	}
+	patch := func(status Status) *BookAPIError {
+		_, err := bookService.Patch(id, AnyVersion, MergePatch(fmt.Sprintf(`{"status": "%s"}`, status)))
+		return err
 	}
 
-	bookRepo := NewMockRepository(gomock.NewController(t))
-	bookService := NewService(bookRepo)
+	// neither a Loan nor a Hold follows a Book put or patched out or on the hold shelf
+	for _, status := range []Status{CheckedOut, OnHoldShelf, Lost} {
+		assert.Equal(t, statusChange, put(status).fields, "PUT %s", status)
+		assert.Equal(t, statusChange, patch(status).fields, "PATCH %s", status)
+	}
 
-	bookRepo.EXPECT().FindOne(testBook.ID.Hex()).Return(testBook, nil)
-	bookRepo.EXPECT().Update(testBook.ID.Hex(), gomock.Any()).Return(NewPersistError("error"))
-	err := bookService.Rate(testBook.ID.Hex(), 2)
+	_, err := bookService.CheckOut(id, AnyVersion, LoanRequest{PatronID: ada})
+	assert.Nil(t, err)
 
-	assert.NotNil(t, err, `Invalid response.. Expected an error but Got %s\n`, err)
+	// the Loan of a Book checked out is only closed by checking it in or losing it
+	for _, status := range []Status{CheckedIn, Lost} {
+		assert.Equal(t, statusChange, put(status).fields, "PUT %s", status)
+		assert.Equal(t, statusChange, patch(status).fields, "PATCH %s", status)
+	}
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, CheckedOut, book.Status)
+	open, _ := loans.FindAll(NewQueryFilter("returned_at", Equals, nil), FindOptions{})
+	assert.Len(t, open, 1)
+
+	// the other fields are still put and patched
+	book.Title = "Clean Code, 2nd Edition"
+	assert.Nil(t, bookService.Update(id, AnyVersion, book))
+	patched, err := bookService.Patch(id, AnyVersion, MergePatch(`{"publisher": "Pearson"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, CheckedOut, patched.Status)
+	assert.Equal(t, "Clean Code, 2nd Edition", patched.Title)
 }
@@ -0,0 +1,257 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"strings"
+)
+
+// sqlTable columns of a collection table, named after the bson fields except _id stored in the id column
+type sqlTable struct {
+	fields []string
+}
+
+// sqlTables tables of the collections, created by the migrations
+var sqlTables = map[string]sqlTable{
+	"patrons": {fields: []string{"_id", "name", "email"}},
+	"loans":   {fields: []string{"_id", "book_id", "copy_id", "patron_id", "checked_out_at", "due_at", "returned_at", "overdue_at"}},
+	"holds":   {fields: []string{"_id", "book_id", "copy_id", "patron_id", "placed_at", "ready_at", "expires_at"}},
+	"copies":  {fields: []string{"_id", "book_id", "barcode", "branch", "condition", "status", "version"}},
+	"ratings": {fields: []string{"_id", "book_id", "patron_id", "rating", "rated_at", "rating_scale"}},
+	"reviews": {fields: []string{"_id", "book_id", "patron_id", "title", "text", "status", "helpful_votes", "created_at",
+		"updated_at", "moderated_at"}},
+	"review_votes":  {fields: []string{"_id", "review_id", "patron_id", "voted_at"}},
+	"rating_scales": {fields: []string{"_id", "min", "max", "step"}},
+}
+
+// columns maps the bson field names to their column. Only these fields may be queried.
+func (t sqlTable) columns() map[string]string {
+	columns := make(map[string]string, len(t.fields))
+	for _, field := range t.fields {
+		columns[field] = sqlColumn(field)
+	}
+
+	return columns
+}
+
+func sqlColumn(field string) string {
+	if field == "_id" {
+		return "id"
+	}
+
+	return field
+}
+
+// sqlCollection collection of documents stored in a table of a database/sql database
+type sqlCollection struct {
+	db      *sql.DB
+	dialect sqlDialect
+	name    string
+	table   sqlTable
+}
+
+func (c *sqlCollection) find(filter Filter, findOptions FindOptions, results interface{}) *BookAPIError {
+	columns := c.table.columns()
+	statement := &sqlStatement{dialect: c.dialect, columns: columns}
+	where, err := statement.where(filter)
+	if err != nil {
+		return NewDatabaseOperationError(err.Error())
+	}
+
+	orderBy, err := sqlOrderBy(columns, findOptions.Sort)
+	if err != nil {
+		return NewDatabaseOperationError(err.Error())
+	}
+
+	selected := make([]string, 0, len(c.table.fields))
+	for _, field := range c.table.fields {
+		selected = append(selected, sqlColumn(field))
+	}
+
+	query := "SELECT " + strings.Join(selected, ", ") + " FROM " + c.name + where + orderBy +
+		statement.limit(findOptions.Limit, findOptions.Skip)
+	rows, queryErr := c.db.Query(query, statement.args...)
+	if queryErr != nil {
+		return NewDatabaseOperationError(queryErr.Error())
+	}
+	defer rows.Close()
+
+	docs := []bson.M{}
+	for rows.Next() {
+		doc, scanErr := c.scan(rows)
+		if scanErr != nil {
+			return NewDatabaseOperationError(scanErr.Error())
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return NewDatabaseOperationError(rowsErr.Error())
+	}
+
+	if decodeErr := decodeDocuments(docs, results); decodeErr != nil {
+		return NewDatabaseOperationError(decodeErr.Error())
+	}
+
+	return nil
+}
+
+func (c *sqlCollection) count(filter Filter) (int64, *BookAPIError) {
+	statement := &sqlStatement{dialect: c.dialect, columns: c.table.columns()}
+	where, err := statement.where(filter)
+	if err != nil {
+		return 0, NewDatabaseOperationError(err.Error())
+	}
+
+	var count int64
+	if scanErr := c.db.QueryRow("SELECT COUNT(*) FROM "+c.name+where, statement.args...).Scan(&count); scanErr != nil {
+		return 0, NewDatabaseOperationError(scanErr.Error())
+	}
+
+	return count, nil
+}
+
+func (c *sqlCollection) insert(entity interface{}) (string, *BookAPIError) {
+	doc, id, err := newDocument(entity)
+	if err != nil {
+		return "", NewPersistError(err.Error())
+	}
+
+	statement := &sqlStatement{dialect: c.dialect}
+	columns := make([]string, 0, len(c.table.fields))
+	values := make([]string, 0, len(c.table.fields))
+	for _, field := range c.table.fields {
+		columns = append(columns, sqlColumn(field))
+		values = append(values, statement.bind(doc[field]))
+	}
+
+	query := "INSERT INTO " + c.name + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")"
+	if _, execErr := c.db.Exec(query, statement.args...); execErr != nil {
+		if isDuplicateKey(execErr) {
+			return "", NewDuplicateKeyError(execErr.Error())
+		}
+		return "", NewPersistError(execErr.Error())
+	}
+
+	return id, nil
+}
+
+func (c *sqlCollection) update(id string, condition Filter, fields Fields) (bool, *BookAPIError) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		if name != "_id" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	columns := c.table.columns()
+	statement := &sqlStatement{dialect: c.dialect, columns: columns}
+	assignments := make([]string, 0, len(names))
+	for _, name := range names {
+		column, ok := columns[name]
+		if !ok {
+			return false, NewDatabaseOperationError(fmt.Sprintf("unknown field %s", name))
+		}
+
+		assignments = append(assignments, column+" = "+statement.bind(fields[name]))
+	}
+
+	if len(assignments) == 0 {
+		return false, NewDatabaseOperationError("no fields to update")
+	}
+
+	where, err := statement.where(AllOf(NewQueryFilter("_id", Equals, id), condition))
+	if err != nil {
+		return false, NewDatabaseOperationError(err.Error())
+	}
+
+	result, execErr := c.db.Exec("UPDATE "+c.name+" SET "+strings.Join(assignments, ", ")+where, statement.args...)
+	if execErr != nil {
+		if isDuplicateKey(execErr) {
+			return false, NewDuplicateKeyError(execErr.Error())
+		}
+		return false, NewDatabaseOperationError(execErr.Error())
+	}
+
+	updated, _ := result.RowsAffected()
+	return updated > 0, nil
+}
+
+func (c *sqlCollection) delete(id string, condition Filter) (bool, *BookAPIError) {
+	statement := &sqlStatement{dialect: c.dialect, columns: c.table.columns()}
+	where, err := statement.where(AllOf(NewQueryFilter("_id", Equals, id), condition))
+	if err != nil {
+		return false, NewDatabaseOperationError(err.Error())
+	}
+
+	result, execErr := c.db.Exec("DELETE FROM "+c.name+where, statement.args...)
+	if execErr != nil {
+		return false, NewDatabaseOperationError(execErr.Error())
+	}
+
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
+// scan reads the row into a document with the bson types of the entity: an ObjectID _id and string texts
+func (c *sqlCollection) scan(row rowScanner) (bson.M, error) {
+	values := make([]interface{}, len(c.table.fields))
+	pointers := make([]interface{}, len(values))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := row.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	doc := bson.M{}
+	for i, field := range c.table.fields {
+		value := values[i]
+		if text, ok := value.([]byte); ok {
+			value = string(text)
+		}
+
+		if field == "_id" {
+			id, err := primitive.ObjectIDFromHex(fmt.Sprint(value))
+			if err != nil {
+				return nil, err
+			}
+			value = id
+		}
+
+		if value != nil {
+			doc[field] = value
+		}
+	}
+
+	return doc, nil
+}
+
+// newSQLCollection Initializes the named collection backed by the database/sql driver, migrating the schema
+// to the latest version. The driver must be registered by the caller.
+// It returns an API Error Response if failed
+func newSQLCollection(driver string, dsn string, name string) (collection, *BookAPIError) {
+	table, ok := sqlTables[name]
+	if !ok {
+		return nil, NewDatabaseOperationError(fmt.Sprintf("unknown table %s", name))
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, NewDatabaseOperationError(err.Error())
+	}
+
+	dialect := sqlDialect{driver: driver}
+	if err := migrate(db, dialect); err != nil {
+		_ = db.Close()
+		return nil, NewDatabaseOperationError(err.Error())
+	}
+
+	return &sqlCollection{db: db, dialect: dialect, name: name, table: table}, nil
+}
@@ -0,0 +1,240 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// A sqlMigration - versioned schema change applied once, in order, inside a transaction
+type sqlMigration struct {
+	version     int
+	description string
+	statements  []string
+	// postgres statements applied after the others with PostgreSQL only
+	postgres []string
+}
+
+// sqlMigrations schema history of the SQL repository. Append new versions, never edit applied ones.
+var sqlMigrations = []sqlMigration{
+	{
+		version:     1,
+		description: "create books table",
+		statements: []string{
+			`CREATE TABLE books (
+				id           VARCHAR(24)  PRIMARY KEY,
+				author       VARCHAR(30)  NOT NULL,
+				title        VARCHAR(50)  NOT NULL,
+				publisher    VARCHAR(20)  NOT NULL,
+				status       INTEGER      NOT NULL,
+				rating       INTEGER      NOT NULL DEFAULT 0,
+				publish_date VARCHAR(4)   NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX books_author_title_publish_date ON books (author, title, publish_date)`,
+		},
+	},
+	{
+		version:     2,
+		description: "add books version",
+		statements: []string{
+			`ALTER TABLE books ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version:     3,
+		description: "create patrons and loans tables",
+		statements: []string{
+			`CREATE TABLE patrons (
+				id    VARCHAR(24)  PRIMARY KEY,
+				name  VARCHAR(50)  NOT NULL,
+				email VARCHAR(100) NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX patrons_email ON patrons (email)`,
+			`CREATE TABLE loans (
+				id             VARCHAR(24) PRIMARY KEY,
+				book_id        VARCHAR(24) NOT NULL,
+				patron_id      VARCHAR(24) NOT NULL,
+				checked_out_at TIMESTAMP   NOT NULL,
+				due_at         TIMESTAMP   NOT NULL,
+				returned_at    TIMESTAMP   NULL
+			)`,
+			`CREATE INDEX loans_book_id ON loans (book_id)`,
+			`CREATE INDEX loans_patron_id ON loans (patron_id)`,
+		},
+	},
+	{
+		version:     4,
+		description: "add loans overdue_at",
+		statements: []string{
+			`ALTER TABLE loans ADD COLUMN overdue_at TIMESTAMP NULL`,
+			`CREATE INDEX loans_due_at ON loans (due_at)`,
+		},
+	},
+	{
+		version:     5,
+		description: "create holds table",
+		statements: []string{
+			`CREATE TABLE holds (
+				id         VARCHAR(24) PRIMARY KEY,
+				book_id    VARCHAR(24) NOT NULL,
+				patron_id  VARCHAR(24) NOT NULL,
+				placed_at  TIMESTAMP   NOT NULL,
+				ready_at   TIMESTAMP   NULL,
+				expires_at TIMESTAMP   NULL
+			)`,
+			`CREATE INDEX holds_book_id ON holds (book_id, placed_at)`,
+			`CREATE INDEX holds_expires_at ON holds (expires_at)`,
+		},
+	},
+	{
+		version:     6,
+		description: "create copies table",
+		statements: []string{
+			`CREATE TABLE copies (
+				id        VARCHAR(24) PRIMARY KEY,
+				book_id   VARCHAR(24) NOT NULL,
+				barcode   VARCHAR(30) NOT NULL,
+				branch    VARCHAR(50) NOT NULL,
+				condition VARCHAR(10) NOT NULL,
+				status    INTEGER     NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX copies_barcode ON copies (barcode)`,
+			`CREATE INDEX copies_book_id ON copies (book_id)`,
+			`ALTER TABLE loans ADD COLUMN copy_id VARCHAR(24) NULL`,
+			`ALTER TABLE holds ADD COLUMN copy_id VARCHAR(24) NULL`,
+		},
+	},
+	{
+		version:     7,
+		description: "create ratings table and add books rating aggregates",
+		statements: []string{
+			`CREATE TABLE ratings (
+				id        VARCHAR(24) PRIMARY KEY,
+				book_id   VARCHAR(24) NOT NULL,
+				patron_id VARCHAR(24) NOT NULL,
+				rating    INTEGER     NOT NULL,
+				rated_at  TIMESTAMP   NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX ratings_book_id_patron_id ON ratings (book_id, patron_id)`,
+			`ALTER TABLE books ADD COLUMN rating_average DOUBLE PRECISION NOT NULL DEFAULT 0`,
+			`ALTER TABLE books ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE books ADD COLUMN rating_distribution TEXT NOT NULL DEFAULT '{}'`,
+			`CREATE INDEX books_rating_average ON books (rating_average)`,
+		},
+	},
+	{
+		version:     8,
+		description: "create reviews and review_votes tables",
+		statements: []string{
+			`CREATE TABLE reviews (
+				id            VARCHAR(24)  PRIMARY KEY,
+				book_id       VARCHAR(24)  NOT NULL,
+				patron_id     VARCHAR(24)  NOT NULL,
+				title         VARCHAR(100) NOT NULL,
+				text          TEXT         NOT NULL,
+				status        VARCHAR(10)  NOT NULL,
+				helpful_votes INTEGER      NOT NULL DEFAULT 0,
+				created_at    TIMESTAMP    NOT NULL,
+				updated_at    TIMESTAMP    NOT NULL,
+				moderated_at  TIMESTAMP    NULL
+			)`,
+			`CREATE UNIQUE INDEX reviews_book_id_patron_id ON reviews (book_id, patron_id)`,
+			`CREATE INDEX reviews_status_created_at ON reviews (status, created_at)`,
+			`CREATE TABLE review_votes (
+				id        VARCHAR(24) PRIMARY KEY,
+				review_id VARCHAR(24) NOT NULL,
+				patron_id VARCHAR(24) NOT NULL,
+				voted_at  TIMESTAMP   NOT NULL
+			)`,
+			`CREATE UNIQUE INDEX review_votes_review_id_patron_id ON review_votes (review_id, patron_id)`,
+		},
+	},
+	{
+		version:     9,
+		description: "create rating_scales table and store fractional ratings",
+		statements: []string{
+			`CREATE TABLE rating_scales (
+				id   VARCHAR(24)      PRIMARY KEY,
+				min  DOUBLE PRECISION NOT NULL,
+				max  DOUBLE PRECISION NOT NULL,
+				step DOUBLE PRECISION NOT NULL
+			)`,
+		},
+		// SQLite keeps the fractional values of INTEGER columns as they are
+		postgres: []string{
+			`ALTER TABLE books ALTER COLUMN rating TYPE DOUBLE PRECISION`,
+			`ALTER TABLE ratings ALTER COLUMN rating TYPE DOUBLE PRECISION`,
+		},
+	},
+	{
+		version:     10,
+		description: "record the scale ratings were rescaled to",
+		statements: []string{
+			`ALTER TABLE books ADD COLUMN rating_scale VARCHAR(20) NOT NULL DEFAULT ''`,
+			`ALTER TABLE ratings ADD COLUMN rating_scale VARCHAR(20) NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version:     11,
+		description: "add copies version",
+		statements: []string{
+			`ALTER TABLE copies ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+}
+
+// migrate applies every migration newer than the current schema version
+func migrate(db *sql.DB, dialect sqlDialect) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for _, migration := range sqlMigrations {
+		if int64(migration.version) <= current.Int64 {
+			continue
+		}
+
+		if err := applyMigration(db, dialect, migration); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %s", migration.version, migration.description, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, dialect sqlDialect, migration sqlMigration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	statements := migration.statements
+	if dialect.driver == "postgres" {
+		statements = append(append([]string{}, statements...), migration.postgres...)
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	insert := fmt.Sprintf(`INSERT INTO schema_migrations (version, applied_at) VALUES (%s, %s)`,
+		dialect.placeholder(1), dialect.placeholder(2))
+	if _, err := tx.Exec(insert, migration.version, time.Now().UTC()); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
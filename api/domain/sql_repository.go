@@ -0,0 +1,507 @@
+package domain
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"strings"
+)
+
+// sqlColumns maps the Book bson field names to their column. Only these fields may be queried.
+var sqlColumns = map[string]string{
+	"_id":          "id",
+	"author":       "author",
+	"title":        "title",
+	"publisher":    "publisher",
+	"status":       "status",
+	"rating":       "rating",
+	"publish_date": "publish_date",
+	"version":      "version",
+	// rating aggregates
+	"rating_average":      "rating_average",
+	"rating_count":        "rating_count",
+	"rating_distribution": "rating_distribution",
+	"rating_scale":        "rating_scale",
+}
+
+const sqlSelectBooks = `SELECT id, author, title, publisher, status, rating, publish_date, version,
+	rating_average, rating_count, rating_distribution, rating_scale FROM books`
+
+// sqlDialect hides the syntax differences between the supported database drivers
+type sqlDialect struct {
+	driver string
+}
+
+func (d sqlDialect) placeholder(position int) string {
+	if d.driver == "postgres" {
+		return fmt.Sprintf("$%d", position)
+	}
+
+	return "?"
+}
+
+// unlimited returns the LIMIT value meaning no limit, required when only an OFFSET is set
+func (d sqlDialect) unlimited() string {
+	if d.driver == "postgres" {
+		return "ALL"
+	}
+
+	return "-1"
+}
+
+type sqlRepo struct {
+	db      *sql.DB
+	dialect sqlDialect
+	// index search index of the rows, kept in sync with the writes of this process
+	index *searchIndex
+}
+
+// FindAll Queries the books table with optional filters on custom attributes and find options
+// It returns a list of paginated Books or an API Error Response
+func (r *sqlRepo) FindAll(filter Filter, findOptions FindOptions) (Books, *BookAPIError) {
+	statement := &sqlStatement{dialect: r.dialect}
+	where, err := statement.where(filter)
+	if err != nil {
+		return nil, NewDatabaseOperationError(err.Error())
+	}
+
+	orderBy, err := sqlOrderBy(sqlColumns, findOptions.Sort)
+	if err != nil {
+		return nil, NewDatabaseOperationError(err.Error())
+	}
+
+	query := sqlSelectBooks + where + orderBy + statement.limit(findOptions.Limit, findOptions.Skip)
+	rows, queryErr := r.db.Query(query, statement.args...)
+	if queryErr != nil {
+		return nil, NewDatabaseOperationError(queryErr.Error())
+	}
+	defer rows.Close()
+
+	var books = Books{}
+	for rows.Next() {
+		book, scanErr := scanBook(rows)
+		if scanErr != nil {
+			return nil, NewDatabaseOperationError(scanErr.Error())
+		}
+
+		books = append(books, book)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, NewDatabaseOperationError(rowsErr.Error())
+	}
+
+	return books, nil
+}
+
+// Count Counts the rows of the books table matching the filter
+// It returns the number of Books or an API Error Response
+func (r *sqlRepo) Count(filter Filter) (int64, *BookAPIError) {
+	statement := &sqlStatement{dialect: r.dialect}
+	where, err := statement.where(filter)
+	if err != nil {
+		return 0, NewDatabaseOperationError(err.Error())
+	}
+
+	var count int64
+	if scanErr := r.db.QueryRow("SELECT COUNT(*) FROM books"+where, statement.args...).Scan(&count); scanErr != nil {
+		return 0, NewDatabaseOperationError(scanErr.Error())
+	}
+
+	return count, nil
+}
+
+// Search Queries the search index of the books table for the Books matching any term of the text
+// It returns the page of Books selected by the find options, most relevant first, with the number of matching Books
+func (r *sqlRepo) Search(text string, findOptions FindOptions) (SearchHits, int64, *BookAPIError) {
+	results := r.index.search(text)
+	page := searchPage(results, findOptions)
+	if len(page) == 0 {
+		return SearchHits{}, int64(len(results)), nil
+	}
+
+	ids := make([]interface{}, 0, len(page))
+	for _, result := range page {
+		ids = append(ids, result.id)
+	}
+
+	books, err := r.FindAll(NewQueryFilter("_id", In, ids), FindOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := map[string]Book{}
+	for _, book := range books {
+		byID[book.ID.Hex()] = book
+	}
+
+	hits := SearchHits{}
+	for _, result := range page {
+		if book, ok := byID[result.id]; ok {
+			hits = append(hits, SearchHit{Book: book, Score: result.score})
+		}
+	}
+
+	return hits, int64(len(results)), nil
+}
+
+// FindOne Queries the books table for a specific Book
+// It returns one Book or an API Error Response
+func (r *sqlRepo) FindOne(id string) (Book, *BookAPIError) {
+	row := r.db.QueryRow(sqlSelectBooks+" WHERE id = "+r.dialect.placeholder(1), id)
+	book, err := scanBook(row)
+	if err == sql.ErrNoRows {
+		return Book{}, NewNotFoundError(id)
+	}
+
+	if err != nil {
+		return Book{}, NewDatabaseOperationError(err.Error())
+	}
+
+	return book, nil
+}
+
+// Delete Hard deletes the Book with the specified ID when it matches the condition
+// It returns an API Error Response if failed
+func (r *sqlRepo) Delete(id string, condition Filter) *BookAPIError {
+	statement := &sqlStatement{dialect: r.dialect}
+	where, err := statement.where(AllOf(NewQueryFilter("_id", Equals, id), condition))
+	if err != nil {
+		return NewDatabaseOperationError(err.Error())
+	}
+
+	result, execErr := r.db.Exec("DELETE FROM books"+where, statement.args...)
+	if execErr != nil {
+		return NewDatabaseOperationError(execErr.Error())
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		return conditionFailure(id, r.FindOne)
+	}
+
+	r.index.remove(id)
+	return nil
+}
+
+// Update sets the fields of the Book with the specified ID when it matches the condition, incrementing its version
+// It returns an API Error Response if failed
+func (r *sqlRepo) Update(id string, condition Filter, updatedFields Fields) *BookAPIError {
+	names := make([]string, 0, len(updatedFields))
+	for name := range updatedFields {
+		if name != "_id" && name != "version" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	statement := &sqlStatement{dialect: r.dialect}
+	assignments := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		column, ok := sqlColumns[name]
+		if !ok {
+			return NewDatabaseOperationError(fmt.Sprintf("unknown field %s", name))
+		}
+
+		assignments = append(assignments, column+" = "+statement.bind(updatedFields[name]))
+	}
+	assignments = append(assignments, "version = version + 1")
+
+	where, err := statement.where(AllOf(NewQueryFilter("_id", Equals, id), condition))
+	if err != nil {
+		return NewDatabaseOperationError(err.Error())
+	}
+
+	result, execErr := r.db.Exec("UPDATE books SET "+strings.Join(assignments, ", ")+where, statement.args...)
+	if execErr != nil {
+		return NewDatabaseOperationError(execErr.Error())
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return conditionFailure(id, r.FindOne)
+	}
+
+	if book, err := r.FindOne(id); err == nil {
+		r.index.add(book)
+	}
+
+	return nil
+}
+
+// IsExistingEntry Checks the DB if it has a Book with the same unique composite fields
+// Author, Title, Publish_Date
+// It returns a boolean
+func (r *sqlRepo) IsExistingEntry(book Book) bool {
+	query := fmt.Sprintf("SELECT 1 FROM books WHERE author = %s AND title = %s AND publish_date = %s",
+		r.dialect.placeholder(1), r.dialect.placeholder(2), r.dialect.placeholder(3))
+
+	var found int
+	err := r.db.QueryRow(query, book.Author, book.Title, book.PublishDate).Scan(&found)
+	return err == nil
+}
+
+// Save Saves the Book Payload, generating an ID when it has none
+// It returns the persisted Book ID or an API Error Response if failed
+func (r *sqlRepo) Save(book Book) (string, *BookAPIError) {
+	if book.ID.IsZero() {
+		book.ID = primitive.NewObjectID()
+	}
+	book.Version = 1
+
+	statement := &sqlStatement{dialect: r.dialect}
+	values := []string{
+		statement.bind(book.ID.Hex()),
+		statement.bind(book.Author),
+		statement.bind(book.Title),
+		statement.bind(book.Publisher),
+		statement.bind(book.Status),
+		statement.bind(book.Rating),
+		statement.bind(book.PublishDate),
+		statement.bind(book.Version),
+		statement.bind(book.RatingAverage),
+		statement.bind(book.RatingCount),
+		statement.bind(book.RatingDistribution),
+		statement.bind(book.RatingScale),
+	}
+
+	query := "INSERT INTO books (id, author, title, publisher, status, rating, publish_date, version, " +
+		"rating_average, rating_count, rating_distribution, rating_scale) VALUES (" + strings.Join(values, ", ") + ")"
+	if _, err := r.db.Exec(query, statement.args...); err != nil {
+		return "", NewPersistError(err.Error())
+	}
+
+	r.index.add(book)
+	return book.ID.Hex(), nil
+}
+
+// sqlStatement collects the bound arguments of a statement while it is being built
+type sqlStatement struct {
+	dialect sqlDialect
+	// columns queryable columns by field name, the books columns when nil
+	columns map[string]string
+	args    []interface{}
+}
+
+// bind adds the argument and returns its placeholder
+func (s *sqlStatement) bind(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		value = v.Hex()
+	case primitive.DateTime:
+		value, _ = toTime(v)
+	}
+
+	s.args = append(s.args, value)
+	return s.dialect.placeholder(len(s.args))
+}
+
+// where translates the filter expression tree into a WHERE clause
+func (s *sqlStatement) where(filter Filter) (string, error) {
+	if filter.IsEmpty() {
+		return "", nil
+	}
+
+	condition, err := s.condition(filter)
+	if err != nil {
+		return "", err
+	}
+
+	return " WHERE " + condition, nil
+}
+
+func (s *sqlStatement) condition(filter Filter) (string, error) {
+	if filter.Query != nil {
+		return s.query(*filter.Query)
+	}
+
+	conditions := make([]string, 0, len(filter.Children))
+	for _, child := range filter.Children {
+		condition, err := s.condition(child)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, "("+condition+")")
+	}
+
+	switch filter.Operator {
+	case And:
+		return strings.Join(conditions, " AND "), nil
+	case Or:
+		return strings.Join(conditions, " OR "), nil
+	case Not:
+		return "NOT " + strings.Join(conditions, " AND "), nil
+	}
+
+	return "", fmt.Errorf("unsupported logical operator %s", filter.Operator)
+}
+
+func (s *sqlStatement) query(query Query) (string, error) {
+	columns := s.columns
+	if columns == nil {
+		columns = sqlColumns
+	}
+
+	column, ok := columns[query.Field]
+	if !ok {
+		return "", fmt.Errorf("unknown field %s", query.Field)
+	}
+
+	// a nil value stands for a NULL column
+	if query.Value == nil && query.Operator == Equals {
+		return column + " IS NULL", nil
+	}
+	if query.Value == nil && query.Operator == DoesNotEqual {
+		return column + " IS NOT NULL", nil
+	}
+
+	switch query.Operator {
+	case Equals, LessThan, GreaterThan, GreaterThanOrEqual, LessThanOrEqual:
+		return column + " " + string(query.Operator) + " " + s.bind(query.Value), nil
+	case DoesNotEqual:
+		return column + " <> " + s.bind(query.Value), nil
+	case In:
+		values, ok := query.Value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("%s requires a list of values", query.Operator)
+		}
+		if len(values) == 0 {
+			return "1 = 0", nil
+		}
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			placeholders = append(placeholders, s.bind(value))
+		}
+		return column + " IN (" + strings.Join(placeholders, ", ") + ")", nil
+	case StartsWith:
+		return "LOWER(" + column + ") LIKE LOWER(" + s.bind(escapeLike(fmt.Sprint(query.Value))+"%") + `) ESCAPE '\'`, nil
+	case Contains:
+		return "LOWER(" + column + ") LIKE LOWER(" + s.bind("%"+escapeLike(fmt.Sprint(query.Value))+"%") + `) ESCAPE '\'`, nil
+	}
+
+	return "", fmt.Errorf("unsupported query operator %s", query.Operator)
+}
+
+// escapeLike escapes the LIKE wildcards so the text is matched literally
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// limit translates the pagination options into LIMIT / OFFSET clauses
+func (s *sqlStatement) limit(limit int64, skip int64) string {
+	clause := ""
+	switch {
+	case limit > 0:
+		clause = fmt.Sprintf(" LIMIT %d", limit)
+	case skip > 0:
+		clause = " LIMIT " + s.dialect.unlimited()
+	}
+
+	if skip > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", skip)
+	}
+
+	return clause
+}
+
+// sqlOrderBy translates the sort fields into an ORDER BY clause on the columns
+func sqlOrderBy(columns map[string]string, sortFields []Sort) (string, error) {
+	if len(sortFields) == 0 {
+		return "", nil
+	}
+
+	terms := make([]string, 0, len(sortFields))
+	for _, field := range sortFields {
+		column, ok := columns[field.Field]
+		if !ok {
+			return "", fmt.Errorf("unknown sort field %s", field.Field)
+		}
+
+		direction := "ASC"
+		if field.Order == DESC {
+			direction = "DESC"
+		}
+		terms = append(terms, column+" "+direction)
+	}
+
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBook(row rowScanner) (Book, error) {
+	var book Book
+	var id string
+	err := row.Scan(&id, &book.Author, &book.Title, &book.Publisher, &book.Status, &book.Rating, &book.PublishDate, &book.Version,
+		&book.RatingAverage, &book.RatingCount, &book.RatingDistribution, &book.RatingScale)
+	if err != nil {
+		return Book{}, err
+	}
+
+	book.ID, err = primitive.ObjectIDFromHex(id)
+	return book, err
+}
+
+// Value stores the distribution in its column as a JSON object
+func (d RatingDistribution) Value() (driver.Value, error) {
+	if d == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(map[string]int(d))
+	return string(data), err
+}
+
+// Scan reads the distribution from the JSON object of its column, none when it counts no Rating
+func (d *RatingDistribution) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into a rating distribution", src)
+	}
+
+	distribution := RatingDistribution{}
+	if err := json.Unmarshal(data, &distribution); err != nil {
+		return err
+	}
+
+	*d = nil
+	if len(distribution) > 0 {
+		*d = distribution
+	}
+
+	return nil
+}
+
+// NewSQLRepository Initializes a repository backed by the database/sql driver, migrating the schema
+// to the latest version. The driver must be registered by the caller.
+// It returns an API Error Response if failed
+func NewSQLRepository(driver string, dsn string) (Repository, *BookAPIError) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, NewDatabaseOperationError(err.Error())
+	}
+
+	dialect := sqlDialect{driver: driver}
+	if err := migrate(db, dialect); err != nil {
+		_ = db.Close()
+		return nil, NewDatabaseOperationError(err.Error())
+	}
+
+	repo := &sqlRepo{db: db, dialect: dialect, index: newSearchIndex()}
+	books, findErr := repo.FindAll(Filter{}, FindOptions{})
+	if findErr != nil {
+		_ = db.Close()
+		return nil, findErr
+	}
+	repo.index.reset(books)
+
+	return repo, nil
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// skipWithoutSQLite skips the test when the sqlite3 driver cannot open a database, i.e. in a build without cgo such
+// as the Docker image
+func skipWithoutSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err == nil {
+		err = db.Ping()
+		_ = db.Close()
+	}
+
+	if err != nil {
+		t.Skipf(`sqlite3 driver unavailable: %s`, err.Error())
+	}
+}
+
+func newSQLiteRepository(t *testing.T) (Repository, func()) {
+	skipWithoutSQLite(t)
+	path, cleanup := tempStoragePath(t)
+	repo, err := NewSQLRepository("sqlite3", path)
+	if err != nil {
+		cleanup()
+		t.Fatalf(`Unable to create sqlite repository: %s`, err.Error())
+	}
+
+	return repo, cleanup
+}
+
+// newSQLiteCollection returns the collection of the table in the SQLite database at path
+func newSQLiteCollection(t *testing.T, path string, table string) collection {
+	skipWithoutSQLite(t)
+	c, err := newSQLCollection("sqlite3", path, table)
+	if err != nil {
+		t.Fatalf(`Unable to create sqlite %s collection: %s`, table, err.Error())
+	}
+
+	return c
+}
+
+func TestSQLRepo_SaveAndFindOne(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+
+	id, err := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, Rating: 3, PublishDate: "2008"})
+	assert.Nil(t, err)
+
+	book, findErr := repo.FindOne(id)
+	assert.Nil(t, findErr)
+	assert.Equal(t, id, book.ID.Hex())
+	assert.Equal(t, CheckedIn, book.Status)
+	assert.Equal(t, 3.0, book.Rating)
+
+	_, findErr = repo.FindOne("5ca7c76f9287bd3832d96f15")
+	assert.Equal(t, NotFoundError, findErr.errorType)
+}
+
+func TestSQLRepo_UniqueCompositeKey(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+
+	book := Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"}
+	_, err := repo.Save(book)
+	assert.Nil(t, err)
+	assert.True(t, repo.IsExistingEntry(book))
+
+	_, err = repo.Save(book)
+	assert.NotNil(t, err)
+	assert.Equal(t, PersistError, err.errorType)
+}
+
+func TestSQLRepo_FindAll_WithFilterSortAndPagination(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+
+	seedRepository(t, repo)
+
+	found, err := repo.FindAll(NewQueryFilter("author", Equals, "Robert Martin"), FindOptions{Sort: []Sort{{Field: "rating", Order: DESC}}})
+	assert.Nil(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, "Clean Code", found[0].Title)
+
+	found, err = repo.FindAll(NewQueryFilter("rating", DoesNotEqual, 3), FindOptions{Sort: []Sort{{Field: "publish_date", Order: ASC}}, Skip: 1})
+	assert.Nil(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, "Clean Architecture", found[0].Title)
+
+	_, err = repo.FindAll(NewQueryFilter("rating; DROP TABLE books", Equals, 1), FindOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestSQLRepo_FindAll_WithExpressionFilters(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+	seedRepository(t, repo)
+
+	for _, c := range expressionFilterCases {
+		books, err := repo.FindAll(c.filter, FindOptions{})
+		assert.Nil(t, err, c.name)
+		assert.Len(t, books, c.expected, c.name)
+
+		count, countErr := repo.Count(c.filter)
+		assert.Nil(t, countErr, c.name)
+		assert.Equal(t, int64(c.expected), count, c.name)
+	}
+}
+
+func TestSQLRepo_RatingAggregates(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+
+	rated, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	unrated, _ := repo.Save(Book{Author: "Paulo Coelho", Title: "The Alchemist", Publisher: "HarperCollins", Status: CheckedIn, PublishDate: "1988"})
+
+	distribution := RatingDistribution{"0": 0, "1": 1, "2": 0, "3": 2}
+	assert.Nil(t, repo.Update(rated, Filter{}, Fields{"rating_average": 2.33, "rating_count": 3, "rating_distribution": distribution}))
+
+	book, _ := repo.FindOne(rated)
+	assert.Equal(t, 2.33, book.RatingAverage)
+	assert.Equal(t, 3, book.RatingCount)
+	assert.Equal(t, distribution, book.RatingDistribution)
+
+	book, _ = repo.FindOne(unrated)
+	assert.Nil(t, book.RatingDistribution)
+
+	books, _ := repo.FindAll(NewQueryFilter("rating_average", GreaterThan, 2.0), FindOptions{Sort: []Sort{{Field: "rating_average", Order: DESC}}})
+	if assert.Len(t, books, 1) {
+		assert.Equal(t, rated, books[0].ID.Hex())
+	}
+}
+
+func TestSQLRepo_UpdateAndDelete(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	assert.Nil(t, repo.Update(id, Filter{}, Fields{"status": CheckedOut, "rating": 2}))
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, CheckedOut, book.Status)
+	assert.Equal(t, 2.0, book.Rating)
+
+	assert.Nil(t, repo.Delete(id, Filter{}))
+	_, err := repo.FindOne(id)
+	assert.NotNil(t, err)
+}
+
+func TestSQLRepo_UpdateAndDelete_WithVersionCondition(t *testing.T) {
+	repo, cleanup := newSQLiteRepository(t)
+	defer cleanup()
+
+	id, _ := repo.Save(Book{Author: "Robert Martin", Title: "Clean Code", Publisher: "Prentice Hall", Status: CheckedIn, PublishDate: "2008"})
+	assert.Nil(t, repo.Update(id, versionCondition(Versions{1}), Fields{"status": CheckedOut}))
+
+	book, _ := repo.FindOne(id)
+	assert.Equal(t, int64(2), book.Version)
+
+	err := repo.Update(id, versionCondition(Versions{1}), Fields{"status": CheckedIn})
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+
+	err = repo.Delete(id, versionCondition(Versions{1}))
+	assert.NotNil(t, err)
+	assert.Equal(t, PreconditionFailed, err.errorType)
+
+	err = repo.Delete("5ca7c76f9287bd3832d96f15", Filter{})
+	assert.NotNil(t, err)
+	assert.Equal(t, NotFoundError, err.errorType)
+
+	assert.Nil(t, repo.Delete(id, versionCondition(Versions{2})))
+}
+
+func TestSQLRepo_MigrationsAreIdempotent(t *testing.T) {
+	skipWithoutSQLite(t)
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	for i := 0; i < 2; i++ {
+		_, err := NewSQLRepository("sqlite3", path)
+		assert.Nil(t, err)
+	}
+
+	db, _ := sql.Open("sqlite3", path)
+	defer db.Close()
+
+	var count int
+	_ = db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	assert.Equal(t, len(sqlMigrations), count)
+}
@@ -5,22 +5,115 @@ import (
 	"github.com/temesxgn/redeam/api/domain"
 )
 
-// Routes - Enabled Routes for /books path
+// Routes - Enabled Routes for /books, /patrons, /loans and /reviews paths.
+// The overdue loans are marked and the holds not picked up expired in the background as long as the process runs.
+// The Ratings stored on another rating scale than the configured one are rescaled before the routes are returned.
+// There are no routes without a valid loan policy, which every loan, hold and sweep depends on
 func Routes() (*chi.Mux, *domain.BookAPIError) {
 	router := chi.NewRouter()
 	repo, err := domain.NewRepository()
-	service := domain.NewService(repo)
+	patrons, patronsErr := domain.NewPatronRepository()
+	loans, loansErr := domain.NewLoanRepository()
+	holds, holdsErr := domain.NewHoldRepository()
+	copies, copiesErr := domain.NewCopyRepository()
+	ratings, ratingsErr := domain.NewRatingRepository()
+	reviews, reviewsErr := domain.NewReviewRepository()
+	votes, votesErr := domain.NewReviewVoteRepository()
+	policy, policyErr := domain.NewLoanPolicy()
+	scale, scaleErr := domain.NewRatingScale()
+	if policyErr != nil {
+		return nil, policyErr
+	}
+
+	for _, setupErr := range []*domain.BookAPIError{patronsErr, loansErr, holdsErr, copiesErr, ratingsErr, reviewsErr, votesErr,
+		scaleErr} {
+		if err == nil {
+			err = setupErr
+		}
+	}
+
+	if scaleErr == nil {
+		domain.SetRatingScale(scale)
+	}
+
+	service := domain.NewService(repo, patrons, loans, holds, copies, ratings, policy)
+	if err == nil {
+		// the Ratings stored on another scale are moved to the configured one before serving
+		_, err = service.RescaleRatings()
+	}
 	ctrl := domain.NewController(service)
-	router.Get("/", ctrl.GetAll)
-	router.Post("/", ctrl.Create)
+	copyCtrl := domain.NewCopyController(domain.NewCopyService(repo, copies, holds, loans))
+	reviewCtrl := domain.NewReviewController(domain.NewReviewService(repo, patrons, reviews, votes))
+	patronCtrl := domain.NewPatronController(domain.NewPatronService(patrons, loans))
+	loanService := domain.NewLoanService(loans)
+	loanCtrl := domain.NewLoanController(loanService)
+	if loans != nil {
+		domain.StartOverdueSweep(loanService, policy.SweepInterval)
+	}
+	if holds != nil {
+		domain.StartHoldSweep(service, policy.SweepInterval)
+	}
+
+	router.NotFound(ctrl.NotFound)
+	router.MethodNotAllowed(ctrl.MethodNotAllowed)
+
+	router.Route("/books", func(router chi.Router) {
+		router.Get("/", ctrl.GetAll)
+		router.Post("/", ctrl.Create)
+		router.Get("/search", ctrl.Search)
+
+		router.Get("/{id}", ctrl.GetByID)
+		router.Put("/{id}", ctrl.Update)
+		router.Patch("/{id}", ctrl.Patch)
+		router.Delete("/{id}", ctrl.Delete)
+		router.Get("/{id}/loans", ctrl.Loans)
+		router.Get("/{id}/holds", ctrl.Holds)
+		router.Post("/{id}/holds", ctrl.PlaceHold)
+		router.Get("/{id}/holds/{holdID}", ctrl.Hold)
+		router.Delete("/{id}/holds/{holdID}", ctrl.CancelHold)
+		router.Get("/{id}/availability", copyCtrl.Availability)
+
+		router.Get("/{id}/copies", copyCtrl.GetAll)
+		router.Post("/{id}/copies", copyCtrl.Create)
+		router.Get("/{id}/copies/{copyID}", copyCtrl.GetByID)
+		router.Put("/{id}/copies/{copyID}", copyCtrl.Update)
+		router.Delete("/{id}/copies/{copyID}", copyCtrl.Delete)
+		router.Put("/{id}/copies/{copyID}/checkout", ctrl.CheckOutCopy)
+		router.Put("/{id}/copies/{copyID}/checkin", ctrl.CheckInCopy)
+		router.Put("/{id}/copies/{copyID}/status/{status}", ctrl.TransitionCopy)
+
+		router.Put("/checkout/{id}", ctrl.CheckOut)
+		router.Put("/checkin/{id}", ctrl.CheckIn)
+		router.Put("/{id}/rate/{rate}", ctrl.Rate)
+		router.Get("/{id}/ratings", ctrl.Ratings)
+		router.Put("/{id}/status/{status}", ctrl.Transition)
+
+		router.Get("/{id}/reviews", reviewCtrl.GetAll)
+		router.Post("/{id}/reviews", reviewCtrl.Create)
+		router.Get("/{id}/reviews/{reviewID}", reviewCtrl.GetByID)
+		router.Put("/{id}/reviews/{reviewID}", reviewCtrl.Update)
+		router.Delete("/{id}/reviews/{reviewID}", reviewCtrl.Delete)
+		router.Put("/{id}/reviews/{reviewID}/helpful", reviewCtrl.Vote)
+	})
+
+	router.Route("/patrons", func(router chi.Router) {
+		router.Get("/", patronCtrl.GetAll)
+		router.Post("/", patronCtrl.Create)
+
+		router.Get("/{id}", patronCtrl.GetByID)
+		router.Put("/{id}", patronCtrl.Update)
+		router.Delete("/{id}", patronCtrl.Delete)
+		router.Get("/{id}/loans", patronCtrl.Loans)
+	})
 
-	router.Get("/{id}", ctrl.GetByID)
-	router.Put("/{id}", ctrl.Update)
-	router.Delete("/{id}", ctrl.Delete)
+	router.Route("/loans", func(router chi.Router) {
+		router.Get("/overdue", loanCtrl.Overdue)
+	})
 
-	router.Put("/checkout/{id}", ctrl.CheckOut)
-	router.Put("/checkin/{id}", ctrl.CheckIn)
-	router.Put("/{id}/rate/{rate}", ctrl.Rate)
+	router.Route("/reviews", func(router chi.Router) {
+		router.Get("/", reviewCtrl.Queue)
+		router.Put("/{id}/status/{status}", reviewCtrl.Moderate)
+	})
 
 	return router, err
 }
@@ -2,15 +2,14 @@ package utils
 
 import (
 	"github.com/fatih/structs"
-	"go.mongodb.org/mongo-driver/bson"
 	"strings"
 )
 
 type ModelMapper struct{}
 
-// ToMongoDocument maps book KV pair to mongo update fields
-func (m *ModelMapper) ToMongoDocument(fields []*structs.Field) bson.D {
-	fds := bson.M{}
+// ToFields maps book KV pair to update fields keyed by their bson name
+func (m *ModelMapper) ToFields(fields []*structs.Field) map[string]interface{} {
+	fds := map[string]interface{}{}
 	for _, f := range fields {
 		tagValue := f.Tag("bson")
 		if !strings.Contains(tagValue, "_id") {
@@ -18,5 +17,5 @@ func (m *ModelMapper) ToMongoDocument(fields []*structs.Field) bson.D {
 		}
 	}
 
-	return bson.D{{"$set", fds}}
+	return fds
 }
@@ -1,33 +1,22 @@
 package utils
 
 import (
-	"fmt"
-	"log"
 	"net/http"
 )
 
 // ResponseBuilder - helper methods to generate HTTP responses
 type ResponseBuilder struct{}
 
-func (r *ResponseBuilder) InternalServerError(w http.ResponseWriter, msg string) {
-	log.Println("Internal error:", msg)
-	w.WriteHeader(http.StatusInternalServerError)
-	r.build(w, []byte(fmt.Sprintf("Internal Error: %s", msg)))
-}
-
 func (r *ResponseBuilder) OK(w http.ResponseWriter, data []byte) {
 	w.WriteHeader(http.StatusOK)
 	r.build(w, data)
 }
 
-func (r *ResponseBuilder) NotFound(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusNotFound)
-	r.build(w, []byte("Not Found"))
-}
-
-func (r *ResponseBuilder) BadRequest(w http.ResponseWriter, msg string) {
-	w.WriteHeader(http.StatusBadRequest)
-	r.build(w, []byte(msg))
+// Problem writes the RFC 7807 problem details of an error response with its status
+func (r *ResponseBuilder) Problem(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
 }
 
 func (r *ResponseBuilder) build(w http.ResponseWriter, data []byte) {
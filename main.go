@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	_ "github.com/lib/pq" // PostgreSQL driver for the sql storage engine
 	"github.com/temesxgn/redeam/api"
 	"log"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 func Routes() *chi.Mux {
 	router := chi.NewRouter()
 	router.Use(
+		middleware.RequestID, // Tag each request with an ID, reported in error responses
 		middleware.Logger,    // Log API request calls
 		middleware.Recoverer, // Recover from panics without crashing server
 		middleware.SetHeader("Content-Type", "application/json"), // Set content-Type headers as application/json
@@ -22,9 +24,15 @@ func Routes() *chi.Mux {
 		log.Println("Error initializing route:", err.Error())
 	}
 
-	router.Route("/", func(r chi.Router) {
-		r.Mount("/books", apiRoutes)
-	})
+	if apiRoutes == nil {
+		log.Fatalln("Unable to serve the API without its routes")
+	}
+
+	// unknown paths and methods get the same problem details as the API errors
+	router.NotFound(apiRoutes.NotFoundHandler().ServeHTTP)
+	router.MethodNotAllowed(apiRoutes.MethodNotAllowedHandler().ServeHTTP)
+
+	router.Mount("/", apiRoutes)
 
 	return router
 }